@@ -0,0 +1,44 @@
+package secretmanager
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// RouteKey identifies a route by namespace and name.
+type RouteKey struct {
+	Namespace string
+	Name      string
+}
+
+// String returns the route key in the namespace/route form used by the manager.
+func (k RouteKey) String() string {
+	return generateKey(k.Namespace, k.Name)
+}
+
+// GetSecrets is like GetSecret for several routes at once, acquiring the lock only once.
+// The secrets are read from the cache without waiting for it to sync. Every key is either
+// in the returned secrets or in the returned errors, e.g. for an unregistered route.
+func (m *manager) GetSecrets(keys []RouteKey) (map[RouteKey]*v1.Secret, map[RouteKey]error) {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+
+	secrets := make(map[RouteKey]*v1.Secret, len(keys))
+	errs := map[RouteKey]error{}
+	for _, key := range keys {
+		handlerRegistration, exists := m.registeredHandlers[key.String()]
+		if !exists {
+			errs[key] = fmt.Errorf("no handler registered with key %s", key)
+			continue
+		}
+
+		obj, err := handlerRegistration.GetSecret()
+		if err != nil {
+			errs[key] = err
+			continue
+		}
+		secrets[key] = obj
+	}
+	return secrets, errs
+}
@@ -0,0 +1,56 @@
+package secretmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestGetSecrets(t *testing.T) {
+	namespace := "ns"
+	kubeClient := kubefake.NewSimpleClientset(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}})
+	mgr := newManager(secret.NewSecretMonitor(kubeClient), nil)
+
+	for route, secretName := range map[string]string{"route1": "secret", "route2": "secret", "uncached": "missing"} {
+		if err := mgr.RegisterRoute(context.TODO(), namespace, route, secretName, cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		route1       = RouteKey{Namespace: namespace, Name: "route1"}
+		route2       = RouteKey{Namespace: namespace, Name: "route2"}
+		uncached     = RouteKey{Namespace: namespace, Name: "uncached"}
+		unregistered = RouteKey{Namespace: namespace, Name: "unregistered"}
+	)
+	secrets, errs := mgr.GetSecrets([]RouteKey{route1, route2, uncached, unregistered})
+
+	if len(secrets) != 2 {
+		t.Errorf("expected 2 secrets, got %v", secrets)
+	}
+	for _, key := range []RouteKey{route1, route2} {
+		if gotSecret := secrets[key]; gotSecret == nil || gotSecret.Name != "secret" {
+			t.Errorf("expected secret for route %s, got %v", key, gotSecret)
+		}
+	}
+
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors, got %v", errs)
+	}
+	if err := errs[uncached]; !errors.Is(err, secret.ErrSecretNotFound) {
+		t.Errorf("expected %v for route %s, got %v", secret.ErrSecretNotFound, uncached, err)
+	}
+	if err := errs[unregistered]; err == nil {
+		t.Errorf("expected an error for route %s", unregistered)
+	}
+
+	if secrets, errs := mgr.GetSecrets(nil); len(secrets) != 0 || len(errs) != 0 {
+		t.Errorf("expected no results without keys, got %v, %v", secrets, errs)
+	}
+}
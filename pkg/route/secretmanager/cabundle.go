@@ -0,0 +1,61 @@
+package secretmanager
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/openshift/library-go/pkg/secret"
+	v1 "k8s.io/api/core/v1"
+)
+
+// GetCABundle returns the PEM encoded CA bundle stored in the ca.crt key of the secret registered with a route.
+// The secret is read from the cache without waiting for it to sync. Returns an error wrapping ErrCABundleMissing
+// if the secret has no ca.crt key, and ErrInvalidCABundle if it doesn't hold at least one CA certificate.
+func (m *manager) GetCABundle(namespace, routeName string) ([]byte, error) {
+	obj, err := m.cachedSecret(namespace, routeName)
+	if err != nil {
+		return nil, err
+	}
+
+	caPEM, ok := obj.Data[secret.TLSCABundleKey]
+	if !ok {
+		return nil, fmt.Errorf("%w: secret %s/%s has no %q key", ErrCABundleMissing, obj.Namespace, obj.Name, secret.TLSCABundleKey)
+	}
+	if err := validateCABundle(obj, caPEM); err != nil {
+		return nil, err
+	}
+	return caPEM, nil
+}
+
+// validateSecretCABundle validates the CA bundle of the secret, if any. A secret without CA bundle is valid.
+func validateSecretCABundle(obj *v1.Secret) error {
+	caPEM, ok := obj.Data[secret.TLSCABundleKey]
+	if !ok {
+		return nil
+	}
+	return validateCABundle(obj, caPEM)
+}
+
+// validateCABundle returns an error wrapping ErrInvalidCABundle unless caPEM holds at least one CA certificate.
+// Blocks which are not certificates are ignored.
+func validateCABundle(obj *v1.Secret, caPEM []byte) error {
+	for rest := caPEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("%w: secret %s/%s: %w", ErrInvalidCABundle, obj.Namespace, obj.Name, err)
+		}
+		if cert.IsCA {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: secret %s/%s has no CA certificate in its %q key", ErrInvalidCABundle, obj.Namespace, obj.Name, secret.TLSCABundleKey)
+}
@@ -0,0 +1,69 @@
+package secretmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	"github.com/openshift/library-go/pkg/secret/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestGetCABundle(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	caPEM, _, _ := newCertKeyPEM(t, time.Hour)
+
+	scenarios := []struct {
+		name      string
+		data      map[string][]byte
+		expectErr error
+	}{
+		{
+			name: "valid CA bundle",
+			data: map[string][]byte{secret.TLSCABundleKey: caPEM},
+		},
+		{
+			name:      "missing CA bundle",
+			data:      map[string][]byte{},
+			expectErr: ErrCABundleMissing,
+		},
+		{
+			name:      "CA bundle without PEM data",
+			data:      map[string][]byte{secret.TLSCABundleKey: []byte("invalid")},
+			expectErr: ErrInvalidCABundle,
+		},
+		{
+			name:      "CA bundle with a corrupted certificate",
+			data:      map[string][]byte{secret.TLSCABundleKey: []byte("-----BEGIN CERTIFICATE-----\naW52YWxpZA==\n-----END CERTIFICATE-----\n")},
+			expectErr: ErrInvalidCABundle,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			sm := &fake.SecretMonitor{
+				Secret: &corev1.Secret{
+					Type:       corev1.SecretTypeTLS,
+					ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace},
+					Data:       s.data,
+				},
+			}
+			mgr := newManager(sm, nil)
+			if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+				t.Fatalf("failed to register route: %v", err)
+			}
+
+			gotCA, err := mgr.GetCABundle(namespace, routeName)
+			if !errors.Is(err, s.expectErr) {
+				t.Fatalf("expected %v, got %v", s.expectErr, err)
+			}
+			if s.expectErr == nil && string(gotCA) != string(caPEM) {
+				t.Errorf("expected CA bundle %q, got %q", caPEM, gotCA)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package secretmanager
+
+import (
+	"context"
+
+	"k8s.io/klog/v2"
+)
+
+// RegisterFromCertManagerCertificate registers the route with the secret issued by a cert-manager Certificate,
+// i.e. the secret named in its spec.secretName, and tags the registration of the route with the name of the
+// Certificate for diagnostics, see HandlerInfo and DumpState. The route is registered with a handler adding
+// the route key to the manager's queue, like EnsureAndGet. Only the names are mapped, the Certificate itself
+// is never read. Returns an error if the route is already registered.
+func (m *manager) RegisterFromCertManagerCertificate(ctx context.Context, namespace, routeName, certName, secretName string) error {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+
+	key := generateKey(namespace, routeName)
+	if _, err := m.registerRoute(ctx, namespace, routeName, secretName, m.enqueueHandler(key)); err != nil {
+		return err
+	}
+	if m.certificates == nil {
+		m.certificates = make(map[string]string)
+	}
+	m.certificates[key] = certName
+	klog.V(4).Infof("secret manager linked route key %s to cert-manager Certificate %s", key, certName)
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package secretmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	"github.com/openshift/library-go/pkg/secret/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestRegisterFromCertManagerCertificate(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cert-tls", Namespace: "ns"}})
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+	mgr := newManager(secret.NewSecretMonitor(kubeClient), queue)
+
+	if err := mgr.RegisterFromCertManagerCertificate(context.TODO(), "ns", "route", "cert", "cert-tls"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// a route named after the Certificate is unrelated to it
+	if err := mgr.RegisterRoute(context.TODO(), "ns", "cert", "cert-tls", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("unexpected error registering a route named after the certificate: %v", err)
+	}
+	if !mgr.IsSecretWatched("ns", "cert-tls") {
+		t.Error("expected the secret of the certificate to be watched")
+	}
+
+	// the events of the secret enqueue the owning route
+	item, shutdown := queue.Get()
+	if shutdown || item != "ns/route" {
+		t.Fatalf("expected the route key ns/route to be enqueued, got %v", item)
+	}
+	queue.Done(item)
+
+	info, err := mgr.HandlerInfo("ns", "route")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Key != secret.NewObjectKey("ns", "cert-tls") || info.Certificate != "cert" {
+		t.Errorf("expected the route to carry the certificate cert of secret ns/cert-tls, got %+v", info)
+	}
+	// routes registered otherwise carry no certificate
+	if info, err := mgr.HandlerInfo("ns", "cert"); err != nil || info.Certificate != "" {
+		t.Errorf("expected no certificate for the route, got %q with error %v", info.Certificate, err)
+	}
+
+	// registering a registered route fails without tagging it
+	if err := mgr.RegisterFromCertManagerCertificate(context.TODO(), "ns", "cert", "other", "cert-tls"); err == nil {
+		t.Error("expected an error registering a registered route")
+	}
+	if info, err := mgr.HandlerInfo("ns", "cert"); err != nil || info.Certificate != "" {
+		t.Errorf("expected no certificate for the route, got %q with error %v", info.Certificate, err)
+	}
+
+	// the certificate is forgotten with the registration
+	if err := mgr.UnregisterRoute("ns", "route"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.RegisterRoute(context.TODO(), "ns", "route", "cert-tls", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := mgr.HandlerInfo("ns", "route"); err != nil || info.Certificate != "" {
+		t.Errorf("expected no certificate after re-registering, got %q with error %v", info.Certificate, err)
+	}
+	if err := mgr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// no key other than the route is ever enqueued
+	time.Sleep(100 * time.Millisecond)
+	if queue.Len() != 0 {
+		item, _ := queue.Get()
+		t.Errorf("expected no other key to be enqueued, got %v", item)
+	}
+}
+
+func TestRegisterFromCertManagerCertificateInvalidSecret(t *testing.T) {
+	mgr := newManager(&fake.SecretMonitor{}, nil)
+
+	if err := mgr.RegisterFromCertManagerCertificate(context.TODO(), "ns", "route", "cert", ""); err == nil {
+		t.Fatal("expected an error for a certificate without secret name")
+	}
+	if mgr.IsRouteRegistered("ns", "route") || len(mgr.certificates) != 0 {
+		t.Error("expected the certificate not to be registered")
+	}
+}
@@ -0,0 +1,83 @@
+package secretmanager
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/openshift/library-go/pkg/secret"
+)
+
+const (
+	// DegradedReasonSecretNotSynced means the informer of the secret has not synced yet.
+	DegradedReasonSecretNotSynced = "SecretNotSynced"
+	// DegradedReasonSecretMissing means the secret doesn't exist, or was deleted.
+	DegradedReasonSecretMissing = "SecretMissing"
+	// DegradedReasonSecretUnavailable means the secret could not be read from the cache.
+	DegradedReasonSecretUnavailable = "SecretUnavailable"
+	// DegradedReasonWrongSecretType means the secret is not of type kubernetes.io/tls.
+	DegradedReasonWrongSecretType = "WrongSecretType"
+	// DegradedReasonInvalidKeyPair means the certificate and private key of the secret are missing, invalid or don't match.
+	DegradedReasonInvalidKeyPair = "InvalidKeyPair"
+	// DegradedReasonInvalidCABundle means the ca.crt key of the secret doesn't hold any CA certificate.
+	DegradedReasonInvalidCABundle = "InvalidCABundle"
+)
+
+// DegradedRoute is a registered route whose secret can't be used to serve it.
+type DegradedRoute struct {
+	// Key is the route key, namespace/route.
+	Key string
+	// Reason is one of the DegradedReason constants.
+	Reason string
+	// Message details the reason.
+	Message string
+}
+
+// DegradedRoutes returns the registered routes whose secret is not synced, missing, of the wrong type,
+// or doesn't hold a valid key pair or CA bundle, sorted by route key. Secrets without CA bundle are not degraded. Secrets are read from the cache without waiting for it to sync.
+func (m *manager) DegradedRoutes() []DegradedRoute {
+	m.handlersLock.RLock()
+	registrations := make(map[string]secret.SecretEventHandlerRegistration, len(m.registeredHandlers))
+	for key, handlerRegistration := range m.registeredHandlers {
+		registrations[key] = handlerRegistration
+	}
+	m.handlersLock.RUnlock()
+
+	degraded := []DegradedRoute{}
+	for key, handlerRegistration := range registrations {
+		if reason, message, ok := m.degradedReason(handlerRegistration); ok {
+			degraded = append(degraded, DegradedRoute{Key: key, Reason: reason, Message: message})
+		}
+	}
+	sort.Slice(degraded, func(i, j int) bool {
+		return degraded[i].Key < degraded[j].Key
+	})
+	return degraded
+}
+
+// degradedReason returns the reason why the secret of the registration can't be used, if any.
+func (m *manager) degradedReason(handlerRegistration secret.SecretEventHandlerRegistration) (reason, message string, degraded bool) {
+	secretKey := handlerRegistration.GetKey()
+	if !handlerRegistration.HasSynced() {
+		return DegradedReasonSecretNotSynced, fmt.Sprintf("secret %s/%s is not synced", secretKey.Namespace, secretKey.Name), true
+	}
+
+	obj, err := handlerRegistration.GetSecret()
+	switch {
+	case errors.Is(err, secret.ErrSecretNotFound) || errors.Is(err, secret.ErrSecretDeleted):
+		return DegradedReasonSecretMissing, err.Error(), true
+	case err != nil:
+		return DegradedReasonSecretUnavailable, err.Error(), true
+	}
+
+	if err := validateSecretType(obj); err != nil {
+		return DegradedReasonWrongSecretType, err.Error(), true
+	}
+	if _, err := m.keyPairFromSecret(obj); err != nil {
+		return DegradedReasonInvalidKeyPair, err.Error(), true
+	}
+	if err := validateSecretCABundle(obj); err != nil {
+		return DegradedReasonInvalidCABundle, err.Error(), true
+	}
+	return "", "", false
+}
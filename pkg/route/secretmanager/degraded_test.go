@@ -0,0 +1,65 @@
+package secretmanager
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	"github.com/openshift/library-go/pkg/secret/fake"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDegradedRoutes(t *testing.T) {
+	namespace := "ns"
+	certPEM, keyPEM, _ := newCertKeyPEM(t, time.Hour)
+	_, otherKeyPEM, _ := newCertKeyPEM(t, time.Hour)
+	tlsSecret := func(certPEM, keyPEM []byte) *corev1.Secret {
+		return &corev1.Secret{
+			Type:       corev1.SecretTypeTLS,
+			ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       certPEM,
+				corev1.TLSPrivateKeyKey: keyPEM,
+			},
+		}
+	}
+	invalidCA := tlsSecret(certPEM, keyPEM)
+	invalidCA.Data[secret.TLSCABundleKey] = []byte("invalid")
+	notFound := fmt.Errorf("%w: %w", secret.ErrSecretNotFound, apierrors.NewNotFound(corev1.Resource("secrets"), "secret"))
+
+	registrations := map[string]*fake.SecretEventHandlerRegistration{
+		"healthy":    {Secret: tlsSecret(certPEM, keyPEM)},
+		"unsynced":   {NotSynced: true},
+		"missing":    {Err: notFound},
+		"wrong-type": {Secret: &corev1.Secret{Type: corev1.SecretTypeOpaque, ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}}},
+		"mismatched": {Secret: tlsSecret(certPEM, otherKeyPEM)},
+		"invalid-ca": {Secret: invalidCA},
+	}
+	mgr := newManager(&fake.SecretMonitor{}, nil)
+	for routeName, registration := range registrations {
+		registration.Key = secret.NewObjectKey(namespace, "secret")
+		mgr.registeredHandlers[generateKey(namespace, routeName)] = registration
+	}
+
+	gotReasons := map[string]string{}
+	for _, d := range mgr.DegradedRoutes() {
+		gotReasons[d.Key] = d.Reason
+		if len(d.Message) == 0 {
+			t.Errorf("expected a message for route %s", d.Key)
+		}
+	}
+	expectedReasons := map[string]string{
+		"ns/unsynced":   DegradedReasonSecretNotSynced,
+		"ns/missing":    DegradedReasonSecretMissing,
+		"ns/wrong-type": DegradedReasonWrongSecretType,
+		"ns/mismatched": DegradedReasonInvalidKeyPair,
+		"ns/invalid-ca": DegradedReasonInvalidCABundle,
+	}
+	if !reflect.DeepEqual(gotReasons, expectedReasons) {
+		t.Errorf("expected degraded routes %v, got %v", expectedReasons, gotReasons)
+	}
+}
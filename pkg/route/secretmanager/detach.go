@@ -0,0 +1,97 @@
+package secretmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/openshift/library-go/pkg/secret"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// ErrRouteDetached is returned when reading the secret of a route detached with DetachRoute.
+var ErrRouteDetached = errors.New("route is detached")
+
+// DetachRoute removes the handlers of the route from the informers of its secrets, e.g. while the route is paused
+// long-term, but keeps its registration: the route stays registered with its secrets, handler and pause state,
+// so that ReattachRoute can restore it. Informers shared with other routes keep running, the others are stopped.
+// Reading the secret of a detached route fails with ErrRouteDetached. Detaching a detached route does nothing.
+// Updating a detached route, e.g. with UpdateRoute, keeps it detached with its new secret and handler.
+// Returns an error if the route is not registered, or is deferred while the manager is inactive. The route stays
+// attached if the handler of its secret can't be removed.
+func (m *manager) DetachRoute(namespace, routeName string) error {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+
+	key := generateKey(namespace, routeName)
+	if _, detached := m.detached[key]; detached {
+		return nil
+	}
+	if _, deferred := m.deferred[key]; deferred {
+		return fmt.Errorf("%w: can't detach deferred route key %s", ErrManagerInactive, key)
+	}
+	handlerRegistration, exists := m.registeredHandlers[key]
+	if !exists {
+		return fmt.Errorf("no handler registered with key %s", key)
+	}
+
+	// the route stays attached with its registrations until the handler of its primary secret is removed
+	binding := m.bindingOf(key, handlerRegistration)
+	if err := m.monitor.RemoveSecretEventHandler(handlerRegistration); err != nil {
+		return fmt.Errorf("failed to detach route key %s: %w", key, err)
+	}
+	m.dropRegistrations(key, handlerRegistration)
+	if m.detached == nil {
+		m.detached = make(map[string]deferredRoute)
+	}
+	m.detached[key] = binding
+	klog.Infof("secret manager detached route for key %s", key)
+	return nil
+}
+
+// ReattachRoute adds the handlers of a route detached with DetachRoute again, which creates the informers of
+// its secrets unless they are shared with other routes. The route is deferred if the manager is inactive,
+// see SetActive. Reattaching a route which is not detached does nothing. The route stays detached on error.
+func (m *manager) ReattachRoute(namespace, routeName string) error {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+
+	key := generateKey(namespace, routeName)
+	binding, detached := m.detached[key]
+	if !detached {
+		return nil
+	}
+
+	if m.inactive {
+		delete(m.detached, key)
+		m.deferRoute(key, binding)
+		klog.Infof("secret manager deferred reattaching route for key %s while inactive", key)
+		return nil
+	}
+	if err := m.attachRoute(key, binding); err != nil {
+		return fmt.Errorf("failed to reattach route key %s: %w", key, err)
+	}
+	delete(m.detached, key)
+	klog.Infof("secret manager reattached route for key %s with secrets %v", key, binding.secretNames)
+	return nil
+}
+
+// replaceDetachedRoute replaces the binding and the handler of a detached route, which stays detached until
+// ReattachRoute adds the handler of its new secret. Its fallbacks are dropped. The caller must hold handlersLock.
+func (m *manager) replaceDetachedRoute(ctx context.Context, namespace, routeName, secretName string, handler cache.ResourceEventHandlerFuncs) error {
+	key := generateKey(namespace, routeName)
+	if err := secret.NewObjectKey(namespace, secretName).Validate(); err != nil {
+		return fmt.Errorf("invalid secret reference for route key %s: %w", key, err)
+	}
+	if m.rejectSelfOwnedSecrets {
+		if err := m.validateNotSelfOwned(ctx, namespace, routeName, secretName); err != nil {
+			return err
+		}
+	}
+
+	m.pausers[key] = m.newRoutePauser(namespace, routeName, handler)
+	m.detached[key] = deferredRoute{namespace: namespace, routeName: routeName, secretNames: []string{secretName}}
+	klog.Infof("secret manager updated detached route for key %s with secret %s", key, secretName)
+	return nil
+}
@@ -0,0 +1,244 @@
+package secretmanager
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	"github.com/openshift/library-go/pkg/secret/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestDetachRouteKeepsSharedInformer(t *testing.T) {
+	namespace := "ns"
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}},
+	)
+	monitor := secret.NewSecretMonitor(kubeClient)
+	mgr := newManager(monitor, nil)
+	for _, rs := range []routeSecret{{"route1", "shared"}, {"route2", "shared"}, {"route3", "secret"}} {
+		if err := mgr.RegisterRoute(context.TODO(), namespace, rs.routeName, rs.secretName, cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, routeName := range []string{"route1", "route3"} {
+		if err := mgr.DetachRoute(namespace, routeName); err != nil {
+			t.Fatalf("unexpected error detaching %s: %v", routeName, err)
+		}
+	}
+	// detaching again does nothing
+	if err := mgr.DetachRoute(namespace, "route1"); err != nil {
+		t.Fatalf("unexpected error detaching again: %v", err)
+	}
+
+	// the informer of the shared secret keeps running for route2, the one of route3 is stopped
+	expectedKeys := []secret.ObjectKey{secret.NewObjectKey(namespace, "shared")}
+	if keys := monitor.ListMonitoredKeys(); !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("expected informers of %v, got %v", expectedKeys, keys)
+	}
+	if _, err := mgr.GetSecretBlocking(context.TODO(), namespace, "route2", 5*time.Second); err != nil {
+		t.Errorf("expected route2 to keep reading the shared secret, got %v", err)
+	}
+	if _, err := mgr.GetSecret(context.TODO(), namespace, "route1"); !errors.Is(err, ErrRouteDetached) {
+		t.Errorf("expected ErrRouteDetached, got %v", err)
+	}
+	if !mgr.IsRouteRegistered(namespace, "route1") {
+		t.Error("expected the detached route to stay registered")
+	}
+	if mgr.IsSecretWatched(namespace, "secret") {
+		t.Error("expected the secret of the detached route not to be watched")
+	}
+	if err := mgr.RegisterRoute(context.TODO(), namespace, "route1", "shared", cache.ResourceEventHandlerFuncs{}); err == nil {
+		t.Error("expected an error registering a detached route again")
+	}
+
+	if err := mgr.DetachRoute(namespace, "unregistered"); err == nil {
+		t.Error("expected an error detaching an unregistered route")
+	}
+
+	// unregistering a detached route drops its record
+	if err := mgr.UnregisterRoute(namespace, "route3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mgr.IsRouteRegistered(namespace, "route3") {
+		t.Error("expected the detached route to be unregistered")
+	}
+	if err := mgr.ReattachRoute(namespace, "route3"); err != nil || mgr.IsRouteRegistered(namespace, "route3") {
+		t.Errorf("expected reattaching an unregistered route to do nothing, got %v", err)
+	}
+}
+
+func TestDetachRouteFailure(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	key := generateKey(namespace, routeName)
+	sm := &fake.SecretMonitor{Secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}}}
+	mgr := newManager(sm, nil)
+	if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+
+	sm.Err = errors.New("remove failed")
+	if err := mgr.DetachRoute(namespace, routeName); err == nil {
+		t.Fatal("expected an error detaching the route")
+	}
+	if _, detached := mgr.detached[key]; detached {
+		t.Error("expected the route not to be recorded as detached")
+	}
+	if _, registered := mgr.registeredHandlers[key]; !registered {
+		t.Error("expected the route to keep its registration")
+	}
+	if !mgr.IsSecretWatched(namespace, "secret") {
+		t.Error("expected the secret of the route to stay watched")
+	}
+
+	// the route is detached once its handler can be removed
+	sm.Err = nil
+	if err := mgr.DetachRoute(namespace, routeName); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := mgr.GetSecret(context.TODO(), namespace, routeName); !errors.Is(err, ErrRouteDetached) {
+		t.Errorf("expected ErrRouteDetached, got %v", err)
+	}
+}
+
+func TestReattachRoute(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "fallback", Namespace: namespace}},
+	)
+	monitor := secret.NewSecretMonitor(kubeClient)
+	mgr := newManager(monitor, nil)
+
+	var adds atomic.Int32
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(_ interface{}) { adds.Add(1) },
+	}
+	if err := mgr.RegisterRouteWithFallbacks(context.TODO(), namespace, routeName, []string{"secret", "fallback"}, handler); err != nil {
+		t.Fatal(err)
+	}
+	// the secret and its fallback are added before the route is paused
+	if err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return adds.Load() == 2, nil
+	}); err != nil {
+		t.Fatalf("expected the secrets to be added: %v", err)
+	}
+	if err := mgr.PauseRoute(namespace, routeName); err != nil {
+		t.Fatal(err)
+	}
+	adds.Store(0)
+	if err := mgr.DetachRoute(namespace, routeName); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys := monitor.ListMonitoredKeys(); len(keys) != 0 {
+		t.Errorf("expected no informer while detached, got %v", keys)
+	}
+
+	if err := mgr.ReattachRoute(namespace, routeName); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedKeys := []secret.ObjectKey{secret.NewObjectKey(namespace, "fallback"), secret.NewObjectKey(namespace, "secret")}
+	if keys := monitor.ListMonitoredKeys(); !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("expected informers of %v once reattached, got %v", expectedKeys, keys)
+	}
+	expectedRefs := []RouteRef{{Namespace: namespace, RouteName: routeName, SecretName: "secret", FallbackSecretNames: []string{"fallback"}}}
+	if got := mgr.ExportRegistrations(); !reflect.DeepEqual(got, expectedRefs) {
+		t.Errorf("expected the binding %v to be restored, got %v", expectedRefs, got)
+	}
+	if gotSecret, err := mgr.GetSecretBlocking(context.TODO(), namespace, routeName, 5*time.Second); err != nil || gotSecret.Name != "secret" {
+		t.Errorf("expected the route to resolve secret, got %v, %v", gotSecret, err)
+	}
+
+	// the route stays paused with its handler
+	if got := adds.Load(); got != 0 {
+		t.Errorf("expected no event delivered to the paused route, got %d", got)
+	}
+	if err := mgr.ResumeRoute(namespace, routeName); err != nil {
+		t.Fatal(err)
+	}
+	if err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return adds.Load() > 0, nil
+	}); err != nil {
+		t.Errorf("expected the handler of the route to be restored: %v", err)
+	}
+
+	// reattaching a route which is not detached does nothing
+	if err := mgr.ReattachRoute(namespace, routeName); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestUpdateDetachedRoute(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "fallback", Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: namespace}},
+	)
+	monitor := secret.NewSecretMonitor(kubeClient)
+	mgr := newManager(monitor, nil)
+	if err := mgr.RegisterRouteWithFallbacks(context.TODO(), namespace, routeName, []string{"secret", "fallback"}, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.DetachRoute(namespace, routeName); err != nil {
+		t.Fatal(err)
+	}
+
+	// the detached route is exported with its binding
+	expectedRefs := []RouteRef{{Namespace: namespace, RouteName: routeName, SecretName: "secret", FallbackSecretNames: []string{"fallback"}}}
+	if got := mgr.ExportRegistrations(); !reflect.DeepEqual(got, expectedRefs) {
+		t.Errorf("expected the detached route to be exported as %v, got %v", expectedRefs, got)
+	}
+
+	var adds atomic.Int32
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(_ interface{}) { adds.Add(1) },
+	}
+	if err := mgr.UpdateRoute(context.TODO(), namespace, routeName, "new", handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mgr.UpdateRoute(context.TODO(), namespace, routeName, "", handler); err == nil {
+		t.Error("expected an error updating the detached route with an empty secret name")
+	}
+	// the route stays detached with its new binding
+	if keys := monitor.ListMonitoredKeys(); len(keys) != 0 {
+		t.Errorf("expected no informer for the updated detached route, got %v", keys)
+	}
+	if _, err := mgr.GetSecret(context.TODO(), namespace, routeName); !errors.Is(err, ErrRouteDetached) {
+		t.Errorf("expected ErrRouteDetached, got %v", err)
+	}
+	expectedRefs = []RouteRef{{Namespace: namespace, RouteName: routeName, SecretName: "new"}}
+	if got := mgr.ExportRegistrations(); !reflect.DeepEqual(got, expectedRefs) {
+		t.Errorf("expected the detached route to be exported as %v, got %v", expectedRefs, got)
+	}
+	if _, err := mgr.EnsureAndGet(context.TODO(), namespace, routeName, "secret"); !errors.Is(err, ErrRouteDetached) {
+		t.Errorf("expected EnsureAndGet to keep the route detached, got %v", err)
+	}
+
+	// reattaching the route watches its new secret with its new handler
+	if err := mgr.UpdateRoute(context.TODO(), namespace, routeName, "new", handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.ReattachRoute(namespace, routeName); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedKeys := []secret.ObjectKey{secret.NewObjectKey(namespace, "new")}
+	if keys := monitor.ListMonitoredKeys(); !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("expected informers of %v once reattached, got %v", expectedKeys, keys)
+	}
+	if err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return adds.Load() > 0, nil
+	}); err != nil {
+		t.Errorf("expected the new handler to be added: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package secretmanager
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/openshift/library-go/pkg/secret"
+)
+
+// StateDump is a snapshot of the manager state, without any secret data.
+// It is meant to be served by debug endpoints.
+type StateDump struct {
+	Routes   []RouteState `json:"routes"`
+	Secrets  int          `json:"secrets"`
+	Handlers int          `json:"handlers"`
+}
+
+// RouteState describes a registered route and the monitoring of its secret.
+type RouteState struct {
+	// Route is the route key, namespace/route.
+	Route           string `json:"route"`
+	SecretNamespace string `json:"secretNamespace"`
+	SecretName      string `json:"secretName"`
+	// Certificate is the name of the cert-manager Certificate the route was registered from, if any.
+	Certificate string `json:"certificate,omitempty"`
+	// ResourceVersion is the resource version of the cached secret, empty if it is not cached.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	Synced          bool   `json:"synced"`
+	// SecretHandlers is the number of handlers added by the manager for the secret of the route.
+	SecretHandlers int   `json:"secretHandlers"`
+	Adds           int64 `json:"adds"`
+	Updates        int64 `json:"updates"`
+	Deletes        int64 `json:"deletes"`
+	// DegradedReason and Error describe why the secret can't be used to serve the route, see DegradedRoutes.
+	DegradedReason string `json:"degradedReason,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// DumpState returns the JSON encoding of the StateDump of the manager, with the routes sorted by key.
+// Only the metadata of the secrets is read, their data is never part of the dump.
+func (m *manager) DumpState() ([]byte, error) {
+	m.handlersLock.RLock()
+	registrations := make(map[string]secret.SecretEventHandlerRegistration, len(m.registeredHandlers))
+	for key, handlerRegistration := range m.registeredHandlers {
+		registrations[key] = handlerRegistration
+	}
+	certificates := make(map[string]string, len(m.certificates))
+	for key, certName := range m.certificates {
+		certificates[key] = certName
+	}
+	handlers := make(map[secret.ObjectKey]int, len(m.secretRoutes))
+	dump := StateDump{Routes: []RouteState{}, Secrets: len(m.secretRoutes)}
+	for secretKey, routes := range m.secretRoutes {
+		handlers[secretKey] = routes.Len()
+		dump.Handlers += routes.Len()
+	}
+	m.handlersLock.RUnlock()
+
+	for key, handlerRegistration := range registrations {
+		secretKey := handlerRegistration.GetKey()
+		state := RouteState{
+			Route:           key,
+			SecretNamespace: secretKey.Namespace,
+			SecretName:      secretKey.Name,
+			Certificate:     certificates[key],
+			Synced:          handlerRegistration.HasSynced(),
+			SecretHandlers:  handlers[secretKey],
+		}
+		state.Adds, state.Updates, state.Deletes = handlerRegistration.EventCounts()
+		if state.Synced {
+			if obj, err := handlerRegistration.GetSecret(); err == nil {
+				state.ResourceVersion = obj.ResourceVersion
+			}
+		}
+		if reason, message, degraded := m.degradedReason(handlerRegistration); degraded {
+			state.DegradedReason = reason
+			state.Error = message
+		}
+		dump.Routes = append(dump.Routes, state)
+	}
+	sort.Slice(dump.Routes, func(i, j int) bool {
+		return dump.Routes[i].Route < dump.Routes[j].Route
+	})
+
+	return json.Marshal(dump)
+}
@@ -0,0 +1,87 @@
+package secretmanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	"github.com/openshift/library-go/pkg/secret/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDumpState(t *testing.T) {
+	namespace := "ns"
+	certPEM, keyPEM, _ := newCertKeyPEM(t, time.Hour)
+	secretValue := []byte("do-not-dump-this-value")
+	healthy := &corev1.Secret{
+		Type:       corev1.SecretTypeTLS,
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace, ResourceVersion: "42"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+			"extra":                 secretValue,
+		},
+	}
+
+	mgr := newManager(&fake.SecretMonitor{}, nil)
+	for routeName, registration := range map[string]*fake.SecretEventHandlerRegistration{
+		"healthy":  {Secret: healthy, Adds: 1, Updates: 2},
+		"unsynced": {NotSynced: true},
+	} {
+		registration.Key = secret.NewObjectKey(namespace, "secret")
+		key := generateKey(namespace, routeName)
+		mgr.registeredHandlers[key] = registration
+		mgr.indexRoute(key, registration.Key)
+	}
+
+	data, err := mgr.DumpState()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, value := range [][]byte{secretValue, certPEM, keyPEM} {
+		if bytes.Contains(data, value) {
+			t.Fatalf("expected no secret data in the dump, got %s", data)
+		}
+	}
+
+	var dump StateDump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		t.Fatalf("failed to decode the dump: %v", err)
+	}
+	expected := StateDump{
+		Routes: []RouteState{
+			{
+				Route:           "ns/healthy",
+				SecretNamespace: namespace,
+				SecretName:      "secret",
+				ResourceVersion: "42",
+				Synced:          true,
+				SecretHandlers:  2,
+				Adds:            1,
+				Updates:         2,
+			},
+			{
+				Route:           "ns/unsynced",
+				SecretNamespace: namespace,
+				SecretName:      "secret",
+				SecretHandlers:  2,
+				DegradedReason:  DegradedReasonSecretNotSynced,
+				Error:           "secret ns/secret is not synced",
+			},
+		},
+		Secrets:  1,
+		Handlers: 2,
+	}
+	if !reflect.DeepEqual(dump, expected) {
+		t.Errorf("expected %+v, got %+v", expected, dump)
+	}
+	for _, field := range []string{`"route"`, `"secretName"`, `"synced"`, `"secretHandlers"`, `"error"`} {
+		if !bytes.Contains(data, []byte(field)) {
+			t.Errorf("expected field %s in the dump %s", field, data)
+		}
+	}
+}
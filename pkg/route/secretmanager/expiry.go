@@ -0,0 +1,86 @@
+package secretmanager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// ExpiringFunc is called for a registered route whose certificate expires at notAfter.
+type ExpiringFunc func(namespace, routeName string, notAfter time.Time)
+
+// StartExpiryWatcher checks the certificates of all registered routes right away, then every checkInterval
+// according to the clock of the manager, until the context is cancelled.
+// onExpiring is called for every route whose certificate expires within warnBefore, including already expired ones.
+// The certificates are read from the cache without waiting for it to sync, routes whose certificate can't be
+// read or parsed are skipped.
+func (m *manager) StartExpiryWatcher(ctx context.Context, checkInterval, warnBefore time.Duration, onExpiring ExpiringFunc) {
+	go func() {
+		for ctx.Err() == nil {
+			m.checkExpiry(warnBefore, onExpiring)
+
+			timer := m.clock.NewTimer(checkInterval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C():
+			}
+		}
+	}()
+}
+
+// checkExpiry calls onExpiring for every registered route whose cached certificate expires within warnBefore.
+func (m *manager) checkExpiry(warnBefore time.Duration, onExpiring ExpiringFunc) {
+	deadline := m.clock.Now().Add(warnBefore)
+	for _, key := range m.registeredKeys() {
+		namespace, routeName, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			klog.Errorf("invalid route key %s: %v", key, err)
+			continue
+		}
+
+		secret, err := m.cachedSecret(namespace, routeName)
+		if err != nil {
+			klog.V(5).Infof("skipping expiry check of route %s: %v", key, err)
+			continue
+		}
+		notAfter, err := m.certificateExpiry(secret)
+		if err != nil {
+			klog.V(5).Infof("skipping expiry check of route %s: %v", key, err)
+			continue
+		}
+		if notAfter.Before(deadline) {
+			onExpiring(namespace, routeName, notAfter)
+		}
+	}
+}
+
+// SecretAge returns how long ago the secret registered with a route was created, according to the clock
+// of the manager. It helps detecting routes referencing old, possibly stale, secrets.
+// The secret is read from the cache without waiting for it to sync.
+func (m *manager) SecretAge(namespace, routeName string) (time.Duration, error) {
+	secret, err := m.cachedSecret(namespace, routeName)
+	if err != nil {
+		return 0, err
+	}
+	if secret.CreationTimestamp.IsZero() {
+		return 0, fmt.Errorf("secret %s/%s has no creation timestamp", secret.Namespace, secret.Name)
+	}
+	return m.clock.Since(secret.CreationTimestamp.Time), nil
+}
+
+// registeredKeys returns the keys of all registered routes.
+func (m *manager) registeredKeys() []string {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+
+	keys := make([]string, 0, len(m.registeredHandlers))
+	for key := range m.registeredHandlers {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,271 @@
+package secretmanager
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	"github.com/openshift/library-go/pkg/secret/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestStartExpiryWatcher(t *testing.T) {
+	var (
+		namespace  = "ns"
+		routeName  = "route"
+		warnBefore = 2 * time.Hour
+	)
+
+	scenarios := []struct {
+		name           string
+		lifetime       time.Duration
+		expectExpiring bool
+	}{
+		{
+			name:           "soon to expire certificate",
+			lifetime:       time.Hour,
+			expectExpiring: true,
+		},
+		{
+			name:           "long lived certificate",
+			lifetime:       10 * 24 * time.Hour,
+			expectExpiring: false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			certPEM, keyPEM, notAfter := newCertKeyPEM(t, s.lifetime)
+			sm := &fake.SecretMonitor{
+				Secret: &corev1.Secret{
+					Type:       corev1.SecretTypeTLS,
+					ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace},
+					Data: map[string][]byte{
+						corev1.TLSCertKey:       certPEM,
+						corev1.TLSPrivateKeyKey: keyPEM,
+					},
+				},
+			}
+			mgr := newManager(sm, nil)
+			if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+				t.Fatalf("failed to register route: %v", err)
+			}
+
+			var (
+				lock  sync.Mutex
+				calls int
+			)
+			ctx, cancel := context.WithCancel(context.TODO())
+			mgr.StartExpiryWatcher(ctx, 10*time.Millisecond, warnBefore, func(gotNamespace, gotRouteName string, gotNotAfter time.Time) {
+				lock.Lock()
+				defer lock.Unlock()
+				calls += 1
+				if gotNamespace != namespace || gotRouteName != routeName {
+					t.Errorf("expected route %s/%s, got %s/%s", namespace, routeName, gotNamespace, gotRouteName)
+				}
+				if !gotNotAfter.Equal(notAfter) {
+					t.Errorf("expected expiry %v, got %v", notAfter, gotNotAfter)
+				}
+			})
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+			time.Sleep(50 * time.Millisecond)
+
+			lock.Lock()
+			callsAtCancel := calls
+			lock.Unlock()
+			if (callsAtCancel > 0) != s.expectExpiring {
+				t.Fatalf("expected expiring to be %t, got %d calls", s.expectExpiring, callsAtCancel)
+			}
+
+			// the watcher stops with the context
+			time.Sleep(50 * time.Millisecond)
+			lock.Lock()
+			defer lock.Unlock()
+			if calls != callsAtCancel {
+				t.Errorf("expected no calls after the context is cancelled, got %d more", calls-callsAtCancel)
+			}
+		})
+	}
+}
+
+func TestCheckExpiryWithClock(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	certPEM, keyPEM, _ := newCertKeyPEM(t, 10*24*time.Hour)
+	sm := &fake.SecretMonitor{
+		Secret: &corev1.Secret{
+			Type:       corev1.SecretTypeTLS,
+			ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       certPEM,
+				corev1.TLSPrivateKeyKey: keyPEM,
+			},
+		},
+	}
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	mgr := newManager(sm, nil, WithClock(fakeClock))
+	if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("failed to register route: %v", err)
+	}
+
+	calls := 0
+	onExpiring := func(_, _ string, _ time.Time) { calls += 1 }
+	mgr.checkExpiry(time.Hour, onExpiring)
+	if calls != 0 {
+		t.Fatalf("expected the certificate not to be expiring yet, got %d calls", calls)
+	}
+
+	// the certificate expires within the hour after 10 days
+	fakeClock.Step(10*24*time.Hour - 30*time.Minute)
+	mgr.checkExpiry(time.Hour, onExpiring)
+	if calls != 1 {
+		t.Errorf("expected the certificate to be expiring, got %d calls", calls)
+	}
+}
+
+// blockingSecretMonitor is a SecretMonitor whose GetSecret waits for the context, like an informer which never syncs.
+type blockingSecretMonitor struct {
+	fake.SecretMonitor
+}
+
+func (sm *blockingSecretMonitor) GetSecret(ctx context.Context, _ secret.SecretEventHandlerRegistration) (*corev1.Secret, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestCheckExpiryDoesNotWaitForSync(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	certPEM, keyPEM, _ := newCertKeyPEM(t, time.Minute)
+	sm := &blockingSecretMonitor{
+		SecretMonitor: fake.SecretMonitor{
+			Secret: &corev1.Secret{
+				Type:       corev1.SecretTypeTLS,
+				ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace},
+				Data: map[string][]byte{
+					corev1.TLSCertKey:       certPEM,
+					corev1.TLSPrivateKeyKey: keyPEM,
+				},
+			},
+		},
+	}
+	mgr := newManager(sm, nil)
+	if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("failed to register route: %v", err)
+	}
+
+	done := make(chan struct{})
+	calls := 0
+	go func() {
+		defer close(done)
+		mgr.checkExpiry(time.Hour, func(_, _ string, _ time.Time) { calls += 1 })
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the expiry check not to wait for the secret to sync")
+	}
+	if calls != 1 {
+		t.Errorf("expected the cached certificate to be expiring, got %d calls", calls)
+	}
+}
+
+func TestStartExpiryWatcherWithClock(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	certPEM, keyPEM, _ := newCertKeyPEM(t, 10*24*time.Hour)
+	sm := &fake.SecretMonitor{
+		Secret: &corev1.Secret{
+			Type:       corev1.SecretTypeTLS,
+			ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       certPEM,
+				corev1.TLSPrivateKeyKey: keyPEM,
+			},
+		},
+	}
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	mgr := newManager(sm, nil, WithClock(fakeClock))
+	if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("failed to register route: %v", err)
+	}
+
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	mgr.StartExpiryWatcher(ctx, 24*time.Hour, time.Hour, func(_, _ string, _ time.Time) { calls.Add(1) })
+	// the watcher waits on the clock once the first check is done
+	awaitWaiters := func() {
+		if err := wait.PollUntilContextTimeout(context.TODO(), time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+			return fakeClock.HasWaiters(), nil
+		}); err != nil {
+			t.Fatalf("expected the watcher to wait on the clock: %v", err)
+		}
+	}
+	awaitWaiters()
+	if got := calls.Load(); got != 0 {
+		t.Fatalf("expected the certificate not to be expiring yet, got %d calls", got)
+	}
+
+	// the certificate expires within the hour after 10 days, the check of day 9 doesn't see it yet
+	for day := 1; day <= 10; day++ {
+		fakeClock.Step(24 * time.Hour)
+		awaitWaiters()
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected the certificate to be expiring at the check of day 10, got %d calls", got)
+	}
+
+	// the watcher stops with the context
+	cancel()
+	if err := wait.PollUntilContextTimeout(context.TODO(), time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return !fakeClock.HasWaiters(), nil
+	}); err != nil {
+		t.Fatalf("expected the watcher to stop waiting on the clock: %v", err)
+	}
+	fakeClock.Step(24 * time.Hour)
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected no check after the context is cancelled, got %d calls", got)
+	}
+}
+
+func TestSecretAge(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	created := metav1.NewTime(fakeClock.Now().Add(-48 * time.Hour))
+	sm := &fake.SecretMonitor{
+		Secret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace, CreationTimestamp: created},
+		},
+	}
+	mgr := newManager(sm, nil, WithClock(fakeClock))
+	if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("failed to register route: %v", err)
+	}
+
+	age, err := mgr.SecretAge(namespace, routeName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if age != 48*time.Hour {
+		t.Errorf("expected age %v, got %v", 48*time.Hour, age)
+	}
+
+	fakeClock.Step(time.Hour)
+	if age, err = mgr.SecretAge(namespace, routeName); err != nil || age != 49*time.Hour {
+		t.Errorf("expected age %v, got %v, %v", 49*time.Hour, age, err)
+	}
+
+	if _, err := mgr.SecretAge(namespace, "unregistered"); err == nil {
+		t.Error("expected an error for an unregistered route")
+	}
+	sm.Secret.CreationTimestamp = metav1.Time{}
+	if _, err := mgr.SecretAge(namespace, routeName); err == nil {
+		t.Error("expected an error for a secret without creation timestamp")
+	}
+}
@@ -0,0 +1,61 @@
+package secretmanager
+
+import (
+	"context"
+	"fmt"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
+)
+
+// RouteRef is the binding of a registered route to its secrets, see ExportRegistrations.
+type RouteRef struct {
+	Namespace  string
+	RouteName  string
+	SecretName string
+	// FallbackSecretNames are the fallback secrets of routes registered with RegisterRouteWithFallbacks, in order.
+	FallbackSecretNames []string
+}
+
+// ExportRegistrations returns the bindings of the registered routes sorted by namespace and route name,
+// including the routes deferred while the manager is inactive, see SetActive, and the routes detached with
+// DetachRoute, so that another manager, e.g. of a new process taking over, can re-establish the same watches
+// with RegisterRoutes. Handlers are not exported.
+func (m *manager) ExportRegistrations() []RouteRef {
+	bindings := m.routeBindings()
+	refs := make([]RouteRef, 0, len(bindings))
+	for routeKey, secretNames := range bindings {
+		ref := RouteRef{
+			Namespace:  routeKey.Namespace,
+			RouteName:  routeKey.Name,
+			SecretName: secretNames[0],
+		}
+		if len(secretNames) > 1 {
+			ref.FallbackSecretNames = append([]string(nil), secretNames[1:]...)
+		}
+		refs = append(refs, ref)
+	}
+	sortRouteRefs(refs)
+
+	return refs
+}
+
+// RegisterRoutes registers every route with the given handler, with RegisterRouteWithFallbacks if the route
+// has fallback secrets and RegisterRoute otherwise. A route failing to register doesn't prevent the others
+// from being registered: the returned error aggregates the error of every failing route.
+func (m *manager) RegisterRoutes(ctx context.Context, refs []RouteRef, handler cache.ResourceEventHandlerFuncs) error {
+	var errs []error
+	for _, ref := range refs {
+		var err error
+		if len(ref.FallbackSecretNames) > 0 {
+			secretNames := append([]string{ref.SecretName}, ref.FallbackSecretNames...)
+			err = m.RegisterRouteWithFallbacks(ctx, ref.Namespace, ref.RouteName, secretNames, handler)
+		} else {
+			err = m.RegisterRoute(ctx, ref.Namespace, ref.RouteName, ref.SecretName, handler)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to register route key %s: %w", generateKey(ref.Namespace, ref.RouteName), err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
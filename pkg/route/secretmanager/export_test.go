@@ -0,0 +1,97 @@
+package secretmanager
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestExportRegistrations(t *testing.T) {
+	var secrets []*corev1.Secret
+	for _, key := range []secret.ObjectKey{{Namespace: "ns1", Name: "secret1"}, {Namespace: "ns1", Name: "secret2"}, {Namespace: "ns2", Name: "secret"}} {
+		secrets = append(secrets, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}})
+	}
+	kubeClient := kubefake.NewSimpleClientset(secrets[0], secrets[1], secrets[2])
+
+	exporting := newManager(secret.NewSecretMonitor(kubeClient), nil)
+	if err := exporting.RegisterRoute(context.TODO(), "ns2", "route", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporting.RegisterRoute(context.TODO(), "ns1", "route2", "secret2", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporting.RegisterRouteWithFallbacks(context.TODO(), "ns1", "route1", []string{"secret1", "secret2"}, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+
+	refs := exporting.ExportRegistrations()
+	expectedRefs := []RouteRef{
+		{Namespace: "ns1", RouteName: "route1", SecretName: "secret1", FallbackSecretNames: []string{"secret2"}},
+		{Namespace: "ns1", RouteName: "route2", SecretName: "secret2"},
+		{Namespace: "ns2", RouteName: "route", SecretName: "secret"},
+	}
+	if !reflect.DeepEqual(refs, expectedRefs) {
+		t.Fatalf("expected %v, got %v", expectedRefs, refs)
+	}
+
+	importing := newManager(secret.NewSecretMonitor(kubeClient), nil)
+	if err := importing.RegisterRoutes(context.TODO(), refs, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if imported := importing.ExportRegistrations(); !reflect.DeepEqual(imported, expectedRefs) {
+		t.Errorf("expected %v, got %v", expectedRefs, imported)
+	}
+	if !reflect.DeepEqual(importing.Stats(), exporting.Stats()) {
+		t.Errorf("expected stats %+v, got %+v", exporting.Stats(), importing.Stats())
+	}
+	for _, ref := range refs {
+		gotSecret, err := importing.GetSecret(context.TODO(), ref.Namespace, ref.RouteName)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotSecret.Name != ref.SecretName {
+			t.Errorf("expected secret %s for route %s/%s, got %s", ref.SecretName, ref.Namespace, ref.RouteName, gotSecret.Name)
+		}
+	}
+
+	// the routes which can't be registered don't prevent the others from being registered
+	partial := newManager(secret.NewSecretMonitor(kubeClient), nil)
+	err := partial.RegisterRoutes(context.TODO(), []RouteRef{{Namespace: "ns1", RouteName: "invalid"}, refs[1]}, cache.ResourceEventHandlerFuncs{})
+	if err == nil {
+		t.Error("expected an error for the route without secret name")
+	}
+	if !partial.IsRouteRegistered(refs[1].Namespace, refs[1].RouteName) {
+		t.Errorf("expected route %s/%s to be registered", refs[1].Namespace, refs[1].RouteName)
+	}
+}
+
+func TestExportRegistrationsWhileInactive(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "fallback"}},
+	)
+	mgr := newManager(secret.NewSecretMonitor(kubeClient), nil)
+	if err := mgr.RegisterRouteWithFallbacks(context.TODO(), "ns", "route1", []string{"secret", "fallback"}, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.SetActive(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.RegisterRoute(context.TODO(), "ns", "route2", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedRefs := []RouteRef{
+		{Namespace: "ns", RouteName: "route1", SecretName: "secret", FallbackSecretNames: []string{"fallback"}},
+		{Namespace: "ns", RouteName: "route2", SecretName: "secret"},
+	}
+	if refs := mgr.ExportRegistrations(); !reflect.DeepEqual(refs, expectedRefs) {
+		t.Errorf("expected the deferred routes %v to be exported, got %v", expectedRefs, refs)
+	}
+}
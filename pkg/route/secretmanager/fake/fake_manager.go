@@ -2,8 +2,17 @@ package fake
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"sync"
+	"time"
 
+	routev1 "github.com/openshift/api/route/v1"
+	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
+	"github.com/openshift/library-go/pkg/route/secretmanager"
+	"github.com/openshift/library-go/pkg/secret"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 )
@@ -12,11 +21,33 @@ type SecretManager struct {
 	Err          error
 	Secret       *corev1.Secret
 	IsRegistered bool
+	Registration secret.SecretEventHandlerRegistration
+	KeyPair      *tls.Certificate
+	Expiry       time.Time
+	CABundle     []byte
+	Certificate  *x509.Certificate
+	Age          time.Duration
+	Info         secretmanager.HandlerInfo
 }
 
 func (m *SecretManager) RegisterRoute(ctx context.Context, namespace string, routeName string, secretName string, handler cache.ResourceEventHandlerFuncs) error {
 	return m.Err
 }
+func (m *SecretManager) RegisterRouteWithResult(ctx context.Context, namespace string, routeName string, secretName string, handler cache.ResourceEventHandlerFuncs) (secret.SecretEventHandlerRegistration, error) {
+	return m.Registration, m.Err
+}
+func (m *SecretManager) RegisterRoutes(ctx context.Context, refs []secretmanager.RouteRef, handler cache.ResourceEventHandlerFuncs) error {
+	return m.Err
+}
+func (m *SecretManager) RegisterRouteFromSpec(ctx context.Context, route *routev1.Route, handler cache.ResourceEventHandlerFuncs) error {
+	return m.Err
+}
+func (m *SecretManager) UpdateRoute(ctx context.Context, namespace string, routeName string, secretName string, handler cache.ResourceEventHandlerFuncs) error {
+	return m.Err
+}
+func (m *SecretManager) TransferRoute(ctx context.Context, namespace string, oldRouteName string, newRouteName string, secretName string, handler cache.ResourceEventHandlerFuncs) error {
+	return m.Err
+}
 func (m *SecretManager) UnregisterRoute(namespace string, routeName string) error {
 	return m.Err
 }
@@ -24,6 +55,27 @@ func (m *SecretManager) UnregisterRoute(namespace string, routeName string) erro
 func (m *SecretManager) GetSecret(ctx context.Context, namespace string, routeName string) (*corev1.Secret, error) {
 	return m.Secret, m.Err
 }
+func (m *SecretManager) GetSecretCopy(ctx context.Context, namespace string, routeName string) (*corev1.Secret, error) {
+	if m.Err != nil || m.Secret == nil {
+		return nil, m.Err
+	}
+	return m.Secret.DeepCopy(), nil
+}
+func (m *SecretManager) GetSecretBlocking(ctx context.Context, namespace string, routeName string, maxWait time.Duration) (*corev1.Secret, error) {
+	return m.Secret, m.Err
+}
+func (m *SecretManager) GetSecrets(keys []secretmanager.RouteKey) (map[secretmanager.RouteKey]*corev1.Secret, map[secretmanager.RouteKey]error) {
+	secrets := map[secretmanager.RouteKey]*corev1.Secret{}
+	errs := map[secretmanager.RouteKey]error{}
+	for _, key := range keys {
+		if m.Err != nil {
+			errs[key] = m.Err
+			continue
+		}
+		secrets[key] = m.Secret
+	}
+	return secrets, errs
+}
 func (m *SecretManager) IsRouteRegistered(namespace string, routeName string) bool {
 	return m.IsRegistered
 }
@@ -31,3 +83,154 @@ func (m *SecretManager) IsRouteRegistered(namespace string, routeName string) bo
 func (m *SecretManager) Queue() workqueue.RateLimitingInterface {
 	return nil
 }
+
+func (m *SecretManager) GetTLSKeyPair(ctx context.Context, namespace string, routeName string) (*tls.Certificate, error) {
+	return m.KeyPair, m.Err
+}
+
+func (m *SecretManager) GetCertificateExpiry(ctx context.Context, namespace string, routeName string) (time.Time, error) {
+	return m.Expiry, m.Err
+}
+
+func (m *SecretManager) EnsureAndGet(ctx context.Context, namespace string, routeName string, secretName string) (*corev1.Secret, error) {
+	return m.Secret, m.Err
+}
+
+func (m *SecretManager) StartExpiryWatcher(ctx context.Context, checkInterval time.Duration, warnBefore time.Duration, onExpiring secretmanager.ExpiringFunc) {
+}
+
+func (m *SecretManager) ValidateRoute(ctx context.Context, namespace string, routeName string, secretName string) error {
+	return m.Err
+}
+
+func (m *SecretManager) EventCounts(namespace string, routeName string) (adds, updates, deletes int64, err error) {
+	return 0, 0, 0, m.Err
+}
+
+func (m *SecretManager) GetRegistrationToken(namespace string, routeName string) (string, error) {
+	return "", m.Err
+}
+
+func (m *SecretManager) ResolveToken(token string) (secret.SecretEventHandlerRegistration, error) {
+	return m.Registration, m.Err
+}
+
+func (m *SecretManager) Stats() secretmanager.ManagerStats {
+	return secretmanager.ManagerStats{}
+}
+
+func (m *SecretManager) IsSecretWatched(namespace string, secretName string) bool {
+	return m.IsRegistered
+}
+
+func (m *SecretManager) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (m *SecretManager) Close() error {
+	return m.Err
+}
+
+func (m *SecretManager) DegradedRoutes() []secretmanager.DegradedRoute {
+	return nil
+}
+
+func (m *SecretManager) DumpState() ([]byte, error) {
+	return []byte("{}"), m.Err
+}
+
+func (m *SecretManager) RegisterRouteWithFallbacks(ctx context.Context, namespace string, routeName string, secretNames []string, handler cache.ResourceEventHandlerFuncs) error {
+	return m.Err
+}
+
+func (m *SecretManager) GetPreferredSecret(namespace string, routeName string) (*corev1.Secret, error) {
+	return m.Secret, m.Err
+}
+
+func (m *SecretManager) ForceResync(namespace string, routeName string) error {
+	return m.Err
+}
+
+func (m *SecretManager) Subscribe(namespace string, routeName string) (<-chan secretmanager.SecretEvent, func(), error) {
+	if m.Err != nil {
+		return nil, nil, m.Err
+	}
+	events := make(chan secretmanager.SecretEvent)
+	var once sync.Once
+	return events, func() { once.Do(func() { close(events) }) }, nil
+}
+
+func (m *SecretManager) PauseRoute(namespace string, routeName string) error {
+	return m.Err
+}
+
+func (m *SecretManager) ResumeRoute(namespace string, routeName string) error {
+	return m.Err
+}
+
+func (m *SecretManager) DetachRoute(namespace string, routeName string) error {
+	return m.Err
+}
+
+func (m *SecretManager) ReattachRoute(namespace string, routeName string) error {
+	return m.Err
+}
+
+func (m *SecretManager) GetCABundle(namespace string, routeName string) ([]byte, error) {
+	return m.CABundle, m.Err
+}
+
+func (m *SecretManager) GetParsedCertificate(namespace string, routeName string) (*x509.Certificate, error) {
+	return m.Certificate, m.Err
+}
+
+func (m *SecretManager) SecretAge(namespace string, routeName string) (time.Duration, error) {
+	return m.Age, m.Err
+}
+
+func (m *SecretManager) HandlerInfo(namespace string, routeName string) (secretmanager.HandlerInfo, error) {
+	return m.Info, m.Err
+}
+
+func (m *SecretManager) ExportRegistrations() []secretmanager.RouteRef {
+	return nil
+}
+
+func (m *SecretManager) SetActive(active bool) error {
+	return m.Err
+}
+
+func (m *SecretManager) Plan(desired map[secretmanager.RouteKey]secretmanager.SecretReference) secretmanager.ReconcilePlan {
+	return secretmanager.ReconcilePlan{}
+}
+
+func (m *SecretManager) Reconcile(ctx context.Context, plan secretmanager.ReconcilePlan, handler cache.ResourceEventHandlerFuncs) error {
+	return m.Err
+}
+
+func (m *SecretManager) EstimatedCacheBytes() int64 {
+	return 0
+}
+
+func (m *SecretManager) RegisterFromRouteLister(ctx context.Context, lister routev1listers.RouteLister, selector labels.Selector) error {
+	return m.Err
+}
+
+func (m *SecretManager) RegisterFromCertManagerCertificate(ctx context.Context, namespace string, routeName string, certName string, secretName string) error {
+	return m.Err
+}
+
+func (m *SecretManager) IsSecretImmutable(namespace string, routeName string) (bool, error) {
+	if m.Err != nil || m.Secret == nil || m.Secret.Immutable == nil {
+		return false, m.Err
+	}
+	return *m.Secret.Immutable, nil
+}
+
+func (m *SecretManager) LastSyncResourceVersion(namespace string, routeName string) (string, error) {
+	if m.Err != nil || m.Secret == nil {
+		return "", m.Err
+	}
+	return m.Secret.ResourceVersion, nil
+}
@@ -0,0 +1,126 @@
+package secretmanager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift/library-go/pkg/secret"
+	v1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// RegisterRouteWithFallbacks registers the route with an ordered list of secrets. The first secret is the primary
+// secret of the route, used by GetSecret and the other methods of the manager, and the following ones are fallbacks
+// returned by GetPreferredSecret while the previous secrets can't be used, e.g. during a graceful rollout.
+// The handler is added for every secret. Either all the secrets are registered, or none of them.
+func (m *manager) RegisterRouteWithFallbacks(ctx context.Context, namespace, routeName string, secretNames []string, handler cache.ResourceEventHandlerFuncs) error {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+
+	key := generateKey(namespace, routeName)
+	if len(secretNames) == 0 {
+		return fmt.Errorf("no secret provided for route key %s", key)
+	}
+	if names := sets.New[string](secretNames...); names.Len() != len(secretNames) {
+		return fmt.Errorf("duplicate secrets %v provided for route key %s", secretNames, key)
+	}
+
+	if _, err := m.registerRoute(ctx, namespace, routeName, secretNames[0], handler); err != nil {
+		return err
+	}
+	if m.inactive {
+		return m.deferFallbacks(namespace, routeName, secretNames)
+	}
+	fallbacks, err := m.addFallbacks(ctx, namespace, key, secretNames[1:], m.pausers[key])
+	if err != nil {
+		if unregisterErr := m.unregisterRoute(namespace, routeName); unregisterErr != nil {
+			klog.Errorf("secret manager failed to unregister route key %s after failing to add its fallbacks: %v", key, unregisterErr)
+		}
+		return err
+	}
+	if len(fallbacks) > 0 {
+		if m.fallbackHandlers == nil {
+			m.fallbackHandlers = make(map[string][]secret.SecretEventHandlerRegistration)
+		}
+		m.fallbackHandlers[key] = fallbacks
+	}
+	klog.Infof("secret manager registered route for key %s with fallback secrets %v", key, secretNames[1:])
+
+	return nil
+}
+
+// GetPreferredSecret returns the first secret of the route, in registration order, which is cached and holds
+// a valid key pair. The secrets are read from the cache without waiting for it to sync, see DegradedRoutes.
+// Returns an error aggregating the reason of every secret if none can be used.
+func (m *manager) GetPreferredSecret(namespace, routeName string) (*v1.Secret, error) {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+
+	key := generateKey(namespace, routeName)
+	handlerRegistration, exists := m.registeredHandlers[key]
+	if !exists {
+		return nil, fmt.Errorf("no handler registered with key %s", key)
+	}
+
+	var errs []error
+	candidates := append([]secret.SecretEventHandlerRegistration{handlerRegistration}, m.fallbackHandlers[key]...)
+	for _, candidate := range candidates {
+		if reason, message, degraded := m.degradedReason(candidate); degraded {
+			errs = append(errs, fmt.Errorf("%s: %s", reason, message))
+			continue
+		}
+		obj, err := candidate.GetSecret()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return obj, nil
+	}
+
+	return nil, fmt.Errorf("no usable secret for route key %s: %w", key, utilerrors.NewAggregate(errs))
+}
+
+// addFallbacks adds the handler for every fallback secret of the route and indexes them.
+// The handler is the pausable handler of the route, shared with its primary secret.
+// If a handler can't be added, the handlers added so far are removed. The caller must hold handlersLock.
+func (m *manager) addFallbacks(ctx context.Context, namespace, key string, secretNames []string, handler cache.ResourceEventHandler) ([]secret.SecretEventHandlerRegistration, error) {
+	fallbacks := make([]secret.SecretEventHandlerRegistration, 0, len(secretNames))
+	for _, secretName := range secretNames {
+		var handlerRegistration secret.SecretEventHandlerRegistration
+		err := secret.NewObjectKey(namespace, secretName).Validate()
+		if err == nil {
+			handlerRegistration, err = m.monitor.AddSecretEventHandler(ctx, namespace, secretName, handler)
+		}
+		if err != nil {
+			m.removeFallbackHandlers(key, fallbacks)
+			return nil, fmt.Errorf("invalid fallback secret %s for route key %s: %w", secretName, key, err)
+		}
+		fallbacks = append(fallbacks, handlerRegistration)
+		m.indexRoute(key, handlerRegistration.GetKey())
+	}
+	return fallbacks, nil
+}
+
+// removeFallbacks removes the handlers of the fallback secrets of the route. The caller must hold handlersLock.
+func (m *manager) removeFallbacks(key string) {
+	fallbacks := m.fallbackHandlers[key]
+	delete(m.fallbackHandlers, key)
+	m.removeFallbackHandlers(key, fallbacks)
+}
+
+// removeFallbackHandlers removes the given fallback handlers of the route and unindexes their secrets,
+// unless the secret is also the primary secret of the route. The caller must hold handlersLock.
+func (m *manager) removeFallbackHandlers(key string, fallbacks []secret.SecretEventHandlerRegistration) {
+	primary, hasPrimary := m.registeredHandlers[key]
+	for _, handlerRegistration := range fallbacks {
+		if err := m.monitor.RemoveSecretEventHandler(handlerRegistration); err != nil {
+			klog.Errorf("secret manager failed to remove fallback handler of route key %s: %v", key, err)
+		}
+		if !hasPrimary || primary.GetKey() != handlerRegistration.GetKey() {
+			m.unindexRoute(key, handlerRegistration.GetKey())
+		}
+	}
+}
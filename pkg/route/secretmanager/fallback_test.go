@@ -0,0 +1,132 @@
+package secretmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestGetPreferredSecret(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	certPEM, keyPEM, _ := newCertKeyPEM(t, time.Hour)
+	tlsSecret := func(name string) *corev1.Secret {
+		return &corev1.Secret{
+			Type:       corev1.SecretTypeTLS,
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       certPEM,
+				corev1.TLSPrivateKeyKey: keyPEM,
+			},
+		}
+	}
+	invalid := tlsSecret("primary")
+	invalid.Data[corev1.TLSPrivateKeyKey] = []byte("invalid")
+
+	scenarios := []struct {
+		name           string
+		secrets        []runtime.Object
+		expectedSecret string
+		expectErr      bool
+	}{
+		{
+			name:           "valid primary is preferred",
+			secrets:        []runtime.Object{tlsSecret("primary"), tlsSecret("fallback")},
+			expectedSecret: "primary",
+		},
+		{
+			name:           "missing primary falls back",
+			secrets:        []runtime.Object{tlsSecret("fallback")},
+			expectedSecret: "fallback",
+		},
+		{
+			name:           "invalid primary falls back",
+			secrets:        []runtime.Object{invalid, tlsSecret("fallback")},
+			expectedSecret: "fallback",
+		},
+		{
+			name:      "no usable secret",
+			secrets:   []runtime.Object{invalid},
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			mgr := newManager(secret.NewSecretMonitor(kubefake.NewSimpleClientset(s.secrets...)), nil)
+			if err := mgr.RegisterRouteWithFallbacks(context.TODO(), namespace, routeName, []string{"primary", "fallback"}, cache.ResourceEventHandlerFuncs{}); err != nil {
+				t.Fatal(err)
+			}
+
+			// wait for the handlers to sync
+			var (
+				gotSecret *corev1.Secret
+				err       error
+			)
+			_ = wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(_ context.Context) (bool, error) {
+				gotSecret, err = mgr.GetPreferredSecret(namespace, routeName)
+				return err == nil, nil
+			})
+			if s.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got secret %s", gotSecret.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gotSecret.Name != s.expectedSecret {
+				t.Errorf("expected secret %s, got %s", s.expectedSecret, gotSecret.Name)
+			}
+		})
+	}
+}
+
+func TestRegisterRouteWithFallbacks(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	mgr := newManager(secret.NewSecretMonitor(kubefake.NewSimpleClientset()), nil)
+
+	if err := mgr.RegisterRouteWithFallbacks(context.TODO(), namespace, routeName, []string{"primary", "primary"}, cache.ResourceEventHandlerFuncs{}); err == nil {
+		t.Error("expected an error for duplicate secrets")
+	}
+	// nothing is registered if a fallback is invalid
+	if err := mgr.RegisterRouteWithFallbacks(context.TODO(), namespace, routeName, []string{"primary", ""}, cache.ResourceEventHandlerFuncs{}); err == nil {
+		t.Error("expected an error for an empty fallback secret name")
+	}
+	if mgr.IsRouteRegistered(namespace, routeName) || mgr.IsSecretWatched(namespace, "primary") {
+		t.Error("expected the route not to be registered")
+	}
+
+	if err := mgr.RegisterRouteWithFallbacks(context.TODO(), namespace, routeName, []string{"primary", "fallback"}, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (ManagerStats{Routes: 1, Secrets: 2, Handlers: 2}); mgr.Stats() != expected {
+		t.Errorf("expected %+v, got %+v", expected, mgr.Stats())
+	}
+
+	// updating the route drops the fallbacks
+	if err := mgr.UpdateRoute(context.TODO(), namespace, routeName, "fallback", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (ManagerStats{Routes: 1, Secrets: 1, Handlers: 1}); mgr.Stats() != expected {
+		t.Errorf("expected %+v after update, got %+v", expected, mgr.Stats())
+	}
+	if !mgr.IsSecretWatched(namespace, "fallback") || mgr.IsSecretWatched(namespace, "primary") {
+		t.Error("expected only the fallback secret to stay watched")
+	}
+
+	if err := mgr.UnregisterRoute(namespace, routeName); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (ManagerStats{}); mgr.Stats() != expected {
+		t.Errorf("expected %+v after unregistering, got %+v", expected, mgr.Stats())
+	}
+}
@@ -2,23 +2,158 @@ package secretmanager
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
+	routev1 "github.com/openshift/api/route/v1"
+	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
 	"github.com/openshift/library-go/pkg/secret"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 )
 
 type SecretManager interface {
 	RegisterRoute(ctx context.Context, namespace string, routeName string, secretName string, handler cache.ResourceEventHandlerFuncs) error
+	RegisterRouteWithResult(ctx context.Context, namespace string, routeName string, secretName string, handler cache.ResourceEventHandlerFuncs) (secret.SecretEventHandlerRegistration, error)
+	RegisterRouteWithFallbacks(ctx context.Context, namespace string, routeName string, secretNames []string, handler cache.ResourceEventHandlerFuncs) error
+	RegisterRoutes(ctx context.Context, refs []RouteRef, handler cache.ResourceEventHandlerFuncs) error
+	RegisterRouteFromSpec(ctx context.Context, route *routev1.Route, handler cache.ResourceEventHandlerFuncs) error
+	RegisterFromRouteLister(ctx context.Context, lister routev1listers.RouteLister, selector labels.Selector) error
+	RegisterFromCertManagerCertificate(ctx context.Context, namespace string, routeName string, certName string, secretName string) error
+	UpdateRoute(ctx context.Context, namespace string, routeName string, secretName string, handler cache.ResourceEventHandlerFuncs) error
+	TransferRoute(ctx context.Context, namespace string, oldRouteName string, newRouteName string, secretName string, handler cache.ResourceEventHandlerFuncs) error
 	UnregisterRoute(namespace string, routeName string) error
+	ForceResync(namespace string, routeName string) error
+	PauseRoute(namespace string, routeName string) error
+	ResumeRoute(namespace string, routeName string) error
+	DetachRoute(namespace string, routeName string) error
+	ReattachRoute(namespace string, routeName string) error
 	GetSecret(ctx context.Context, namespace string, routeName string) (*v1.Secret, error)
+	GetSecretCopy(ctx context.Context, namespace string, routeName string) (*v1.Secret, error)
+	GetSecretBlocking(ctx context.Context, namespace string, routeName string, maxWait time.Duration) (*v1.Secret, error)
+	GetSecrets(keys []RouteKey) (map[RouteKey]*v1.Secret, map[RouteKey]error)
+	GetPreferredSecret(namespace string, routeName string) (*v1.Secret, error)
 	IsRouteRegistered(namespace string, routeName string) bool
 	Queue() workqueue.RateLimitingInterface
+	Subscribe(namespace string, routeName string) (<-chan SecretEvent, func(), error)
+	GetTLSKeyPair(ctx context.Context, namespace string, routeName string) (*tls.Certificate, error)
+	GetCertificateExpiry(ctx context.Context, namespace string, routeName string) (time.Time, error)
+	GetCABundle(namespace string, routeName string) ([]byte, error)
+	GetParsedCertificate(namespace string, routeName string) (*x509.Certificate, error)
+	EnsureAndGet(ctx context.Context, namespace string, routeName string, secretName string) (*v1.Secret, error)
+	StartExpiryWatcher(ctx context.Context, checkInterval time.Duration, warnBefore time.Duration, onExpiring ExpiringFunc)
+	SecretAge(namespace string, routeName string) (time.Duration, error)
+	ValidateRoute(ctx context.Context, namespace string, routeName string, secretName string) error
+	IsSecretImmutable(namespace string, routeName string) (bool, error)
+	EventCounts(namespace string, routeName string) (adds, updates, deletes int64, err error)
+	LastSyncResourceVersion(namespace string, routeName string) (string, error)
+	GetRegistrationToken(namespace string, routeName string) (string, error)
+	ResolveToken(token string) (secret.SecretEventHandlerRegistration, error)
+	Stats() ManagerStats
+	EstimatedCacheBytes() int64
+	HandlerInfo(namespace string, routeName string) (HandlerInfo, error)
+	IsSecretWatched(namespace string, secretName string) bool
+	DegradedRoutes() []DegradedRoute
+	DumpState() ([]byte, error)
+	ExportRegistrations() []RouteRef
+	Plan(desired map[RouteKey]SecretReference) ReconcilePlan
+	Reconcile(ctx context.Context, plan ReconcilePlan, handler cache.ResourceEventHandlerFuncs) error
+	SetActive(active bool) error
+	Run(ctx context.Context) error
+	Close() error
+}
+
+// Option configures optional behaviour of the manager created by NewManager.
+type Option func(*manager)
+
+// WithKeyNames overrides the secret data keys holding the certificate and the private key.
+// By default the standard kubernetes.io/tls keys (tls.crt and tls.key) are used.
+func WithKeyNames(certKey, keyKey string) Option {
+	return func(m *manager) {
+		m.certKey = certKey
+		m.keyKey = keyKey
+	}
+}
+
+// WithAutoUnregisterOnDelete unregisters a route automatically once the deletion of its secret is observed,
+// after the route's DeleteFunc handler has been invoked.
+func WithAutoUnregisterOnDelete() Option {
+	return func(m *manager) {
+		m.autoUnregisterOnDelete = true
+	}
+}
+
+// WithPausedEventBuffering makes paused routes buffer every secret event and deliver them in order on resume.
+// By default only the latest event received while paused is delivered on resume, see PauseRoute.
+func WithPausedEventBuffering() Option {
+	return func(m *manager) {
+		m.bufferPausedEvents = true
+	}
+}
+
+// WithRotationAnnotations makes changes of the given secret annotations significant, so that routes are enqueued
+// when rotation tooling only bumps an annotation, e.g. a rotated-at timestamp, without changing the certificate material.
+// By default only changes of the certificate material enqueue the routes registered by EnsureAndGet.
+func WithRotationAnnotations(annotations []string) Option {
+	return func(m *manager) {
+		m.rotationAnnotations = annotations
+	}
+}
+
+// WithSecretCopies makes GetSecret return a deep copy of the cached secret, like GetSecretCopy, for callers
+// which can't guarantee not to mutate it. By default GetSecret returns the cached secret, which must be read-only.
+func WithSecretCopies() Option {
+	return func(m *manager) {
+		m.copySecrets = true
+	}
+}
+
+// WithRejectSelfOwnedSecrets makes registering a route fail with ErrSelfOwnedSecret if its secret is owned by
+// the route itself, to prevent cyclic configurations, e.g. a route serving a certificate it generates.
+// The owner references of the secret are read with a live GET, which requires the client of NewManager.
+func WithRejectSelfOwnedSecrets() Option {
+	return func(m *manager) {
+		m.rejectSelfOwnedSecrets = true
+	}
+}
+
+// WithEnqueueJitter staggers the enqueueing of the routes registered by EnsureAndGet which share a secret,
+// so that a single secret event doesn't make them all reconcile at once: the routes of the secret, sorted by
+// key, are enqueued with delays spread evenly over jitter, the first one immediately. The queue deduplicates
+// the routes enqueued again before being processed. By default the routes are enqueued immediately.
+func WithEnqueueJitter(jitter time.Duration) Option {
+	return func(m *manager) {
+		m.enqueueJitter = jitter
+	}
+}
+
+// WithNamespaceInformer makes the monitor created by NewManager watch the namespaces with the given informer,
+// which the caller must start, see secret.WithNamespaceInformer. The routes of a terminating namespace are
+// unregistered once the informers of their secrets are stopped.
+func WithNamespaceInformer(namespaceInformer cache.SharedIndexInformer) Option {
+	return func(m *manager) {
+		m.namespaceInformer = namespaceInformer
+	}
+}
+
+// WithClock sets the clock used by the manager and the informers of its secrets, which defaults to the real clock.
+// It is mostly useful in tests, with a fake clock.
+func WithClock(clock clock.WithDelayedExecution) Option {
+	return func(m *manager) {
+		m.clock = clock
+	}
 }
 
 // Manager is responsible for managing secrets associated with routes. It implements SecretManager.
@@ -26,25 +161,138 @@ type manager struct {
 	// monitor for managing and watching "single" secret dynamically.
 	monitor secret.SecretMonitor
 
+	// kubeClient is used for live reads which must bypass the monitor's cache, such as ValidateRoute.
+	kubeClient kubernetes.Interface
+
 	// Map of registered handlers for each route.
 	// Populated inside RegisterRoute() and used in UnregisterRoute(), GetSecret.
 	// generateKey() will create the map key.
 	registeredHandlers map[string]secret.SecretEventHandlerRegistration
 
+	// Handler registrations of the fallback secrets of the routes registered with RegisterRouteWithFallbacks,
+	// in order, by route key. The primary secret of such routes is in registeredHandlers.
+	// Protected by handlersLock.
+	fallbackHandlers map[string][]secret.SecretEventHandlerRegistration
+
+	// Pausable handlers wrapping the handlers of the routes, by route key. Protected by handlersLock.
+	pausers map[string]*pausableHandler
+
+	// Names of the cert-manager Certificates the routes were registered from, by route key,
+	// see RegisterFromCertManagerCertificate. Protected by handlersLock.
+	certificates map[string]string
+
+	// bufferPausedEvents keeps every event of a paused route instead of the latest one.
+	bufferPausedEvents bool
+
+	// Bindings of the routes registered or deactivated while the manager is inactive, by route key.
+	// Their pausable handlers are kept in pausers. Protected by handlersLock.
+	deferred map[string]deferredRoute
+
+	// Bindings of the routes detached with DetachRoute, by route key. Their pausable handlers are kept
+	// in pausers. Protected by handlersLock.
+	detached map[string]deferredRoute
+
+	// inactive defers the creation of informers, see SetActive. Protected by handlersLock.
+	inactive bool
+
+	// Reverse index of registeredHandlers and fallbackHandlers, from the secrets to the keys of the routes
+	// referencing them. Protected by handlersLock, and written while also holding indexLock.
+	secretRoutes map[secret.ObjectKey]sets.Set[string]
+
+	// indexLock protects secretRoutes for the handlers, which must not acquire handlersLock since it can be
+	// held while waiting for the handlers to sync, e.g. by GetSecret.
+	indexLock sync.RWMutex
+
 	// Lock to protect access to registeredHandlers map.
 	handlersLock sync.RWMutex
 
 	// Work queue to be used by the consumer of this Manager, mostly to add secret change events.
 	queue workqueue.RateLimitingInterface
+
+	// certKey and keyKey are the secret data keys holding the certificate and the private key.
+	certKey string
+	keyKey  string
+
+	// autoUnregisterOnDelete unregisters routes whose secret is deleted.
+	autoUnregisterOnDelete bool
+
+	// rotationAnnotations are the secret annotations whose changes enqueue the routes.
+	rotationAnnotations []string
+
+	// copySecrets makes GetSecret return deep copies of the cached secrets.
+	copySecrets bool
+
+	// rejectSelfOwnedSecrets rejects the secrets owned by the route they are registered with.
+	rejectSelfOwnedSecrets bool
+
+	// reregistrationWindow is the minimum interval between two re-registrations of a route, zero means no limit.
+	reregistrationWindow time.Duration
+
+	// Re-registrations of the routes, by route key, see WithReregistrationWindow. Protected by handlersLock.
+	reregistrations map[string]*reregistration
+
+	// resyncPeriod is the period of the resyncs of the registered routes, zero means no resync.
+	resyncPeriod time.Duration
+
+	// resyncStopCh stops the resyncs once closed, nil means they are stopped. Protected by handlersLock.
+	resyncStopCh chan struct{}
+
+	// namespaceInformer notifies the terminating namespaces to the monitor created by NewManager, nil means they are not watched.
+	namespaceInformer cache.SharedIndexInformer
+
+	// onCertificateExpired is called when the certificate of a secret is found expired, nil means no revalidation.
+	onCertificateExpired ExpiringFunc
+
+	// enqueueJitter is the window over which the routes sharing a secret are enqueued, zero means no delay.
+	enqueueJitter time.Duration
+
+	// routeRegistry records the routes owned by the manager, shared with other managers. nil means no registry.
+	routeRegistry *RouteRegistry
+
+	// clock is used whenever the manager reads the time.
+	clock clock.WithDelayedExecution
+
+	// secretRefExtractor extracts the secrets referenced by a route in RegisterRouteFromSpec.
+	secretRefExtractor SecretRefExtractor
+
+	// subscriptionBufferSize is the size of the buffer of the Subscribe channels, zero means the default.
+	subscriptionBufferSize int
+
+	// slowConsumerPolicy is applied to the subscriptions whose buffer is full.
+	slowConsumerPolicy SlowConsumerPolicy
 }
 
-func NewManager(kubeClient kubernetes.Interface, queue workqueue.RateLimitingInterface) SecretManager {
-	return &manager{
-		monitor:            secret.NewSecretMonitor(kubeClient),
+func NewManager(kubeClient kubernetes.Interface, queue workqueue.RateLimitingInterface, opts ...Option) SecretManager {
+	m := newManager(nil, queue, opts...)
+	monitorOpts := []secret.Option{secret.WithClock(m.clock)}
+	if m.namespaceInformer != nil {
+		monitorOpts = append(monitorOpts, secret.WithNamespaceInformer(m.namespaceInformer), secret.WithOnNamespaceTerminating(m.dropNamespace))
+	}
+	m.monitor = secret.NewSecretMonitor(kubeClient, monitorOpts...)
+	m.kubeClient = kubeClient
+	return m
+}
+
+// newManager creates a manager around the given monitor and applies the options.
+func newManager(monitor secret.SecretMonitor, queue workqueue.RateLimitingInterface, opts ...Option) *manager {
+	m := &manager{
+		monitor:            monitor,
 		handlersLock:       sync.RWMutex{},
 		queue:              queue,
 		registeredHandlers: make(map[string]secret.SecretEventHandlerRegistration),
+		certKey:            secret.TLSCertKey,
+		keyKey:             secret.TLSPrivateKeyKey,
+		clock:              clock.RealClock{},
+		secretRefExtractor: ExternalCertificateRefs,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.resyncPeriod > 0 {
+		m.resyncStopCh = make(chan struct{})
+		go m.runResync(m.resyncStopCh)
 	}
+	return m
 }
 
 // Queue returns the work queue for the manager.
@@ -55,9 +303,22 @@ func (m *manager) Queue() workqueue.RateLimitingInterface {
 // RegisterRoute registers a route with a secret, enabling the manager to watch for the secret changes and associate them with the handler functions.
 // Returns an error if the route is already registered with a secret or if adding the secret event handler fails.
 func (m *manager) RegisterRoute(ctx context.Context, namespace, routeName, secretName string, handler cache.ResourceEventHandlerFuncs) error {
+	_, err := m.RegisterRouteWithResult(ctx, namespace, routeName, secretName, handler)
+	return err
+}
+
+// RegisterRouteWithResult is like RegisterRoute, but also returns the handler registration stored by the manager.
+// The registration can be used to read the secret directly.
+// The registration is nil if the route is deferred while the manager is inactive, see SetActive.
+func (m *manager) RegisterRouteWithResult(ctx context.Context, namespace, routeName, secretName string, handler cache.ResourceEventHandlerFuncs) (secret.SecretEventHandlerRegistration, error) {
 	m.handlersLock.Lock()
 	defer m.handlersLock.Unlock()
 
+	return m.registerRoute(ctx, namespace, routeName, secretName, handler)
+}
+
+// registerRoute implements RegisterRouteWithResult. The caller must hold handlersLock.
+func (m *manager) registerRoute(ctx context.Context, namespace, routeName, secretName string, handler cache.ResourceEventHandlerFuncs) (secret.SecretEventHandlerRegistration, error) {
 	// Generate a unique key for the provided namespace and routeName.
 	key := generateKey(namespace, routeName)
 
@@ -65,21 +326,77 @@ func (m *manager) RegisterRoute(ctx context.Context, namespace, routeName, secre
 	// Each route (namespace/routeName) should be registered only once with any secret.
 	// Note: inside a namespace multiple different routes can be registered(watch) with a common secret.
 	if _, exists := m.registeredHandlers[key]; exists {
-		return fmt.Errorf("route already registered with key %s", key)
+		return nil, fmt.Errorf("route already registered with key %s", key)
+	}
+	if _, exists := m.deferred[key]; exists {
+		return nil, fmt.Errorf("route already registered with key %s", key)
+	}
+	if _, exists := m.detached[key]; exists {
+		return nil, fmt.Errorf("route already registered with key %s", key)
+	}
+
+	// Reject empty namespace or secret name, which would make the secret informer select nothing.
+	if err := secret.NewObjectKey(namespace, secretName).Validate(); err != nil {
+		return nil, fmt.Errorf("invalid secret reference for route key %s: %w", key, err)
+	}
+
+	if m.rejectSelfOwnedSecrets {
+		if err := m.validateNotSelfOwned(ctx, namespace, routeName, secretName); err != nil {
+			return nil, err
+		}
+	}
+
+	// Claim the route before watching its secret, so that another manager doesn't watch it as well.
+	claimed, err := m.claimRoute(key)
+	if err != nil {
+		return nil, err
+	}
+
+	pauser := m.newRoutePauser(namespace, routeName, handler)
+	if m.pausers == nil {
+		m.pausers = make(map[string]*pausableHandler)
+	}
+
+	if m.inactive {
+		m.deferRoute(key, deferredRoute{namespace: namespace, routeName: routeName, secretNames: []string{secretName}})
+		m.pausers[key] = pauser
+		klog.Infof("secret manager deferred registering route for key %s with secret %s while inactive", key, secretName)
+		return nil, nil
 	}
 
 	// Add a secret event handler for the specified namespace and secret, with the handler functions.
 	klog.V(5).Infof("trying to add handler for key %s with secret %s", key, secretName)
-	handlerRegistration, err := m.monitor.AddSecretEventHandler(ctx, namespace, secretName, handler)
+	handlerRegistration, err := m.monitor.AddSecretEventHandler(ctx, namespace, secretName, pauser)
 	if err != nil {
-		return err
+		if claimed {
+			m.releaseRoute(key)
+		}
+		return nil, err
 	}
+	m.pausers[key] = pauser
 
 	// Store the registration in the manager's map. Used during UnregisterRoute() and GetSecret().
 	m.registeredHandlers[key] = handlerRegistration
+	m.indexRoute(key, handlerRegistration.GetKey())
 	klog.Infof("secret manager registered route for key %s with secret %s", key, secretName)
 
-	return nil
+	return handlerRegistration, nil
+}
+
+// newRoutePauser wraps the handler of the route in the pausable handler registered with the monitor.
+// A re-registered route stays paused. The caller must hold handlersLock.
+func (m *manager) newRoutePauser(namespace, routeName string, handler cache.ResourceEventHandlerFuncs) *pausableHandler {
+	pauser := newPausableHandler(handler, m.bufferPausedEvents)
+	if previous, exists := m.pausers[generateKey(namespace, routeName)]; exists {
+		pauser.paused = previous.isPaused()
+	}
+	if m.autoUnregisterOnDelete {
+		pauser.handler = m.withAutoUnregister(namespace, routeName, handler, pauser)
+	}
+	if m.onCertificateExpired != nil {
+		pauser.handler = m.withRevalidation(namespace, routeName, pauser.handler)
+	}
+	return pauser
 }
 
 // UnregisterRoute removes the registration of a route from the manager.
@@ -88,8 +405,26 @@ func (m *manager) UnregisterRoute(namespace, routeName string) error {
 	m.handlersLock.Lock()
 	defer m.handlersLock.Unlock()
 
+	return m.unregisterRoute(namespace, routeName)
+}
+
+// unregisterRoute implements UnregisterRoute. The caller must hold handlersLock.
+func (m *manager) unregisterRoute(namespace, routeName string) error {
 	key := generateKey(namespace, routeName)
 
+	if _, deferred := m.deferred[key]; deferred {
+		delete(m.deferred, key)
+		m.forgetRoute(key)
+		klog.Infof("secret manager unregistered deferred route for key %s", key)
+		return nil
+	}
+	if _, detached := m.detached[key]; detached {
+		delete(m.detached, key)
+		m.forgetRoute(key)
+		klog.Infof("secret manager unregistered detached route for key %s", key)
+		return nil
+	}
+
 	// Get the registered handler.
 	handlerRegistration, exists := m.registeredHandlers[key]
 	if !exists {
@@ -105,18 +440,368 @@ func (m *manager) UnregisterRoute(namespace, routeName string) error {
 
 	// delete the registered handler from manager's map of handlers.
 	delete(m.registeredHandlers, key)
+	m.unindexRoute(key, handlerRegistration.GetKey())
+	m.removeFallbacks(key)
+	m.forgetRoute(key)
 	klog.Infof("secret manager unregistered route for key %s", key)
 
 	return nil
 }
 
+// forgetRoute drops the state kept by the manager for a route whose handlers are removed. The caller must hold handlersLock.
+func (m *manager) forgetRoute(key string) {
+	delete(m.pausers, key)
+	delete(m.certificates, key)
+	m.cancelReregistration(key)
+	m.releaseRoute(key)
+}
+
+// dropNamespace unregisters the routes of a terminating namespace, whose secret monitors were already removed
+// by the monitor, see WithNamespaceInformer, so the handlers of the routes are dropped without removing them.
+func (m *manager) dropNamespace(namespace string) {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+
+	for key, handlerRegistration := range m.registeredHandlers {
+		if handlerRegistration.GetKey().Namespace != namespace {
+			continue
+		}
+		delete(m.registeredHandlers, key)
+		m.unindexRoute(key, handlerRegistration.GetKey())
+		for _, fallback := range m.fallbackHandlers[key] {
+			m.unindexRoute(key, fallback.GetKey())
+		}
+		delete(m.fallbackHandlers, key)
+		m.forgetRoute(key)
+	}
+	for key, binding := range m.deferred {
+		if binding.namespace == namespace {
+			delete(m.deferred, key)
+			m.forgetRoute(key)
+		}
+	}
+	for key, binding := range m.detached {
+		if binding.namespace == namespace {
+			delete(m.detached, key)
+			m.forgetRoute(key)
+		}
+	}
+	klog.Infof("secret manager unregistered the routes of terminating namespace %s", namespace)
+}
+
+// indexRoute adds the route key to the reverse index of the secret. The caller must hold handlersLock.
+func (m *manager) indexRoute(key string, secretKey secret.ObjectKey) {
+	m.indexLock.Lock()
+	defer m.indexLock.Unlock()
+
+	if m.secretRoutes == nil {
+		m.secretRoutes = make(map[secret.ObjectKey]sets.Set[string])
+	}
+	routes, exists := m.secretRoutes[secretKey]
+	if !exists {
+		routes = sets.New[string]()
+		m.secretRoutes[secretKey] = routes
+	}
+	routes.Insert(key)
+}
+
+// unindexRoute removes the route key from the reverse index of the secret. The caller must hold handlersLock.
+func (m *manager) unindexRoute(key string, secretKey secret.ObjectKey) {
+	m.indexLock.Lock()
+	defer m.indexLock.Unlock()
+
+	routes, exists := m.secretRoutes[secretKey]
+	if !exists {
+		return
+	}
+	routes.Delete(key)
+	if routes.Len() == 0 {
+		delete(m.secretRoutes, secretKey)
+	}
+}
+
+// withAutoUnregister wraps the DeleteFunc of the handler to unregister the route once its secret is deleted.
+// pauser is the pausable handler of the route wrapping the returned handler, which identifies the registration.
+func (m *manager) withAutoUnregister(namespace, routeName string, handler cache.ResourceEventHandlerFuncs, pauser *pausableHandler) cache.ResourceEventHandlerFuncs {
+	deleteFunc := handler.DeleteFunc
+	handler.DeleteFunc = func(obj interface{}) {
+		if deleteFunc != nil {
+			deleteFunc(obj)
+		}
+		// The handler can't be removed synchronously from within its own event delivery.
+		go m.unregisterDeletedRoute(namespace, routeName, pauser)
+	}
+	return handler
+}
+
+// unregisterDeletedRoute unregisters the route, unless it was already unregistered or re-registered meanwhile.
+func (m *manager) unregisterDeletedRoute(namespace, routeName string, pauser *pausableHandler) {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+
+	key := generateKey(namespace, routeName)
+	if current, exists := m.pausers[key]; !exists || current != pauser {
+		klog.V(5).Infof("secret manager skipped unregistering route for key %s, registration changed", key)
+		return
+	}
+
+	if err := m.unregisterRoute(namespace, routeName); err != nil {
+		klog.Errorf("secret manager failed to unregister route for key %s after secret deletion: %v", key, err)
+		return
+	}
+	klog.Infof("secret manager unregistered route for key %s after secret deletion", key)
+}
+
+// EnsureAndGet makes sure the route is registered with the given secret and returns the secret.
+// Routes which are not registered yet are registered with a handler adding the route key to the manager's queue.
+// If the route is already registered with a different secret, it is re-registered with the new secret.
+func (m *manager) EnsureAndGet(ctx context.Context, namespace, routeName, secretName string) (*v1.Secret, error) {
+	if err := m.ensureRoute(ctx, namespace, routeName, secretName); err != nil {
+		return nil, err
+	}
+	return m.GetSecret(ctx, namespace, routeName)
+}
+
+// ensureRoute registers the route with the given secret unless it is already registered with it.
+func (m *manager) ensureRoute(ctx context.Context, namespace, routeName, secretName string) error {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+
+	key := generateKey(namespace, routeName)
+	if handlerRegistration, exists := m.registeredHandlers[key]; exists && handlerRegistration.GetKey().Name == secretName {
+		return nil
+	}
+	if deferred, exists := m.deferred[key]; exists && deferred.secretNames[0] == secretName {
+		return nil
+	}
+	if detached, exists := m.detached[key]; exists && detached.secretNames[0] == secretName {
+		return nil
+	}
+
+	_, err := m.replaceRoute(ctx, namespace, routeName, secretName, m.enqueueHandler(key))
+	return err
+}
+
+// UpdateRoute registers the route with the given secret and handler, replacing its current registration if any.
+// The new handler is added before the previous one is removed, so GetSecret keeps resolving the route
+// during the swap. If the new handler can't be added, the route stays registered with its previous secret.
+// A detached route stays detached with the new secret and handler, see DetachRoute.
+func (m *manager) UpdateRoute(ctx context.Context, namespace, routeName, secretName string, handler cache.ResourceEventHandlerFuncs) error {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+
+	_, err := m.replaceRoute(ctx, namespace, routeName, secretName, handler)
+	return err
+}
+
+// TransferRoute moves the registration of a renamed route to its new name, e.g. when a route is recreated
+// under another name. The new route is registered with the handler before the handler of the old route is
+// removed, so the informer of a secret shared by both routes keeps running and is not relisted.
+// Returns an error if the old route is not registered or the new one already is, in which case
+// neither registration is changed.
+func (m *manager) TransferRoute(ctx context.Context, namespace, oldRouteName, newRouteName, secretName string, handler cache.ResourceEventHandlerFuncs) error {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+
+	oldKey := generateKey(namespace, oldRouteName)
+	if _, deferred := m.deferred[oldKey]; deferred {
+		return fmt.Errorf("%w: can't transfer deferred route key %s", ErrManagerInactive, oldKey)
+	}
+	previous, exists := m.registeredHandlers[oldKey]
+	if !exists {
+		return fmt.Errorf("no handler registered with key %s", oldKey)
+	}
+	newKey := generateKey(namespace, newRouteName)
+	if _, err := m.registerRoute(ctx, namespace, newRouteName, secretName, handler); err != nil {
+		return err
+	}
+	if fallbacks := m.fallbackHandlers[oldKey]; len(fallbacks) > 0 {
+		secretNames := make([]string, 0, len(fallbacks))
+		for _, fallback := range fallbacks {
+			secretNames = append(secretNames, fallback.GetKey().Name)
+		}
+		newFallbacks, err := m.addFallbacks(ctx, namespace, newKey, secretNames, m.pausers[newKey])
+		if err != nil {
+			if unregisterErr := m.unregisterRoute(namespace, newRouteName); unregisterErr != nil {
+				klog.Errorf("secret manager failed to unregister route key %s after failing to transfer its fallbacks: %v", newKey, unregisterErr)
+			}
+			return err
+		}
+		m.fallbackHandlers[newKey] = newFallbacks
+	}
+
+	delete(m.registeredHandlers, oldKey)
+	m.unindexRoute(oldKey, previous.GetKey())
+	if err := m.monitor.RemoveSecretEventHandler(previous); err != nil {
+		klog.Errorf("secret manager failed to remove handler of transferred route key %s: %v", oldKey, err)
+	}
+	m.removeFallbacks(oldKey)
+	delete(m.pausers, oldKey)
+	delete(m.certificates, oldKey)
+	m.cancelReregistration(oldKey)
+	m.releaseRoute(oldKey)
+	klog.Infof("secret manager transferred route from key %s to %s with secret %s", oldKey, newKey, secretName)
+
+	return nil
+}
+
+// replaceRoute registers the route with the secret, then removes the handler of its previous registration.
+// The previous registration is returned if the re-registration is throttled, see WithReregistrationWindow.
+// A detached route stays detached, see replaceDetachedRoute.
+// The caller must hold handlersLock.
+func (m *manager) replaceRoute(ctx context.Context, namespace, routeName, secretName string, handler cache.ResourceEventHandlerFuncs) (secret.SecretEventHandlerRegistration, error) {
+	key := generateKey(namespace, routeName)
+	if deferred, exists := m.deferred[key]; exists {
+		delete(m.deferred, key)
+		handlerRegistration, err := m.registerRoute(ctx, namespace, routeName, secretName, handler)
+		if err != nil {
+			m.deferred[key] = deferred
+		}
+		return handlerRegistration, err
+	}
+	if _, exists := m.detached[key]; exists {
+		return nil, m.replaceDetachedRoute(ctx, namespace, routeName, secretName, handler)
+	}
+	previous, exists := m.registeredHandlers[key]
+	if !exists {
+		return m.registerRoute(ctx, namespace, routeName, secretName, handler)
+	}
+	if m.throttleReregistration(namespace, routeName, secretName, handler) {
+		return previous, nil
+	}
+	return m.reregisterRoute(ctx, namespace, routeName, secretName, handler, previous)
+}
+
+// reregisterRoute implements replaceRoute for a route registered with the previous registration, without throttling.
+// The caller must hold handlersLock.
+func (m *manager) reregisterRoute(ctx context.Context, namespace, routeName, secretName string, handler cache.ResourceEventHandlerFuncs, previous secret.SecretEventHandlerRegistration) (secret.SecretEventHandlerRegistration, error) {
+	key := generateKey(namespace, routeName)
+	klog.Infof("secret manager re-registering route for key %s from secret %s to %s", key, previous.GetKey().Name, secretName)
+
+	// Only drop the previous registration from the map for registerRoute, its handler is kept until
+	// the new one is added. The lock is held, so this is never observed by readers.
+	delete(m.registeredHandlers, key)
+	m.unindexRoute(key, previous.GetKey())
+	handlerRegistration, err := m.registerRoute(ctx, namespace, routeName, secretName, handler)
+	if err != nil {
+		m.registeredHandlers[key] = previous
+		m.indexRoute(key, previous.GetKey())
+		return nil, err
+	}
+
+	if err := m.monitor.RemoveSecretEventHandler(previous); err != nil {
+		klog.Errorf("secret manager failed to remove previous handler of route key %s: %v", key, err)
+	}
+	// the route is now registered with a single secret
+	m.removeFallbacks(key)
+	return handlerRegistration, nil
+}
+
+// enqueueHandler returns the handler of routes registered by the manager itself,
+// which adds the route key to the manager's queue on every secret add and delete,
+// and on updates changing the certificate material of the secret, see WithEnqueueJitter.
+func (m *manager) enqueueHandler(key string) cache.ResourceEventHandlerFuncs {
+	enqueue := func(obj interface{}) {
+		if m.queue == nil {
+			return
+		}
+		if delay := m.enqueueDelay(key, obj); delay > 0 {
+			m.queue.AddAfter(key, delay)
+			return
+		}
+		m.queue.Add(key)
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if !m.isSignificantUpdate(oldObj, newObj) {
+				klog.V(5).Infof("secret manager skipped enqueueing route key %s, certificate material unchanged", key)
+				return
+			}
+			enqueue(newObj)
+		},
+		DeleteFunc: enqueue,
+	}
+}
+
+// enqueueDelay returns the delay of the route key enqueued for an event of the secret, spreading the routes
+// of the secret evenly over the enqueue jitter by their position in the reverse index, sorted by key.
+// Returns zero without jitter, or if the secret of the event is unknown.
+func (m *manager) enqueueDelay(key string, obj interface{}) time.Duration {
+	if m.enqueueJitter <= 0 {
+		return 0
+	}
+	s := toSecret(obj)
+	if s == nil {
+		return 0
+	}
+
+	m.indexLock.RLock()
+	routes := sets.List(m.secretRoutes[secret.NewObjectKey(s.Namespace, s.Name)])
+	m.indexLock.RUnlock()
+
+	for i, route := range routes {
+		if route == key {
+			return m.enqueueJitter * time.Duration(i) / time.Duration(len(routes))
+		}
+	}
+	return 0
+}
+
+// isSignificantUpdate returns true if the update changes the certificate material of the secret, i.e. the
+// certificate and key data keys of the manager, see WithKeyNames, and the CA bundle, or one of its rotation
+// annotations. Updates of objects which are not secrets are always significant.
+func (m *manager) isSignificantUpdate(oldObj, newObj interface{}) bool {
+	oldSecret, oldOK := oldObj.(*v1.Secret)
+	newSecret, newOK := newObj.(*v1.Secret)
+	if !oldOK || !newOK {
+		return true
+	}
+	if secret.CertMaterialChanged(oldSecret, newSecret, m.certKey, m.keyKey, secret.TLSCABundleKey) {
+		return true
+	}
+	for _, annotation := range m.rotationAnnotations {
+		oldValue, oldExists := oldSecret.Annotations[annotation]
+		newValue, newExists := newSecret.Annotations[annotation]
+		if oldExists != newExists || oldValue != newValue {
+			return true
+		}
+	}
+	return false
+}
+
 // GetSecret retrieves the secret object registered with a route.
+// The secret is shared with the cache and must not be mutated, unless the manager was created
+// WithSecretCopies. Callers which mutate the secret should use GetSecretCopy instead.
 func (m *manager) GetSecret(ctx context.Context, namespace, routeName string) (*v1.Secret, error) {
+	if m.copySecrets {
+		return m.GetSecretCopy(ctx, namespace, routeName)
+	}
+	return m.getSecret(ctx, namespace, routeName)
+}
+
+// GetSecretCopy is like GetSecret, but returns a deep copy of the secret which the caller is free to mutate.
+func (m *manager) GetSecretCopy(ctx context.Context, namespace, routeName string) (*v1.Secret, error) {
+	obj, err := m.getSecret(ctx, namespace, routeName)
+	if err != nil {
+		return nil, err
+	}
+	return obj.DeepCopy(), nil
+}
+
+// getSecret returns the secret registered with a route from the cache of the monitor.
+func (m *manager) getSecret(ctx context.Context, namespace, routeName string) (*v1.Secret, error) {
 	m.handlersLock.RLock()
 	defer m.handlersLock.RUnlock()
 
 	key := generateKey(namespace, routeName)
 
+	if _, deferred := m.deferred[key]; deferred {
+		return nil, fmt.Errorf("%w: secret of route key %s is not watched", ErrManagerInactive, key)
+	}
+	if _, detached := m.detached[key]; detached {
+		return nil, fmt.Errorf("%w: secret of route key %s is not watched", ErrRouteDetached, key)
+	}
 	handlerRegistration, exists := m.registeredHandlers[key]
 	if !exists {
 		return nil, fmt.Errorf("no handler registered with key %s", key)
@@ -131,14 +816,153 @@ func (m *manager) GetSecret(ctx context.Context, namespace, routeName string) (*
 	return obj, nil
 }
 
-// IsRouteRegistered returns true if route is registered, false otherwise
+// secretPollInterval is how often GetSecretBlocking polls the cache.
+const secretPollInterval = 50 * time.Millisecond
+
+// GetSecretBlocking is like GetSecret, but waits up to maxWait for the cache to sync and to contain the secret.
+// It tells a secret still warming up from a secret which is genuinely absent, in which case it returns
+// an error wrapping secret.ErrSecretNotFound once maxWait has elapsed. Other errors, e.g. of an unregistered
+// route, are returned immediately.
+func (m *manager) GetSecretBlocking(ctx context.Context, namespace, routeName string, maxWait time.Duration) (*v1.Secret, error) {
+	ctx, cancel := context.WithTimeout(ctx, maxWait)
+	defer cancel()
+
+	var (
+		obj     *v1.Secret
+		lastErr error
+	)
+	err := wait.PollUntilContextCancel(ctx, secretPollInterval, true, func(ctx context.Context) (bool, error) {
+		obj, lastErr = m.GetSecret(ctx, namespace, routeName)
+		switch {
+		case lastErr == nil:
+			return true, nil
+		case errors.Is(lastErr, secret.ErrSecretNotFound), errors.Is(lastErr, secret.ErrSecretDeleted), errors.Is(lastErr, secret.ErrCacheNotSynced):
+			// the secret may still show up
+			return false, nil
+		default:
+			return false, lastErr
+		}
+	})
+	if err == nil {
+		return obj, nil
+	}
+	if lastErr == nil || errors.Is(err, lastErr) {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w for route key %s after waiting %v: %w", secret.ErrSecretNotFound, generateKey(namespace, routeName), maxWait, lastErr)
+}
+
+// IsRouteRegistered returns true if route is registered, including routes deferred while the manager
+// is inactive and detached routes, false otherwise
 func (m *manager) IsRouteRegistered(namespace, routeName string) bool {
 	m.handlersLock.RLock()
 	defer m.handlersLock.RUnlock()
 
 	key := generateKey(namespace, routeName)
 	_, exists := m.registeredHandlers[key]
-	return exists
+	_, deferred := m.deferred[key]
+	_, detached := m.detached[key]
+	return exists || deferred || detached
+}
+
+// Run blocks until the context is cancelled, then closes the manager and returns the context error.
+// It is meant to be the entrypoint of controllers using the manager.
+func (m *manager) Run(ctx context.Context) error {
+	klog.Info("secret manager running")
+	<-ctx.Done()
+
+	klog.Info("secret manager shutting down")
+	if err := m.Close(); err != nil {
+		klog.Errorf("secret manager failed to clean up: %v", err)
+	}
+	return ctx.Err()
+}
+
+// Close unregisters every route, which stops the informers of their secrets, and stops the resyncs, see
+// WithResyncPeriod. Errors from routes which could not be unregistered are aggregated.
+func (m *manager) Close() error {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+
+	if m.resyncStopCh != nil {
+		close(m.resyncStopCh)
+		m.resyncStopCh = nil
+	}
+
+	var errs []error
+	for key, handlerRegistration := range m.registeredHandlers {
+		if err := m.monitor.RemoveSecretEventHandler(handlerRegistration); err != nil {
+			errs = append(errs, err)
+		}
+		m.releaseRoute(key)
+	}
+	for key := range m.deferred {
+		m.releaseRoute(key)
+	}
+	for key := range m.detached {
+		m.releaseRoute(key)
+	}
+	for key := range m.reregistrations {
+		m.cancelReregistration(key)
+	}
+	for _, fallbacks := range m.fallbackHandlers {
+		for _, handlerRegistration := range fallbacks {
+			if err := m.monitor.RemoveSecretEventHandler(handlerRegistration); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	m.registeredHandlers = make(map[string]secret.SecretEventHandlerRegistration)
+	m.fallbackHandlers = nil
+	m.deferred = nil
+	m.detached = nil
+	m.pausers = nil
+	m.certificates = nil
+	m.indexLock.Lock()
+	m.secretRoutes = nil
+	m.indexLock.Unlock()
+	klog.Info("secret manager unregistered all routes")
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// IsSecretWatched returns true if at least one registered route references the secret.
+func (m *manager) IsSecretWatched(namespace, secretName string) bool {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+
+	return m.secretRoutes[secret.NewObjectKey(namespace, secretName)].Len() > 0
+}
+
+// EventCounts returns the number of add, update and delete events delivered by the informer
+// of the secret registered with a route, which helps diagnosing routes reconciled too often.
+func (m *manager) EventCounts(namespace, routeName string) (adds, updates, deletes int64, err error) {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+
+	key := generateKey(namespace, routeName)
+	handlerRegistration, exists := m.registeredHandlers[key]
+	if !exists {
+		return 0, 0, 0, fmt.Errorf("no handler registered with key %s", key)
+	}
+
+	adds, updates, deletes = handlerRegistration.EventCounts()
+	return adds, updates, deletes, nil
+}
+
+// LastSyncResourceVersion returns the resource version last observed by the informer of the secret registered with
+// a route, e.g. for reconcile watermarking: a controller recording it can tell whether it has observed at least
+// a given version of the secret. It is empty if unknown, e.g. before the informer synced.
+func (m *manager) LastSyncResourceVersion(namespace, routeName string) (string, error) {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+
+	key := generateKey(namespace, routeName)
+	handlerRegistration, exists := m.registeredHandlers[key]
+	if !exists {
+		return "", fmt.Errorf("no handler registered with key %s", key)
+	}
+	return handlerRegistration.LastSyncResourceVersion(), nil
 }
 
 // generateKey creates a unique identifier for a route
@@ -2,15 +2,22 @@ package secretmanager
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/openshift/library-go/pkg/secret"
 	"github.com/openshift/library-go/pkg/secret/fake"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 )
 
 type routeSecret struct {
@@ -214,7 +221,7 @@ func TestGetSecret(t *testing.T) {
 		},
 		{
 			name:     "error from secret monitor while calling GetSecret",
-			register: []routeSecret{{routeName, "secretName"}},
+			register: []routeSecret{{routeName, "secret-name"}},
 			sm: fake.SecretMonitor{
 				Err: fmt.Errorf("some error"),
 			},
@@ -222,13 +229,13 @@ func TestGetSecret(t *testing.T) {
 		},
 		{
 			name:     "successfully got secret",
-			register: []routeSecret{{routeName, "secretName"}},
+			register: []routeSecret{{routeName, "secret-name"}},
 			sm: fake.SecretMonitor{
 				Err: nil,
 				Secret: &corev1.Secret{
 					Type: corev1.SecretTypeOpaque,
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      "secretName",
+						Name:      "secret-name",
 						Namespace: namespace,
 					},
 				},
@@ -261,6 +268,70 @@ func TestGetSecret(t *testing.T) {
 	}
 }
 
+func TestGetSecretCopy(t *testing.T) {
+	cached := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns", Labels: map[string]string{"key": "value"}},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+	}
+
+	for _, copySecrets := range []bool{false, true} {
+		var opts []Option
+		if copySecrets {
+			opts = append(opts, WithSecretCopies())
+		}
+		mgr := newManager(&fake.SecretMonitor{Secret: cached}, nil, opts...)
+		if err := mgr.RegisterRoute(context.TODO(), "ns", "route", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatal(err)
+		}
+
+		shared, err := mgr.GetSecret(context.TODO(), "ns", "route")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if (shared == cached) == copySecrets {
+			t.Errorf("expected GetSecret to return the cached secret to be %t with copies %t", !copySecrets, copySecrets)
+		}
+
+		copied, err := mgr.GetSecretCopy(context.TODO(), "ns", "route")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if copied == cached || !reflect.DeepEqual(copied, cached) {
+			t.Fatalf("expected an equal copy of %v, got %v", cached, copied)
+		}
+		copied.Labels["key"] = "mutated"
+		copied.Data[corev1.TLSCertKey] = []byte("mutated")
+		if cached.Labels["key"] != "value" || string(cached.Data[corev1.TLSCertKey]) != "cert" {
+			t.Errorf("expected the cached secret to be independent of its copy, got %v", cached)
+		}
+	}
+}
+
+func BenchmarkGetSecret(b *testing.B) {
+	cached := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+		Data:       map[string][]byte{corev1.TLSCertKey: make([]byte, 4096), corev1.TLSPrivateKeyKey: make([]byte, 4096)},
+	}
+	mgr := newManager(&fake.SecretMonitor{Secret: cached}, nil)
+	if err := mgr.RegisterRoute(context.TODO(), "ns", "route", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		b.Fatal(err)
+	}
+
+	for name, get := range map[string]func(context.Context, string, string) (*corev1.Secret, error){
+		"shared": mgr.GetSecret,
+		"copy":   mgr.GetSecretCopy,
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := get(context.TODO(), "ns", "route"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 func TestIsRouteRegistered(t *testing.T) {
 	var (
 		namespace = "ns"
@@ -279,7 +350,7 @@ func TestIsRouteRegistered(t *testing.T) {
 		},
 		{
 			name:     "when route is registered",
-			register: []routeSecret{{routeName, "secretName"}},
+			register: []routeSecret{{routeName, "secret-name"}},
 			expect:   true,
 		},
 	}
@@ -304,3 +375,762 @@ func TestIsRouteRegistered(t *testing.T) {
 		})
 	}
 }
+
+func TestEnsureAndGet(t *testing.T) {
+	var (
+		namespace = "ns"
+		routeName = "route"
+		key       = namespace + "/" + routeName
+	)
+
+	scenarios := []struct {
+		name             string
+		register         []routeSecret
+		secretName       string
+		expectNewHandler bool
+	}{
+		{
+			name:             "first time registration",
+			register:         []routeSecret{},
+			secretName:       "secret1",
+			expectNewHandler: true,
+		},
+		{
+			name:             "already registered with same secret",
+			register:         []routeSecret{{routeName, "secret1"}},
+			secretName:       "secret1",
+			expectNewHandler: false,
+		},
+		{
+			name:             "already registered with different secret",
+			register:         []routeSecret{{routeName, "secret1"}},
+			secretName:       "secret2",
+			expectNewHandler: true,
+		},
+	}
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			sm := &fake.SecretMonitor{
+				Secret: &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      s.secretName,
+						Namespace: namespace,
+					},
+				},
+			}
+			mgr := newManager(sm, nil)
+			for _, rs := range s.register {
+				if err := mgr.RegisterRoute(context.TODO(), namespace, rs.routeName, rs.secretName, cache.ResourceEventHandlerFuncs{}); err != nil {
+					t.Fatalf("failed to register %v: %v", rs, err)
+				}
+			}
+			oldRegistration := mgr.registeredHandlers[key]
+
+			gotSec, err := mgr.EnsureAndGet(context.TODO(), namespace, routeName, s.secretName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(sm.Secret, gotSec) {
+				t.Fatalf("expected %v got %v", sm.Secret, gotSec)
+			}
+
+			registration, exists := mgr.registeredHandlers[key]
+			if !exists {
+				t.Fatalf("%s key should exist", key)
+			}
+			if registration.GetKey() != secret.NewObjectKey(namespace, s.secretName) {
+				t.Errorf("expected route to be registered with %s, got %v", s.secretName, registration.GetKey())
+			}
+			if gotNewHandler := registration != oldRegistration; gotNewHandler != s.expectNewHandler {
+				t.Errorf("expected new handler to be %t, got %t", s.expectNewHandler, gotNewHandler)
+			}
+		})
+	}
+}
+
+func TestRegisterRouteWithResult(t *testing.T) {
+	var (
+		namespace  = "ns"
+		routeName  = "route"
+		secretName = "secret"
+	)
+	mgr := newManager(&fake.SecretMonitor{}, nil)
+
+	registration, err := mgr.RegisterRouteWithResult(context.TODO(), namespace, routeName, secretName, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expectKey := secret.NewObjectKey(namespace, secretName); registration.GetKey() != expectKey {
+		t.Errorf("expected key %v, got %v", expectKey, registration.GetKey())
+	}
+	if stored := mgr.registeredHandlers[generateKey(namespace, routeName)]; stored != registration {
+		t.Errorf("expected returned registration to be the stored one")
+	}
+
+	// the route can still be registered only once
+	if _, err := mgr.RegisterRouteWithResult(context.TODO(), namespace, routeName, secretName, cache.ResourceEventHandlerFuncs{}); err == nil {
+		t.Error("expected error registering the route twice, got nil")
+	}
+}
+
+func TestAutoUnregisterOnDelete(t *testing.T) {
+	var (
+		namespace  = "ns"
+		routeName  = "route"
+		secretName = "secret"
+	)
+
+	scenarios := []struct {
+		name              string
+		opts              []Option
+		expectRegistered  bool
+		expectQueuedRoute bool
+	}{
+		{
+			name:              "route kept registered by default",
+			expectRegistered:  true,
+			expectQueuedRoute: true,
+		},
+		{
+			name:              "route unregistered with auto unregister",
+			opts:              []Option{WithAutoUnregisterOnDelete()},
+			expectRegistered:  false,
+			expectQueuedRoute: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			kubeClient := kubefake.NewSimpleClientset(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace},
+			})
+			monitor := secret.NewNamespacedSecretMonitor(func(namespace string) informers.SharedInformerFactory {
+				return informers.NewSharedInformerFactoryWithOptions(kubeClient, 0, informers.WithNamespace(namespace))
+			})
+			queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			defer queue.ShutDown()
+			mgr := newManager(monitor, queue, s.opts...)
+
+			if _, err := mgr.EnsureAndGet(context.TODO(), namespace, routeName, secretName); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// drain the initial add event
+			if err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(_ context.Context) (bool, error) {
+				return queue.Len() == 1, nil
+			}); err != nil {
+				t.Fatal("timed out waiting for initial add event")
+			}
+			item, _ := queue.Get()
+			queue.Done(item)
+
+			if err := kubeClient.CoreV1().Secrets(namespace).Delete(context.TODO(), secretName, metav1.DeleteOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(_ context.Context) (bool, error) {
+				return queue.Len() == 1 && mgr.IsRouteRegistered(namespace, routeName) == s.expectRegistered, nil
+			}); err != nil {
+				t.Fatalf("expected route registered to be %t and route to be queued", s.expectRegistered)
+			}
+
+			item, _ = queue.Get()
+			if item != generateKey(namespace, routeName) {
+				t.Errorf("expected route key to be queued, got %v", item)
+			}
+			queue.Done(item)
+		})
+	}
+}
+
+func TestRegisterRouteValidation(t *testing.T) {
+	scenarios := []struct {
+		name       string
+		namespace  string
+		secretName string
+		expectErr  error
+	}{
+		{
+			name:       "empty secret name",
+			namespace:  "ns",
+			secretName: "",
+			expectErr:  secret.ErrEmptySecretName,
+		},
+		{
+			name:       "empty namespace",
+			namespace:  "",
+			secretName: "secret",
+			expectErr:  secret.ErrEmptyNamespace,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			mgr := newManager(&fake.SecretMonitor{}, nil)
+			err := mgr.RegisterRoute(context.TODO(), s.namespace, "route", s.secretName, cache.ResourceEventHandlerFuncs{})
+			if !errors.Is(err, s.expectErr) {
+				t.Fatalf("expected %v, got %v", s.expectErr, err)
+			}
+			if len(mgr.registeredHandlers) != 0 {
+				t.Errorf("expected no route to be registered, got %v", mgr.registeredHandlers)
+			}
+		})
+	}
+}
+
+func TestEventCounts(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	mgr := newManager(&fake.SecretMonitor{}, nil)
+
+	if _, _, _, err := mgr.EventCounts(namespace, routeName); err == nil {
+		t.Error("expected an error for an unregistered route")
+	}
+
+	mgr.registeredHandlers[generateKey(namespace, routeName)] = &fake.SecretEventHandlerRegistration{
+		Key:     secret.NewObjectKey(namespace, "secret"),
+		Adds:    1,
+		Updates: 2,
+		Deletes: 3,
+	}
+	adds, updates, deletes, err := mgr.EventCounts(namespace, routeName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if adds != 1 || updates != 2 || deletes != 3 {
+		t.Errorf("expected counts 1/2/3, got %d/%d/%d", adds, updates, deletes)
+	}
+}
+
+func TestLastSyncResourceVersion(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	mgr := newManager(&fake.SecretMonitor{}, nil)
+
+	if _, err := mgr.LastSyncResourceVersion(namespace, routeName); err == nil {
+		t.Error("expected an error for an unregistered route")
+	}
+
+	mgr.registeredHandlers[generateKey(namespace, routeName)] = &fake.SecretEventHandlerRegistration{
+		Key:                 secret.NewObjectKey(namespace, "secret"),
+		SyncResourceVersion: "42",
+	}
+	resourceVersion, err := mgr.LastSyncResourceVersion(namespace, routeName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resourceVersion != "42" {
+		t.Errorf("expected resource version 42, got %q", resourceVersion)
+	}
+}
+
+func TestIsSecretWatched(t *testing.T) {
+	namespace := "ns"
+	mgr := newManager(&fake.SecretMonitor{}, nil)
+
+	for _, routeName := range []string{"route1", "route2"} {
+		if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "referenced", cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !mgr.IsSecretWatched(namespace, "referenced") {
+		t.Error("expected referenced secret to be watched")
+	}
+	if mgr.IsSecretWatched(namespace, "unreferenced") {
+		t.Error("expected unreferenced secret not to be watched")
+	}
+	if mgr.IsSecretWatched("other", "referenced") {
+		t.Error("expected secret of another namespace not to be watched")
+	}
+
+	if err := mgr.UnregisterRoute(namespace, "route1"); err != nil {
+		t.Fatal(err)
+	}
+	if !mgr.IsSecretWatched(namespace, "referenced") {
+		t.Error("expected secret to be watched while a route references it")
+	}
+	if err := mgr.UnregisterRoute(namespace, "route2"); err != nil {
+		t.Fatal(err)
+	}
+	if mgr.IsSecretWatched(namespace, "referenced") {
+		t.Error("expected secret not to be watched after the last route is unregistered")
+	}
+}
+
+func TestRun(t *testing.T) {
+	namespace, routeName, secretName := "ns", "route", "secret"
+	kubeClient := kubefake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace},
+	})
+	monitor := secret.NewSecretMonitor(kubeClient)
+	mgr := newManager(monitor, nil)
+
+	if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, secretName, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(monitor.ListCachedSecrets()) != 1 {
+		t.Fatal("expected the secret to be monitored")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- mgr.Run(ctx)
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v, got %v", context.Canceled, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for Run to return")
+	}
+
+	if mgr.IsRouteRegistered(namespace, routeName) {
+		t.Error("expected the route to be unregistered")
+	}
+	if cached := monitor.ListCachedSecrets(); len(cached) != 0 {
+		t.Errorf("expected the informers to be stopped, got %v", cached)
+	}
+}
+
+func TestUpdateRoute(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret1", Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret2", Namespace: namespace}},
+	)
+	monitor := secret.NewSecretMonitor(kubeClient)
+	mgr := newManager(monitor, nil)
+
+	if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "secret1", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// GetSecret always resolves the route while its secret is swapped
+	stop := make(chan struct{})
+	errs := make(chan error, 1)
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if _, err := mgr.GetSecret(context.TODO(), namespace, routeName); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		secretName := fmt.Sprintf("secret%d", i%2+1)
+		if err := mgr.UpdateRoute(context.TODO(), namespace, routeName, secretName, cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	<-readerDone
+	select {
+	case err := <-errs:
+		t.Fatalf("GetSecret failed during the swap: %v", err)
+	default:
+	}
+
+	gotSecret, err := mgr.GetSecret(context.TODO(), namespace, routeName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotSecret.Name != "secret2" {
+		t.Errorf("expected secret2, got %s", gotSecret.Name)
+	}
+	// the informer of the previous secret is stopped
+	if cached := monitor.ListCachedSecrets(); len(cached) != 1 || cached[0].Name != "secret2" {
+		t.Errorf("expected only secret2 to be monitored, got %v", cached)
+	}
+
+	// the previous registration is kept if the new one fails
+	if err := mgr.UpdateRoute(context.TODO(), namespace, routeName, "", cache.ResourceEventHandlerFuncs{}); err == nil {
+		t.Fatal("expected an error for an empty secret name")
+	}
+	if gotSecret, err := mgr.GetSecret(context.TODO(), namespace, routeName); err != nil || gotSecret.Name != "secret2" {
+		t.Errorf("expected the route to stay registered with secret2, got %v, %v", gotSecret, err)
+	}
+	if !mgr.IsSecretWatched(namespace, "secret2") {
+		t.Error("expected secret2 to stay watched")
+	}
+}
+
+func TestGetSecretBlocking(t *testing.T) {
+	namespace, routeName, secretName := "ns", "route", "secret"
+
+	scenarios := []struct {
+		name         string
+		initial      bool
+		createAfter  time.Duration
+		expectSecret bool
+	}{
+		{
+			name:         "secret found immediately",
+			initial:      true,
+			expectSecret: true,
+		},
+		{
+			name:         "secret found after a delay",
+			createAfter:  100 * time.Millisecond,
+			expectSecret: true,
+		},
+		{
+			name:         "secret genuinely absent",
+			expectSecret: false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			obj := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace}}
+			kubeClient := kubefake.NewSimpleClientset()
+			if s.initial {
+				kubeClient = kubefake.NewSimpleClientset(obj)
+			}
+			mgr := newManager(secret.NewSecretMonitor(kubeClient), nil)
+			if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, secretName, cache.ResourceEventHandlerFuncs{}); err != nil {
+				t.Fatal(err)
+			}
+			if s.createAfter > 0 {
+				time.AfterFunc(s.createAfter, func() {
+					if _, err := kubeClient.CoreV1().Secrets(namespace).Create(context.TODO(), obj, metav1.CreateOptions{}); err != nil {
+						t.Error(err)
+					}
+				})
+			}
+
+			gotSecret, err := mgr.GetSecretBlocking(context.TODO(), namespace, routeName, 2*time.Second)
+			if s.expectSecret {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if gotSecret.Name != secretName {
+					t.Errorf("expected secret %s, got %s", secretName, gotSecret.Name)
+				}
+				return
+			}
+			if !errors.Is(err, secret.ErrSecretNotFound) {
+				t.Errorf("expected %v, got %v", secret.ErrSecretNotFound, err)
+			}
+		})
+	}
+}
+
+func TestGetSecretBlockingUnregistered(t *testing.T) {
+	mgr := newManager(&fake.SecretMonitor{}, nil)
+
+	start := time.Now()
+	_, err := mgr.GetSecretBlocking(context.TODO(), "ns", "route", time.Minute)
+	if err == nil || errors.Is(err, secret.ErrSecretNotFound) {
+		t.Errorf("expected a registration error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected an immediate error, took %v", elapsed)
+	}
+}
+
+func TestEnqueueHandlerSkipsInsignificantUpdates(t *testing.T) {
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+	mgr := newManager(nil, queue)
+	handler := mgr.enqueueHandler("ns/route")
+
+	oldSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns", ResourceVersion: "1"},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+	}
+	annotated := oldSecret.DeepCopy()
+	annotated.ResourceVersion = "2"
+	annotated.Annotations = map[string]string{"foo": "bar"}
+	handler.OnUpdate(oldSecret, annotated)
+	if queue.Len() != 0 {
+		t.Fatalf("expected annotation only update to be skipped, got %d queued items", queue.Len())
+	}
+
+	rotated := annotated.DeepCopy()
+	rotated.Data[corev1.TLSCertKey] = []byte("rotated")
+	handler.OnUpdate(annotated, rotated)
+	if queue.Len() != 1 {
+		t.Fatalf("expected certificate update to enqueue the route, got %d queued items", queue.Len())
+	}
+}
+
+func TestEnqueueHandlerWithKeyNames(t *testing.T) {
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+	mgr := newManager(nil, queue, WithKeyNames("cert.pem", "key.pem"))
+	handler := mgr.enqueueHandler("ns/route")
+
+	oldSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns", ResourceVersion: "1"},
+		Data: map[string][]byte{
+			"cert.pem":        []byte("cert"),
+			"key.pem":         []byte("key"),
+			corev1.TLSCertKey: []byte("unused"),
+		},
+	}
+	// the default keys are not read by the manager
+	unused := oldSecret.DeepCopy()
+	unused.ResourceVersion = "2"
+	unused.Data[corev1.TLSCertKey] = []byte("changed")
+	handler.OnUpdate(oldSecret, unused)
+	if queue.Len() != 0 {
+		t.Fatalf("expected update of an unused key to be skipped, got %d queued items", queue.Len())
+	}
+
+	for _, dataKey := range []string{"cert.pem", "key.pem", secret.TLSCABundleKey} {
+		rotated := unused.DeepCopy()
+		rotated.Data[dataKey] = []byte("rotated")
+		handler.OnUpdate(unused, rotated)
+		if queue.Len() != 1 {
+			t.Fatalf("expected update of %s to enqueue the route, got %d queued items", dataKey, queue.Len())
+		}
+		item, _ := queue.Get()
+		queue.Forget(item)
+		queue.Done(item)
+	}
+}
+
+func TestTransferRoute(t *testing.T) {
+	namespace, secretName := "ns", "secret"
+	kubeClient := kubefake.NewSimpleClientset(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace}})
+	mgr := newManager(secret.NewSecretMonitor(kubeClient), nil)
+
+	if err := mgr.RegisterRoute(context.TODO(), namespace, "old", secretName, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.RegisterRoute(context.TODO(), namespace, "other", "other-secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	listCount := func() int {
+		count := 0
+		for _, action := range kubeClient.Actions() {
+			if action.GetVerb() == "list" && action.GetResource().Resource == "secrets" {
+				count += 1
+			}
+		}
+		return count
+	}
+	lists := listCount()
+
+	if err := mgr.TransferRoute(context.TODO(), namespace, "old", "new", secretName, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mgr.IsRouteRegistered(namespace, "old") {
+		t.Error("expected the old route to be unregistered")
+	}
+	if gotSecret, err := mgr.GetSecret(context.TODO(), namespace, "new"); err != nil || gotSecret.Name != secretName {
+		t.Errorf("expected the new route to resolve %s, got %v, %v", secretName, gotSecret, err)
+	}
+	if !mgr.IsSecretWatched(namespace, secretName) {
+		t.Error("expected the secret to stay watched")
+	}
+	// the informer of the secret is preserved, so the secret is not listed again
+	if got := listCount(); got != lists {
+		t.Errorf("expected no new list of secrets, got %d lists instead of %d", got, lists)
+	}
+
+	// failed transfers don't change the registrations
+	if err := mgr.TransferRoute(context.TODO(), namespace, "missing", "route", secretName, cache.ResourceEventHandlerFuncs{}); err == nil {
+		t.Error("expected an error transferring an unregistered route")
+	}
+	if err := mgr.TransferRoute(context.TODO(), namespace, "new", "other", secretName, cache.ResourceEventHandlerFuncs{}); err == nil {
+		t.Error("expected an error transferring to a registered route")
+	}
+	if !mgr.IsRouteRegistered(namespace, "new") {
+		t.Error("expected the new route to stay registered")
+	}
+}
+
+func TestRotationAnnotations(t *testing.T) {
+	oldSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns", Annotations: map[string]string{"rotated-at": "1"}},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+	}
+	rotated := oldSecret.DeepCopy()
+	rotated.Annotations["rotated-at"] = "2"
+	unwatched := oldSecret.DeepCopy()
+	unwatched.Annotations["other"] = "value"
+	removed := oldSecret.DeepCopy()
+	removed.Annotations = nil
+
+	scenarios := []struct {
+		name         string
+		opts         []Option
+		newSecret    *corev1.Secret
+		expectQueued bool
+	}{
+		{
+			name:      "annotation ignored by default",
+			newSecret: rotated,
+		},
+		{
+			name:         "watched annotation changed",
+			opts:         []Option{WithRotationAnnotations([]string{"rotated-at"})},
+			newSecret:    rotated,
+			expectQueued: true,
+		},
+		{
+			name:         "watched annotation removed",
+			opts:         []Option{WithRotationAnnotations([]string{"rotated-at"})},
+			newSecret:    removed,
+			expectQueued: true,
+		},
+		{
+			name:      "unwatched annotation changed",
+			opts:      []Option{WithRotationAnnotations([]string{"rotated-at"})},
+			newSecret: unwatched,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			defer queue.ShutDown()
+			mgr := newManager(nil, queue, s.opts...)
+
+			mgr.enqueueHandler("ns/route").OnUpdate(oldSecret, s.newSecret)
+			if queued := queue.Len() == 1; queued != s.expectQueued {
+				t.Errorf("expected route queued to be %t, got %t", s.expectQueued, queued)
+			}
+		})
+	}
+}
+
+// delayRecordingQueue is a queue recording the delays of the enqueued keys, zero for Add.
+type delayRecordingQueue struct {
+	workqueue.RateLimitingInterface
+	lock   sync.Mutex
+	delays map[interface{}]time.Duration
+}
+
+func (q *delayRecordingQueue) Add(item interface{}) {
+	q.AddAfter(item, 0)
+}
+
+func (q *delayRecordingQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.delays[item] = duration
+}
+
+func (q *delayRecordingQueue) recorded() map[interface{}]time.Duration {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	delays := make(map[interface{}]time.Duration, len(q.delays))
+	for item, delay := range q.delays {
+		delays[item] = delay
+	}
+	return delays
+}
+
+func TestEnqueueJitter(t *testing.T) {
+	namespace := "ns"
+	jitter := 900 * time.Millisecond
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "shared"},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+	}
+	kubeClient := kubefake.NewSimpleClientset(s)
+	queue := &delayRecordingQueue{delays: map[interface{}]time.Duration{}}
+	mgr := newManager(secret.NewSecretMonitor(kubeClient), queue, WithEnqueueJitter(jitter))
+
+	for _, route := range []string{"route3", "route1", "route2"} {
+		if _, err := mgr.EnsureAndGet(context.TODO(), namespace, route, s.Name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// drop the enqueues of the initial add events, delivered while the routes were being registered
+	if err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(_ context.Context) (bool, error) {
+		return len(queue.recorded()) == 3, nil
+	}); err != nil {
+		t.Fatalf("timed out waiting for the initial enqueues, got %v", queue.recorded())
+	}
+	queue.lock.Lock()
+	queue.delays = map[interface{}]time.Duration{}
+	queue.lock.Unlock()
+
+	rotated := s.DeepCopy()
+	rotated.Data[corev1.TLSCertKey] = []byte("rotated")
+	if _, err := kubeClient.CoreV1().Secrets(namespace).Update(context.TODO(), rotated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[interface{}]time.Duration{
+		"ns/route1": 0,
+		"ns/route2": jitter / 3,
+		"ns/route3": 2 * jitter / 3,
+	}
+	if err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(_ context.Context) (bool, error) {
+		return len(queue.recorded()) == len(expected), nil
+	}); err != nil {
+		t.Fatalf("timed out waiting for the routes to be enqueued, got %v", queue.recorded())
+	}
+	if delays := queue.recorded(); !reflect.DeepEqual(delays, expected) {
+		t.Errorf("expected delays %v, got %v", expected, delays)
+	}
+}
+
+func TestNamespaceTerminatingDropsRoutes(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "fallback", Namespace: "ns"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "other"}},
+	)
+	namespaceInformer := informers.NewSharedInformerFactory(kubeClient, 0).Core().V1().Namespaces().Informer()
+	mgr := NewManager(kubeClient, nil, WithNamespaceInformer(namespaceInformer)).(*manager)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go namespaceInformer.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), namespaceInformer.HasSynced) {
+		t.Fatal("failed to sync the namespace informer")
+	}
+
+	if err := mgr.RegisterRouteWithFallbacks(context.TODO(), "ns", "route", []string{"secret", "fallback"}, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.RegisterRoute(context.TODO(), "ns", "detached", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.DetachRoute("ns", "detached"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.RegisterRoute(context.TODO(), "other", "route", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := metav1.Now()
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns", DeletionTimestamp: &now}}
+	if _, err := kubeClient.CoreV1().Namespaces().Update(context.TODO(), namespace, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return !mgr.IsRouteRegistered("ns", "route"), nil
+	}); err != nil {
+		t.Fatalf("expected the route of the terminating namespace to be unregistered: %v", err)
+	}
+
+	if mgr.IsRouteRegistered("ns", "detached") {
+		t.Error("expected the detached route of the terminating namespace to be unregistered")
+	}
+	for _, secretName := range []string{"secret", "fallback"} {
+		if mgr.IsSecretWatched("ns", secretName) {
+			t.Errorf("expected secret %s of the terminating namespace not to be watched", secretName)
+		}
+	}
+	if !mgr.IsRouteRegistered("other", "route") || !mgr.IsSecretWatched("other", "secret") {
+		t.Error("expected the route of the other namespace to be kept")
+	}
+	// the route can't be unregistered twice
+	if err := mgr.UnregisterRoute("ns", "route"); err == nil {
+		t.Error("expected an error unregistering the dropped route")
+	}
+}
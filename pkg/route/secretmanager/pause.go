@@ -0,0 +1,119 @@
+package secretmanager
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// PauseRoute stops delivering the secret events of the route to its handler, without stopping the informer
+// of the secret. Events received while paused are dropped, except the latest one which is delivered on
+// ResumeRoute, or are all buffered with WithPausedEventBuffering.
+func (m *manager) PauseRoute(namespace, routeName string) error {
+	pauser, err := m.pauser(namespace, routeName)
+	if err != nil {
+		return err
+	}
+	pauser.pause()
+	klog.Infof("secret manager paused route for key %s", generateKey(namespace, routeName))
+	return nil
+}
+
+// ResumeRoute resumes delivering the secret events of a paused route, starting with the events received while
+// paused, see PauseRoute. Resuming a route which is not paused does nothing. It must not be called from the
+// handler of the route.
+func (m *manager) ResumeRoute(namespace, routeName string) error {
+	pauser, err := m.pauser(namespace, routeName)
+	if err != nil {
+		return err
+	}
+	pauser.resume()
+	klog.Infof("secret manager resumed route for key %s", generateKey(namespace, routeName))
+	return nil
+}
+
+// pauser returns the pausable handler of the registered route.
+func (m *manager) pauser(namespace, routeName string) (*pausableHandler, error) {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+
+	key := generateKey(namespace, routeName)
+	pauser, exists := m.pausers[key]
+	if !exists {
+		return nil, fmt.Errorf("no handler registered with key %s", key)
+	}
+	return pauser, nil
+}
+
+// pausableHandler is a cache.ResourceEventHandler which holds back the events of the wrapped handler while paused.
+type pausableHandler struct {
+	handler cache.ResourceEventHandler
+
+	// buffer keeps every event received while paused, instead of the latest one only.
+	buffer bool
+
+	// lock serializes the delivery of the events, including the ones delivered on resume.
+	lock    sync.Mutex
+	paused  bool
+	pending []func()
+}
+
+func newPausableHandler(handler cache.ResourceEventHandler, buffer bool) *pausableHandler {
+	return &pausableHandler{handler: handler, buffer: buffer}
+}
+
+func (p *pausableHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	p.deliver(func() { p.handler.OnAdd(obj, isInInitialList) })
+}
+
+func (p *pausableHandler) OnUpdate(oldObj, newObj interface{}) {
+	p.deliver(func() { p.handler.OnUpdate(oldObj, newObj) })
+}
+
+func (p *pausableHandler) OnDelete(obj interface{}) {
+	p.deliver(func() { p.handler.OnDelete(obj) })
+}
+
+// deliver invokes the event, or records it if paused.
+func (p *pausableHandler) deliver(event func()) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.paused {
+		event()
+		return
+	}
+	if p.buffer {
+		p.pending = append(p.pending, event)
+	} else {
+		p.pending = []func(){event}
+	}
+}
+
+func (p *pausableHandler) isPaused() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.paused
+}
+
+func (p *pausableHandler) pause() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.paused = true
+}
+
+// resume delivers the pending events, then delivers the events as they are received.
+func (p *pausableHandler) resume() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, event := range p.pending {
+		event()
+	}
+	p.pending = nil
+	p.paused = false
+}
@@ -0,0 +1,107 @@
+package secretmanager
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/secret/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestPauseRoute(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	secretVersion := func(resourceVersion string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace, ResourceVersion: resourceVersion}}
+	}
+
+	scenarios := []struct {
+		name     string
+		opts     []Option
+		expected []string
+	}{
+		{
+			name:     "latest event delivered on resume",
+			expected: []string{"update 3"},
+		},
+		{
+			name:     "every event delivered on resume with buffering",
+			opts:     []Option{WithPausedEventBuffering()},
+			expected: []string{"update 2", "update 3"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var delivered []string
+			mgr := newManager(&fake.SecretMonitor{}, nil, s.opts...)
+			if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "secret", cache.ResourceEventHandlerFuncs{
+				UpdateFunc: func(_, newObj interface{}) {
+					delivered = append(delivered, "update "+newObj.(*corev1.Secret).ResourceVersion)
+				},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			// the fake monitor doesn't deliver events, deliver them to the handler added by the manager
+			handler := mgr.pausers[generateKey(namespace, routeName)]
+
+			handler.OnUpdate(secretVersion("0"), secretVersion("1"))
+			if !reflect.DeepEqual(delivered, []string{"update 1"}) {
+				t.Fatalf("expected the event to be delivered, got %v", delivered)
+			}
+			delivered = nil
+
+			if err := mgr.PauseRoute(namespace, routeName); err != nil {
+				t.Fatal(err)
+			}
+			handler.OnUpdate(secretVersion("1"), secretVersion("2"))
+			handler.OnUpdate(secretVersion("2"), secretVersion("3"))
+			if len(delivered) != 0 {
+				t.Fatalf("expected events of the paused route to be held back, got %v", delivered)
+			}
+
+			if err := mgr.ResumeRoute(namespace, routeName); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(delivered, s.expected) {
+				t.Errorf("expected %v to be delivered on resume, got %v", s.expected, delivered)
+			}
+
+			// events are delivered again once resumed
+			delivered = nil
+			handler.OnUpdate(secretVersion("3"), secretVersion("4"))
+			if !reflect.DeepEqual(delivered, []string{"update 4"}) {
+				t.Errorf("expected the event to be delivered after resume, got %v", delivered)
+			}
+		})
+	}
+}
+
+func TestPauseRouteUnregistered(t *testing.T) {
+	mgr := newManager(&fake.SecretMonitor{}, nil)
+	if err := mgr.PauseRoute("ns", "route"); err == nil {
+		t.Error("expected an error pausing an unregistered route")
+	}
+	if err := mgr.ResumeRoute("ns", "route"); err == nil {
+		t.Error("expected an error resuming an unregistered route")
+	}
+}
+
+func TestPausedRouteStaysPausedOnUpdate(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	mgr := newManager(&fake.SecretMonitor{}, nil)
+	if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "secret1", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.PauseRoute(namespace, routeName); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.UpdateRoute(context.TODO(), namespace, routeName, "secret2", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if !mgr.pausers[generateKey(namespace, routeName)].isPaused() {
+		t.Error("expected the re-registered route to stay paused")
+	}
+}
@@ -0,0 +1,122 @@
+package secretmanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ReconcilePlan lists the changes needed for the registered routes to match a desired set of bindings,
+// see Plan. Every list is sorted by namespace and route name.
+type ReconcilePlan struct {
+	// ToAdd are the desired routes which are not registered.
+	ToAdd []RouteRef
+	// ToRemove are the registered routes which are not desired.
+	ToRemove []RouteKey
+	// ToUpdate are the registered routes bound to other secrets than the desired one, including
+	// routes registered with fallback secrets.
+	ToUpdate []RouteRef
+}
+
+// IsEmpty returns true if the plan doesn't change any route.
+func (p ReconcilePlan) IsEmpty() bool {
+	return len(p.ToAdd) == 0 && len(p.ToRemove) == 0 && len(p.ToUpdate) == 0
+}
+
+// Plan returns the changes Reconcile would make for the registered routes to match the desired secret of
+// every route, without changing anything. Routes deferred while the manager is inactive, and detached routes,
+// count as registered.
+func (m *manager) Plan(desired map[RouteKey]SecretReference) ReconcilePlan {
+	actual := m.routeBindings()
+
+	var plan ReconcilePlan
+	for key, ref := range desired {
+		desiredRef := RouteRef{Namespace: key.Namespace, RouteName: key.Name, SecretName: ref.Name}
+		secretNames, exists := actual[key]
+		switch {
+		case !exists:
+			plan.ToAdd = append(plan.ToAdd, desiredRef)
+		case len(secretNames) != 1 || secretNames[0] != ref.Name:
+			plan.ToUpdate = append(plan.ToUpdate, desiredRef)
+		}
+	}
+	for key := range actual {
+		if _, exists := desired[key]; !exists {
+			plan.ToRemove = append(plan.ToRemove, key)
+		}
+	}
+
+	sortRouteRefs(plan.ToAdd)
+	sortRouteRefs(plan.ToUpdate)
+	sort.Slice(plan.ToRemove, func(i, j int) bool {
+		return routeKeyLess(plan.ToRemove[i], plan.ToRemove[j])
+	})
+	return plan
+}
+
+// Reconcile executes the plan: it unregisters the routes to remove, registers the routes to add with the handler,
+// and updates the routes to update with the handler, see UpdateRoute. The plan is not executed atomically, so
+// it should be computed again by Plan after an error. A route failing doesn't prevent the others from being
+// changed: the returned error aggregates the error of every failing route.
+func (m *manager) Reconcile(ctx context.Context, plan ReconcilePlan, handler cache.ResourceEventHandlerFuncs) error {
+	var errs []error
+	for _, key := range plan.ToRemove {
+		if err := m.UnregisterRoute(key.Namespace, key.Name); err != nil {
+			errs = append(errs, fmt.Errorf("failed to unregister route key %s: %w", key, err))
+		}
+	}
+	for _, ref := range plan.ToAdd {
+		if err := m.RegisterRoute(ctx, ref.Namespace, ref.RouteName, ref.SecretName, handler); err != nil {
+			errs = append(errs, fmt.Errorf("failed to register route key %s: %w", generateKey(ref.Namespace, ref.RouteName), err))
+		}
+	}
+	for _, ref := range plan.ToUpdate {
+		if err := m.UpdateRoute(ctx, ref.Namespace, ref.RouteName, ref.SecretName, handler); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update route key %s: %w", generateKey(ref.Namespace, ref.RouteName), err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// routeBindings returns the secrets of every registered, deferred or detached route, primary secret first.
+func (m *manager) routeBindings() map[RouteKey][]string {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+
+	bindings := make(map[RouteKey][]string, len(m.registeredHandlers)+len(m.deferred)+len(m.detached))
+	for key, handlerRegistration := range m.registeredHandlers {
+		secretKey := handlerRegistration.GetKey()
+		routeKey := RouteKey{Namespace: secretKey.Namespace, Name: strings.TrimPrefix(key, secretKey.Namespace+"/")}
+		secretNames := []string{secretKey.Name}
+		for _, fallback := range m.fallbackHandlers[key] {
+			secretNames = append(secretNames, fallback.GetKey().Name)
+		}
+		bindings[routeKey] = secretNames
+	}
+	for _, deferred := range m.deferred {
+		bindings[RouteKey{Namespace: deferred.namespace, Name: deferred.routeName}] = deferred.secretNames
+	}
+	for _, detached := range m.detached {
+		bindings[RouteKey{Namespace: detached.namespace, Name: detached.routeName}] = detached.secretNames
+	}
+	return bindings
+}
+
+// routeKeyLess orders route keys by namespace and route name.
+func routeKeyLess(a, b RouteKey) bool {
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// sortRouteRefs sorts route references by namespace and route name.
+func sortRouteRefs(refs []RouteRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		return routeKeyLess(RouteKey{Namespace: refs[i].Namespace, Name: refs[i].RouteName}, RouteKey{Namespace: refs[j].Namespace, Name: refs[j].RouteName})
+	})
+}
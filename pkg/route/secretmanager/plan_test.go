@@ -0,0 +1,66 @@
+package secretmanager
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestPlan(t *testing.T) {
+	var secrets []*corev1.Secret
+	for _, name := range []string{"secret1", "secret2", "secret3"} {
+		secrets = append(secrets, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}})
+	}
+	kubeClient := kubefake.NewSimpleClientset(secrets[0], secrets[1], secrets[2])
+	mgr := newManager(secret.NewSecretMonitor(kubeClient), nil)
+
+	for _, r := range []routeSecret{{"unchanged", "secret1"}, {"changed", "secret1"}, {"removed", "secret2"}} {
+		if err := mgr.RegisterRoute(context.TODO(), "ns", r.routeName, r.secretName, cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mgr.RegisterRouteWithFallbacks(context.TODO(), "ns", "with-fallback", []string{"secret2", "secret3"}, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+
+	desired := map[RouteKey]SecretReference{
+		{Namespace: "ns", Name: "unchanged"}:     {Name: "secret1"},
+		{Namespace: "ns", Name: "changed"}:       {Name: "secret2"},
+		{Namespace: "ns", Name: "with-fallback"}: {Name: "secret2"},
+		{Namespace: "ns", Name: "added2"}:        {Name: "secret3"},
+		{Namespace: "ns", Name: "added1"}:        {Name: "secret1"},
+	}
+	plan := mgr.Plan(desired)
+	expected := ReconcilePlan{
+		ToAdd: []RouteRef{
+			{Namespace: "ns", RouteName: "added1", SecretName: "secret1"},
+			{Namespace: "ns", RouteName: "added2", SecretName: "secret3"},
+		},
+		ToRemove: []RouteKey{{Namespace: "ns", Name: "removed"}},
+		ToUpdate: []RouteRef{
+			{Namespace: "ns", RouteName: "changed", SecretName: "secret2"},
+			{Namespace: "ns", RouteName: "with-fallback", SecretName: "secret2"},
+		},
+	}
+	if !reflect.DeepEqual(plan, expected) {
+		t.Fatalf("expected plan %+v, got %+v", expected, plan)
+	}
+
+	// planning has no side effects
+	if !mgr.IsRouteRegistered("ns", "removed") || mgr.IsRouteRegistered("ns", "added1") {
+		t.Error("expected the registrations to be unchanged by Plan")
+	}
+
+	if err := mgr.Reconcile(context.TODO(), plan, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan := mgr.Plan(desired); !plan.IsEmpty() {
+		t.Errorf("expected an empty plan after reconciling, got %+v", plan)
+	}
+}
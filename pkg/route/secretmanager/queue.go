@@ -0,0 +1,17 @@
+package secretmanager
+
+import (
+	"k8s.io/client-go/util/workqueue"
+)
+
+// NewQueue returns a rate limiting queue for the manager, named name in its metrics, such as the
+// workqueue_depth gauge and the workqueue_adds_total counter, which help telling whether reconciles
+// are backing up during secret event storms.
+// The metrics are emitted through metricsProvider, or through the global workqueue metrics provider if nil,
+// which emits no metrics unless one is registered, e.g. by importing k8s.io/component-base/metrics/prometheus/workqueue.
+func NewQueue(name string, metricsProvider workqueue.MetricsProvider) workqueue.RateLimitingInterface {
+	return workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{
+		Name:            name,
+		MetricsProvider: metricsProvider,
+	})
+}
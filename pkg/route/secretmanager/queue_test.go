@@ -0,0 +1,84 @@
+package secretmanager
+
+import (
+	"sync"
+	"testing"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+// fakeMetric records the value of a gauge or counter.
+type fakeMetric struct {
+	lock  sync.Mutex
+	value float64
+}
+
+func (m *fakeMetric) Inc()              { m.add(1) }
+func (m *fakeMetric) Dec()              { m.add(-1) }
+func (m *fakeMetric) Set(value float64) { m.lock.Lock(); m.value = value; m.lock.Unlock() }
+func (m *fakeMetric) Observe(float64)   {}
+
+func (m *fakeMetric) add(delta float64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.value += delta
+}
+
+func (m *fakeMetric) get() float64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.value
+}
+
+// fakeMetricsProvider records the depth and adds metrics of the queues.
+type fakeMetricsProvider struct {
+	names []string
+	depth fakeMetric
+	adds  fakeMetric
+}
+
+func (p *fakeMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	p.names = append(p.names, name)
+	return &p.depth
+}
+func (p *fakeMetricsProvider) NewAddsMetric(string) workqueue.CounterMetric { return &p.adds }
+func (p *fakeMetricsProvider) NewLatencyMetric(string) workqueue.HistogramMetric {
+	return &fakeMetric{}
+}
+func (p *fakeMetricsProvider) NewWorkDurationMetric(string) workqueue.HistogramMetric {
+	return &fakeMetric{}
+}
+func (p *fakeMetricsProvider) NewUnfinishedWorkSecondsMetric(string) workqueue.SettableGaugeMetric {
+	return &fakeMetric{}
+}
+func (p *fakeMetricsProvider) NewLongestRunningProcessorSecondsMetric(string) workqueue.SettableGaugeMetric {
+	return &fakeMetric{}
+}
+func (p *fakeMetricsProvider) NewRetriesMetric(string) workqueue.CounterMetric { return &fakeMetric{} }
+
+func TestNewQueueMetrics(t *testing.T) {
+	provider := &fakeMetricsProvider{}
+	queue := NewQueue("route_secrets", provider)
+	defer queue.ShutDown()
+
+	if len(provider.names) != 1 || provider.names[0] != "route_secrets" {
+		t.Fatalf("expected the depth metric of queue route_secrets, got %v", provider.names)
+	}
+
+	for _, key := range []string{"ns/route1", "ns/route2", "ns/route3", "ns/route1"} {
+		queue.Add(key)
+	}
+	// duplicate keys are not queued twice
+	if depth := provider.depth.get(); depth != 3 {
+		t.Errorf("expected depth 3, got %v", depth)
+	}
+	if adds := provider.adds.get(); adds != 3 {
+		t.Errorf("expected 3 adds, got %v", adds)
+	}
+
+	item, _ := queue.Get()
+	queue.Done(item)
+	if depth := provider.depth.get(); depth != 2 {
+		t.Errorf("expected depth 2 after processing an item, got %v", depth)
+	}
+}
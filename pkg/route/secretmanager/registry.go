@@ -0,0 +1,70 @@
+package secretmanager
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrRouteOwnedByAnotherManager is returned when registering a route already registered by another manager
+// sharing the same RouteRegistry.
+var ErrRouteOwnedByAnotherManager = errors.New("route is registered by another manager")
+
+// RouteRegistry records which manager owns each route key, so that managers sharing it in the same process
+// don't both watch the secret of a route. It is opt-in, see WithRouteRegistry.
+type RouteRegistry struct {
+	lock   sync.Mutex
+	owners map[string]*manager
+}
+
+// NewRouteRegistry returns an empty RouteRegistry.
+func NewRouteRegistry() *RouteRegistry {
+	return &RouteRegistry{owners: map[string]*manager{}}
+}
+
+// WithRouteRegistry makes the manager claim the routes it registers in the shared registry.
+// Registering a route claimed by another manager fails with ErrRouteOwnedByAnotherManager.
+func WithRouteRegistry(registry *RouteRegistry) Option {
+	return func(m *manager) {
+		m.routeRegistry = registry
+	}
+}
+
+// claim records the manager as the owner of the route key. Returns true if the key was not owned by the
+// manager yet, and an error wrapping ErrRouteOwnedByAnotherManager if it is owned by another manager.
+func (r *RouteRegistry) claim(key string, owner *manager) (bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	current, exists := r.owners[key]
+	if exists && current != owner {
+		return false, fmt.Errorf("%w: route key %s", ErrRouteOwnedByAnotherManager, key)
+	}
+	r.owners[key] = owner
+	return !exists, nil
+}
+
+// release removes the manager as the owner of the route key, unless it is owned by another manager.
+func (r *RouteRegistry) release(key string, owner *manager) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.owners[key] == owner {
+		delete(r.owners, key)
+	}
+}
+
+// claimRoute claims the route key in the registry of the manager, if any. See RouteRegistry.claim.
+func (m *manager) claimRoute(key string) (bool, error) {
+	if m.routeRegistry == nil {
+		return false, nil
+	}
+	return m.routeRegistry.claim(key, m)
+}
+
+// releaseRoute releases the route key in the registry of the manager, if any.
+func (m *manager) releaseRoute(key string) {
+	if m.routeRegistry != nil {
+		m.routeRegistry.release(key, m)
+	}
+}
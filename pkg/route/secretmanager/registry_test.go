@@ -0,0 +1,67 @@
+package secretmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/secret/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestRouteRegistry(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	registry := NewRouteRegistry()
+	first := newManager(&fake.SecretMonitor{}, nil, WithRouteRegistry(registry))
+	second := newManager(&fake.SecretMonitor{}, nil, WithRouteRegistry(registry))
+
+	if err := first.RegisterRoute(context.TODO(), namespace, routeName, "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.RegisterRoute(context.TODO(), namespace, routeName, "secret", cache.ResourceEventHandlerFuncs{}); !errors.Is(err, ErrRouteOwnedByAnotherManager) {
+		t.Fatalf("expected %v, got %v", ErrRouteOwnedByAnotherManager, err)
+	}
+	if second.IsRouteRegistered(namespace, routeName) {
+		t.Error("expected the route not to be registered by the second manager")
+	}
+
+	// the owner can re-register its route
+	if err := first.UpdateRoute(context.TODO(), namespace, routeName, "other", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("unexpected error updating the route: %v", err)
+	}
+
+	// other routes can be registered by the second manager
+	if err := second.RegisterRoute(context.TODO(), namespace, "other", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the route can be registered by the second manager once unregistered
+	if err := first.UnregisterRoute(namespace, routeName); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.RegisterRoute(context.TODO(), namespace, routeName, "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("unexpected error after the route was released: %v", err)
+	}
+
+	// closing a manager releases its routes
+	if err := second.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.RegisterRoute(context.TODO(), namespace, "other", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("unexpected error after the second manager was closed: %v", err)
+	}
+}
+
+func TestRouteRegistryFailedRegistration(t *testing.T) {
+	registry := NewRouteRegistry()
+	failing := newManager(&fake.SecretMonitor{Err: errors.New("failed")}, nil, WithRouteRegistry(registry))
+	other := newManager(&fake.SecretMonitor{}, nil, WithRouteRegistry(registry))
+
+	if err := failing.RegisterRoute(context.TODO(), "ns", "route", "secret", cache.ResourceEventHandlerFuncs{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	// a failed registration doesn't keep the route claimed
+	if err := other.RegisterRoute(context.TODO(), "ns", "route", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package secretmanager
+
+import (
+	"fmt"
+
+	"github.com/openshift/library-go/pkg/secret"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+// ForceResync recreates the informers of the secret and the fallback secrets of the route, preserving their
+// handlers, e.g. if their cache is suspected to be stale despite having synced. The secrets are listed again,
+// and the caches have synced once ForceResync returns. It is a targeted version of the ReregisterAll method of
+// the monitor. The informers are shared with the other routes referencing the same secrets.
+func (m *manager) ForceResync(namespace, routeName string) error {
+	m.handlersLock.RLock()
+	key := generateKey(namespace, routeName)
+	handlerRegistration, exists := m.registeredHandlers[key]
+	if !exists {
+		m.handlersLock.RUnlock()
+		return fmt.Errorf("no handler registered with key %s", key)
+	}
+	secretKeys := []secret.ObjectKey{handlerRegistration.GetKey()}
+	for _, fallback := range m.fallbackHandlers[key] {
+		secretKeys = append(secretKeys, fallback.GetKey())
+	}
+	m.handlersLock.RUnlock()
+
+	var errs []error
+	for _, secretKey := range secretKeys {
+		if err := m.monitor.Reregister(secretKey); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to resync secrets of route key %s: %w", key, utilerrors.NewAggregate(errs))
+	}
+
+	klog.Infof("secret manager resynced secrets %v of route key %s", secretKeys, key)
+	return nil
+}
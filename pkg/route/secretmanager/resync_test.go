@@ -0,0 +1,60 @@
+package secretmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestForceResync(t *testing.T) {
+	namespace := "ns"
+	kubeClient := kubefake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "secret"},
+		Data:       map[string][]byte{"tls.crt": []byte("old")},
+	})
+	// watches never deliver any event, as if they were stuck
+	kubeClient.PrependWatchReactor("secrets", func(_ clienttesting.Action) (bool, watch.Interface, error) {
+		return true, watch.NewFake(), nil
+	})
+	mgr := newManager(secret.NewSecretMonitor(kubeClient), nil)
+	if err := mgr.RegisterRoute(context.TODO(), namespace, "route", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "secret"},
+		Data:       map[string][]byte{"tls.crt": []byte("new")},
+	}
+	if _, err := kubeClient.CoreV1().Secrets(namespace).Update(context.TODO(), updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	gotSecret, err := mgr.GetSecret(context.TODO(), namespace, "route")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(gotSecret.Data["tls.crt"]) != "old" {
+		t.Fatalf("expected the stuck watch to miss the update, got %s", gotSecret.Data["tls.crt"])
+	}
+
+	if err := mgr.ForceResync(namespace, "route"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gotSecret, err = mgr.GetSecret(context.TODO(), namespace, "route")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(gotSecret.Data["tls.crt"]) != "new" {
+		t.Errorf("expected the resynced cache to hold the updated secret, got %s", gotSecret.Data["tls.crt"])
+	}
+
+	if err := mgr.ForceResync(namespace, "unregistered"); err == nil {
+		t.Error("expected error for an unregistered route")
+	}
+}
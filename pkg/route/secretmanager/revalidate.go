@@ -0,0 +1,141 @@
+package secretmanager
+
+import (
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// WithResyncPeriod makes the manager redeliver the cached secrets of the registered routes to their handlers every
+// period according to the clock of the manager, until Close, so that the secrets are revalidated even absent changes,
+// see WithOnCertificateExpired. Resyncs are delivered as updates which don't change the certificate material, so they
+// don't enqueue the routes registered by EnsureAndGet.
+func WithResyncPeriod(period time.Duration) Option {
+	return func(m *manager) {
+		m.resyncPeriod = period
+	}
+}
+
+// WithOnCertificateExpired validates the secret of every add and update event of the registered routes,
+// including the periodic resyncs, see WithResyncPeriod, before delivering the event to the handler of the
+// route. onExpired is called once the certificate of a secret is found expired according to the clock of the
+// manager, and again only after the secret has held a certificate which is not expired. Secrets which are
+// not of type kubernetes.io/tls, or don't hold a valid key pair or CA bundle, are logged and not checked.
+func WithOnCertificateExpired(onExpired ExpiringFunc) Option {
+	return func(m *manager) {
+		m.onCertificateExpired = onExpired
+	}
+}
+
+// withRevalidation wraps the handler of the route to revalidate the secrets of its events,
+// see WithOnCertificateExpired. Resyncs are updates, so they go through the same validation.
+func (m *manager) withRevalidation(namespace, routeName string, handler cache.ResourceEventHandler) cache.ResourceEventHandler {
+	expired := map[secret.ObjectKey]bool{}
+	var lock sync.Mutex
+
+	revalidate := func(obj interface{}) {
+		s := toSecret(obj)
+		if s == nil {
+			return
+		}
+		key := generateKey(namespace, routeName)
+		notAfter, err := m.certificateNotAfter(s)
+		if err != nil {
+			klog.V(4).Infof("secret manager failed to revalidate secret of route key %s: %v", key, err)
+			return
+		}
+
+		secretKey := secret.NewObjectKey(s.Namespace, s.Name)
+		isExpired := !m.clock.Now().Before(notAfter)
+		lock.Lock()
+		wasExpired := expired[secretKey]
+		expired[secretKey] = isExpired
+		lock.Unlock()
+		if isExpired && !wasExpired {
+			klog.Warningf("secret manager found expired certificate of secret %s/%s of route key %s, expired at %v", s.Namespace, s.Name, key, notAfter)
+			m.onCertificateExpired(namespace, routeName, notAfter)
+		}
+	}
+	return cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			revalidate(obj)
+			handler.OnAdd(obj, isInInitialList)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			revalidate(newObj)
+			handler.OnUpdate(oldObj, newObj)
+		},
+		DeleteFunc: handler.OnDelete,
+	}
+}
+
+// certificateNotAfter validates the secret like DegradedRoutes, and returns the NotAfter time of its leaf certificate.
+func (m *manager) certificateNotAfter(obj *v1.Secret) (time.Time, error) {
+	if err := validateSecretType(obj); err != nil {
+		return time.Time{}, err
+	}
+	keyPair, err := m.keyPairFromSecret(obj)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := validateSecretCABundle(obj); err != nil {
+		return time.Time{}, err
+	}
+	leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse certificate of secret %s/%s: %w", obj.Namespace, obj.Name, err)
+	}
+	return leaf.NotAfter, nil
+}
+
+// runResync resyncs the registered routes every resync period, until stopCh is closed.
+func (m *manager) runResync(stopCh <-chan struct{}) {
+	for {
+		timer := m.clock.NewTimer(m.resyncPeriod)
+		select {
+		case <-stopCh:
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+		m.resync()
+	}
+}
+
+// resync delivers the cached secrets of the registered routes, including their fallback secrets, to the handlers of
+// the routes as updates. Secrets which can't be read from the cache are skipped.
+func (m *manager) resync() {
+	type resyncEvent struct {
+		handler cache.ResourceEventHandler
+		secret  *v1.Secret
+	}
+	var events []resyncEvent
+
+	m.handlersLock.RLock()
+	for key, handlerRegistration := range m.registeredHandlers {
+		pauser, exists := m.pausers[key]
+		if !exists {
+			continue
+		}
+		for _, registration := range append([]secret.SecretEventHandlerRegistration{handlerRegistration}, m.fallbackHandlers[key]...) {
+			s, err := registration.GetSecret()
+			if err != nil {
+				klog.V(5).Infof("secret manager skipped resync of secret %v of route key %s: %v", registration.GetKey(), key, err)
+				continue
+			}
+			events = append(events, resyncEvent{handler: pauser, secret: s})
+		}
+	}
+	m.handlersLock.RUnlock()
+
+	// the handlers may call the manager
+	for _, event := range events {
+		event.handler.OnUpdate(event.secret, event.secret)
+	}
+}
@@ -0,0 +1,86 @@
+package secretmanager
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	"github.com/openshift/library-go/pkg/secret/secrettesting"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestResyncRevalidation(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	obj, err := secrettesting.NewTLSSecret(namespace, "secret", secrettesting.WithExpiresIn(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kubeClient := kubefake.NewSimpleClientset(obj)
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+
+	var expired, updates atomic.Int32
+	onExpired := func(ns, name string, notAfter time.Time) {
+		if ns != namespace || name != routeName {
+			t.Errorf("expected expiry of route %s/%s, got %s/%s", namespace, routeName, ns, name)
+		}
+		expired.Add(1)
+	}
+	monitor := secret.NewSecretMonitor(kubeClient)
+	mgr := newManager(monitor, nil, WithClock(fakeClock), WithResyncPeriod(time.Minute), WithOnCertificateExpired(onExpired))
+	handler := cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, _ interface{}) { updates.Add(1) },
+	}
+	if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "secret", handler); err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Close()
+
+	// resync steps the clock once the next resync is scheduled, and waits for the resync to be delivered
+	resync := func(d time.Duration) {
+		t.Helper()
+		resyncs := updates.Load()
+		if err := wait.PollUntilContextTimeout(context.TODO(), time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+			return fakeClock.HasWaiters(), nil
+		}); err != nil {
+			t.Fatalf("timed out waiting for the next resync to be scheduled: %v", err)
+		}
+		fakeClock.Step(d)
+		if err := wait.PollUntilContextTimeout(context.TODO(), time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+			return updates.Load() > resyncs, nil
+		}); err != nil {
+			t.Fatalf("expected the resync to be delivered to the handler: %v", err)
+		}
+	}
+
+	// resyncs of the valid certificate don't fire the callback
+	resync(time.Minute)
+	resync(time.Minute)
+	if got := expired.Load(); got != 0 {
+		t.Fatalf("expected no expiry of the valid certificate, got %d", got)
+	}
+
+	// the next resync past the expiry fires the callback, once
+	resync(2 * time.Hour)
+	if got := expired.Load(); got != 1 {
+		t.Fatalf("expected the expiry callback to fire on resync, got %d", got)
+	}
+	resync(time.Minute)
+	if got := expired.Load(); got != 1 {
+		t.Errorf("expected the expiry callback to fire once, got %d", got)
+	}
+
+	// no resync is scheduled once the manager is closed
+	if err := mgr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wait.PollUntilContextTimeout(context.TODO(), time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return !fakeClock.HasWaiters(), nil
+	}); err != nil {
+		t.Errorf("expected the resyncs to stop: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package secretmanager
+
+import (
+	"context"
+	"fmt"
+
+	routev1 "github.com/openshift/api/route/v1"
+	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// SecretReference references a secret in the namespace of a route.
+type SecretReference struct {
+	Name string
+}
+
+// SecretRefExtractor returns the secrets referenced by the spec of a route, in order of preference.
+type SecretRefExtractor func(route *routev1.Route) []SecretReference
+
+// ExternalCertificateRefs is the default SecretRefExtractor, returning the secret of spec.tls.externalCertificate.
+func ExternalCertificateRefs(route *routev1.Route) []SecretReference {
+	if route.Spec.TLS == nil || route.Spec.TLS.ExternalCertificate == nil {
+		return nil
+	}
+	return []SecretReference{{Name: route.Spec.TLS.ExternalCertificate.Name}}
+}
+
+// WithSecretRefExtractor overrides how RegisterRouteFromSpec extracts the secrets referenced by a route,
+// e.g. to support TLS fields other than spec.tls.externalCertificate. Defaults to ExternalCertificateRefs.
+func WithSecretRefExtractor(extractor SecretRefExtractor) Option {
+	return func(m *manager) {
+		m.secretRefExtractor = extractor
+	}
+}
+
+// RegisterRouteFromSpec registers the route with the secrets extracted from its spec, see WithSecretRefExtractor.
+// The first secret is the primary secret of the route and the others its fallbacks, see RegisterRouteWithFallbacks.
+// References with an empty name, e.g. of an externalCertificate without name, are ignored, and routes which
+// don't reference any secret are not registered.
+func (m *manager) RegisterRouteFromSpec(ctx context.Context, route *routev1.Route, handler cache.ResourceEventHandlerFuncs) error {
+	var refs []SecretReference
+	for _, ref := range m.secretRefExtractor(route) {
+		if ref.Name == "" {
+			klog.V(4).Infof("secret manager ignoring secret reference without name of route key %s", generateKey(route.Namespace, route.Name))
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	if len(refs) == 0 {
+		klog.V(4).Infof("secret manager not registering route key %s without secret reference", generateKey(route.Namespace, route.Name))
+		return nil
+	}
+	if len(refs) == 1 {
+		return m.RegisterRoute(ctx, route.Namespace, route.Name, refs[0].Name, handler)
+	}
+
+	secretNames := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		secretNames = append(secretNames, ref.Name)
+	}
+	return m.RegisterRouteWithFallbacks(ctx, route.Namespace, route.Name, secretNames, handler)
+}
+
+// RegisterFromRouteLister registers every route of the lister matching the selector with the secrets extracted
+// from its spec, see RegisterRouteFromSpec, e.g. to bootstrap the manager of a controller at startup.
+// Routes without secret reference are skipped. The routes are registered with a handler adding the route key
+// to the manager's queue, like EnsureAndGet. A route failing to register doesn't prevent the others from being
+// registered: the returned error aggregates the error of every failing route.
+func (m *manager) RegisterFromRouteLister(ctx context.Context, lister routev1listers.RouteLister, selector labels.Selector) error {
+	routes, err := lister.List(selector)
+	if err != nil {
+		return fmt.Errorf("failed to list routes: %w", err)
+	}
+
+	var errs []error
+	for _, route := range routes {
+		key := generateKey(route.Namespace, route.Name)
+		if err := m.RegisterRouteFromSpec(ctx, route, m.enqueueHandler(key)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to register route key %s: %w", key, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,150 @@
+package secretmanager
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
+	"github.com/openshift/library-go/pkg/secret"
+	"github.com/openshift/library-go/pkg/secret/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestRegisterRouteFromSpec(t *testing.T) {
+	namespace := "ns"
+	route := func(name string, tls *routev1.TLSConfig) *routev1.Route {
+		return &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       routev1.RouteSpec{TLS: tls},
+		}
+	}
+	multipleRefs := func(route *routev1.Route) []SecretReference {
+		refs := ExternalCertificateRefs(route)
+		if secretName, exists := route.Annotations["example.com/fallback-secret"]; exists {
+			refs = append(refs, SecretReference{Name: secretName})
+		}
+		return refs
+	}
+
+	scenarios := []struct {
+		name             string
+		extractor        SecretRefExtractor
+		route            *routev1.Route
+		expectRegistered bool
+		expectRefs       []RouteRef
+	}{
+		{
+			name:             "external certificate",
+			route:            route("route", &routev1.TLSConfig{ExternalCertificate: &routev1.LocalObjectReference{Name: "secret1"}}),
+			expectRegistered: true,
+			expectRefs:       []RouteRef{{Namespace: namespace, RouteName: "route", SecretName: "secret1"}},
+		},
+		{
+			name:  "without tls",
+			route: route("route", nil),
+		},
+		{
+			name:  "without external certificate",
+			route: route("route", &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge}),
+		},
+		{
+			name:  "external certificate without name",
+			route: route("route", &routev1.TLSConfig{ExternalCertificate: &routev1.LocalObjectReference{}}),
+		},
+		{
+			name:      "custom extractor with a reference without name",
+			extractor: multipleRefs,
+			route: func() *routev1.Route {
+				r := route("route", &routev1.TLSConfig{ExternalCertificate: &routev1.LocalObjectReference{Name: "secret1"}})
+				r.Annotations = map[string]string{"example.com/fallback-secret": ""}
+				return r
+			}(),
+			expectRegistered: true,
+			expectRefs:       []RouteRef{{Namespace: namespace, RouteName: "route", SecretName: "secret1"}},
+		},
+		{
+			name:      "custom extractor with multiple references",
+			extractor: multipleRefs,
+			route: func() *routev1.Route {
+				r := route("route", &routev1.TLSConfig{ExternalCertificate: &routev1.LocalObjectReference{Name: "secret1"}})
+				r.Annotations = map[string]string{"example.com/fallback-secret": "secret2"}
+				return r
+			}(),
+			expectRegistered: true,
+			expectRefs:       []RouteRef{{Namespace: namespace, RouteName: "route", SecretName: "secret1", FallbackSecretNames: []string{"secret2"}}},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			kubeClient := kubefake.NewSimpleClientset(
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "secret1"}},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "secret2"}},
+			)
+			var opts []Option
+			if s.extractor != nil {
+				opts = append(opts, WithSecretRefExtractor(s.extractor))
+			}
+			mgr := newManager(secret.NewSecretMonitor(kubeClient), nil, opts...)
+
+			if err := mgr.RegisterRouteFromSpec(context.TODO(), s.route, cache.ResourceEventHandlerFuncs{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if registered := mgr.IsRouteRegistered(namespace, s.route.Name); registered != s.expectRegistered {
+				t.Fatalf("expected registered %t, got %t", s.expectRegistered, registered)
+			}
+			if !s.expectRegistered && len(kubeClient.Actions()) > 0 {
+				t.Errorf("expected no secret to be watched, got actions %v", kubeClient.Actions())
+			}
+			if refs := mgr.ExportRegistrations(); len(s.expectRefs) > 0 && !reflect.DeepEqual(refs, s.expectRefs) {
+				t.Errorf("expected %v, got %v", s.expectRefs, refs)
+			}
+			for _, ref := range s.expectRefs {
+				for _, secretName := range append([]string{ref.SecretName}, ref.FallbackSecretNames...) {
+					if !mgr.IsSecretWatched(namespace, secretName) {
+						t.Errorf("expected secret %s to be watched", secretName)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterFromRouteLister(t *testing.T) {
+	route := func(name, app, secretName string) *routev1.Route {
+		r := &routev1.Route{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, Labels: map[string]string{"app": app}}}
+		if secretName != "" {
+			r.Spec.TLS = &routev1.TLSConfig{ExternalCertificate: &routev1.LocalObjectReference{Name: secretName}}
+		}
+		return r
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, r := range []*routev1.Route{
+		route("with-cert", "selected", "secret"),
+		route("without-cert", "selected", ""),
+		route("invalid-cert", "selected", "Invalid_Name"),
+		route("not-selected", "other", "secret"),
+	} {
+		if err := indexer.Add(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mgr := newManager(&fake.SecretMonitor{}, nil)
+
+	err := mgr.RegisterFromRouteLister(context.TODO(), routev1listers.NewRouteLister(indexer), labels.SelectorFromSet(labels.Set{"app": "selected"}))
+	if err == nil || !strings.Contains(err.Error(), "ns/invalid-cert") {
+		t.Errorf("expected an error for the route with an invalid secret, got %v", err)
+	}
+	for routeName, registered := range map[string]bool{"with-cert": true, "without-cert": false, "invalid-cert": false, "not-selected": false} {
+		if got := mgr.IsRouteRegistered("ns", routeName); got != registered {
+			t.Errorf("expected route %s registered to be %v, got %v", routeName, registered, got)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package secretmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/openshift/library-go/pkg/secret"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+// ErrManagerInactive is returned when reading the secret of a route whose registration is deferred
+// while the manager is inactive, see SetActive.
+var ErrManagerInactive = errors.New("secret manager is inactive")
+
+// deferredRoute is the binding of a route whose informers are not created while the manager is inactive.
+type deferredRoute struct {
+	namespace string
+	routeName string
+	// secretNames are the primary secret of the route followed by its fallbacks.
+	secretNames []string
+}
+
+// SetActive activates or deactivates the manager, e.g. when a controller gains or loses the leader election,
+// so that a controller on standby doesn't run watches. The manager is active by default.
+//
+// While inactive, registering a route records its binding but defers the creation of the informers of its
+// secrets, and reading its secret fails with ErrManagerInactive. Deactivating the manager stops the informers
+// of the registered routes but retains their bindings, handlers and pause state. Activating it adds the
+// handlers of every deferred route again, which creates the informers of their secrets. Routes failing to be
+// activated stay deferred until SetActive(true) is called again, and their errors are aggregated.
+// The handlers of Subscribe are not removed and keep the informers of their secrets running.
+func (m *manager) SetActive(active bool) error {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+
+	if !active {
+		if m.inactive {
+			return nil
+		}
+		m.inactive = true
+		klog.Info("secret manager deactivated")
+		return m.deactivate()
+	}
+	if m.inactive {
+		m.inactive = false
+		klog.Info("secret manager activated")
+	}
+	return m.activate()
+}
+
+// activate adds the handlers of the deferred routes. The caller must hold handlersLock.
+func (m *manager) activate() error {
+	var errs []error
+	for key, deferred := range m.deferred {
+		if err := m.attachRoute(key, deferred); err != nil {
+			errs = append(errs, fmt.Errorf("failed to activate route key %s: %w", key, err))
+			continue
+		}
+		delete(m.deferred, key)
+		klog.Infof("secret manager activated route for key %s with secrets %v", key, deferred.secretNames)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// attachRoute adds the pausable handler of the route for the secrets of the binding, and records their
+// registrations. No registration is recorded on error. The caller must hold handlersLock.
+func (m *manager) attachRoute(key string, binding deferredRoute) error {
+	pauser := m.pausers[key]
+	handlerRegistration, err := m.monitor.AddSecretEventHandler(context.Background(), binding.namespace, binding.secretNames[0], pauser)
+	if err != nil {
+		return err
+	}
+	fallbacks, err := m.addFallbacks(context.Background(), binding.namespace, key, binding.secretNames[1:], pauser)
+	if err != nil {
+		if removeErr := m.monitor.RemoveSecretEventHandler(handlerRegistration); removeErr != nil {
+			klog.Errorf("secret manager failed to remove handler of route key %s after failing to attach its fallbacks: %v", key, removeErr)
+		}
+		return err
+	}
+
+	m.registeredHandlers[key] = handlerRegistration
+	m.indexRoute(key, handlerRegistration.GetKey())
+	if len(fallbacks) > 0 {
+		if m.fallbackHandlers == nil {
+			m.fallbackHandlers = make(map[string][]secret.SecretEventHandlerRegistration)
+		}
+		m.fallbackHandlers[key] = fallbacks
+	}
+	return nil
+}
+
+// deactivate removes the handlers of the registered routes and defers them. Errors removing the handlers
+// are aggregated, the routes are deferred anyway. The caller must hold handlersLock.
+func (m *manager) deactivate() error {
+	var errs []error
+	for key, handlerRegistration := range m.registeredHandlers {
+		deferred, err := m.detachHandlers(key, handlerRegistration)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to deactivate route key %s: %w", key, err))
+		}
+		m.deferRoute(key, deferred)
+		klog.V(4).Infof("secret manager deactivated route for key %s", key)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// detachHandlers removes the handlers of the registered route and their registrations, keeping its pausable
+// handler, and returns the binding of the route. The registrations are removed even if removing the handler
+// of the primary secret fails, in which case the error is returned. The caller must hold handlersLock.
+func (m *manager) detachHandlers(key string, handlerRegistration secret.SecretEventHandlerRegistration) (deferredRoute, error) {
+	binding := m.bindingOf(key, handlerRegistration)
+	err := m.monitor.RemoveSecretEventHandler(handlerRegistration)
+	m.dropRegistrations(key, handlerRegistration)
+	return binding, err
+}
+
+// bindingOf returns the binding of the registered route. The caller must hold handlersLock.
+func (m *manager) bindingOf(key string, handlerRegistration secret.SecretEventHandlerRegistration) deferredRoute {
+	secretKey := handlerRegistration.GetKey()
+	binding := deferredRoute{
+		namespace:   secretKey.Namespace,
+		routeName:   strings.TrimPrefix(key, secretKey.Namespace+"/"),
+		secretNames: []string{secretKey.Name},
+	}
+	for _, fallback := range m.fallbackHandlers[key] {
+		binding.secretNames = append(binding.secretNames, fallback.GetKey().Name)
+	}
+	return binding
+}
+
+// dropRegistrations removes the fallback handlers of the route, and the registrations of the route whose primary
+// handler was removed. The caller must hold handlersLock.
+func (m *manager) dropRegistrations(key string, handlerRegistration secret.SecretEventHandlerRegistration) {
+	m.removeFallbacks(key)
+	delete(m.registeredHandlers, key)
+	m.unindexRoute(key, handlerRegistration.GetKey())
+}
+
+// deferRoute records the binding of the route until the manager is activated. The caller must hold handlersLock.
+func (m *manager) deferRoute(key string, deferred deferredRoute) {
+	if m.deferred == nil {
+		m.deferred = make(map[string]deferredRoute)
+	}
+	m.deferred[key] = deferred
+}
+
+// deferFallbacks records the fallback secrets of a route deferred by registerRoute, after validating them.
+// The caller must hold handlersLock.
+func (m *manager) deferFallbacks(namespace, routeName string, secretNames []string) error {
+	key := generateKey(namespace, routeName)
+	for _, secretName := range secretNames[1:] {
+		if err := secret.NewObjectKey(namespace, secretName).Validate(); err != nil {
+			if unregisterErr := m.unregisterRoute(namespace, routeName); unregisterErr != nil {
+				klog.Errorf("secret manager failed to unregister route key %s after failing to defer its fallbacks: %v", key, unregisterErr)
+			}
+			return fmt.Errorf("invalid fallback secret %s for route key %s: %w", secretName, key, err)
+		}
+	}
+	m.deferRoute(key, deferredRoute{namespace: namespace, routeName: routeName, secretNames: secretNames})
+	return nil
+}
@@ -0,0 +1,115 @@
+package secretmanager
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestSetActiveRegisterWhileInactive(t *testing.T) {
+	namespace := "ns"
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "fallback", Namespace: namespace}},
+	)
+	monitor := secret.NewSecretMonitor(kubeClient)
+	mgr := newManager(monitor, nil)
+
+	if err := mgr.SetActive(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.RegisterRoute(context.TODO(), namespace, "route", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mgr.RegisterRouteWithFallbacks(context.TODO(), namespace, "with-fallback", []string{"secret", "fallback"}, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mgr.RegisterRoute(context.TODO(), namespace, "route", "secret", cache.ResourceEventHandlerFuncs{}); err == nil {
+		t.Error("expected an error registering a deferred route again")
+	}
+
+	if !mgr.IsRouteRegistered(namespace, "route") {
+		t.Error("expected the deferred route to be registered")
+	}
+	if keys := monitor.ListMonitoredKeys(); len(keys) != 0 {
+		t.Errorf("expected no informer while inactive, got %v", keys)
+	}
+	if _, err := mgr.GetSecret(context.TODO(), namespace, "route"); !errors.Is(err, ErrManagerInactive) {
+		t.Errorf("expected ErrManagerInactive, got %v", err)
+	}
+
+	if err := mgr.SetActive(true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedKeys := []secret.ObjectKey{secret.NewObjectKey(namespace, "fallback"), secret.NewObjectKey(namespace, "secret")}
+	if keys := monitor.ListMonitoredKeys(); !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("expected informers of %v once active, got %v", expectedKeys, keys)
+	}
+	if gotSecret, err := mgr.GetSecret(context.TODO(), namespace, "route"); err != nil || gotSecret.Name != "secret" {
+		t.Errorf("expected the route to resolve secret, got %v, %v", gotSecret, err)
+	}
+	if got := mgr.ExportRegistrations(); len(got) != 2 || !reflect.DeepEqual(got[1].FallbackSecretNames, []string{"fallback"}) {
+		t.Errorf("expected the fallbacks to be activated, got %v", got)
+	}
+}
+
+func TestSetActiveDeactivate(t *testing.T) {
+	namespace := "ns"
+	kubeClient := kubefake.NewSimpleClientset(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}})
+	monitor := secret.NewSecretMonitor(kubeClient)
+	mgr := newManager(monitor, nil)
+
+	if err := mgr.RegisterRoute(context.TODO(), namespace, "route", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.PauseRoute(namespace, "route"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mgr.SetActive(false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys := monitor.ListMonitoredKeys(); len(keys) != 0 {
+		t.Errorf("expected the informers to be stopped, got %v", keys)
+	}
+	if !mgr.IsRouteRegistered(namespace, "route") {
+		t.Error("expected the binding of the route to be kept")
+	}
+	if mgr.IsSecretWatched(namespace, "secret") {
+		t.Error("expected the secret not to be watched while inactive")
+	}
+
+	if err := mgr.SetActive(true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mgr.IsSecretWatched(namespace, "secret") {
+		t.Error("expected the secret to be watched again")
+	}
+	if !mgr.pausers[generateKey(namespace, "route")].isPaused() {
+		t.Error("expected the route to stay paused")
+	}
+
+	// deferred routes can be unregistered
+	if err := mgr.SetActive(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.UnregisterRoute(namespace, "route"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mgr.IsRouteRegistered(namespace, "route") {
+		t.Error("expected the route to be unregistered")
+	}
+	if err := mgr.SetActive(true); err != nil {
+		t.Fatal(err)
+	}
+	if keys := monitor.ListMonitoredKeys(); len(keys) != 0 {
+		t.Errorf("expected no informer for the unregistered route, got %v", keys)
+	}
+}
@@ -0,0 +1,99 @@
+package secretmanager
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/openshift/library-go/pkg/secret"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ManagerStats describes how many routes and secrets are watched by the manager.
+type ManagerStats struct {
+	// Routes is the number of registered routes.
+	Routes int
+	// Secrets is the number of distinct secrets referenced by the registered routes,
+	// which is the number of informers run on behalf of the manager.
+	Secrets int
+	// Handlers is the number of secret event handlers of the monitors of these secrets, as reported by the
+	// monitor. It includes the handlers of the fallback secrets and of Subscribe, and the handlers added to
+	// the same monitor by other users, so it can be higher than Routes.
+	Handlers int
+}
+
+// Stats returns the number of registered routes, of the secrets they reference and of their handlers.
+// Routes sharing a secret share its informer, so Secrets can be lower than Routes.
+func (m *manager) Stats() ManagerStats {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+
+	stats := ManagerStats{
+		Routes:  len(m.registeredHandlers),
+		Secrets: len(m.secretRoutes),
+	}
+	for secretKey := range m.secretRoutes {
+		stats.Handlers += len(m.monitor.ListHandlers(secretKey))
+	}
+	return stats
+}
+
+// HandlerInfo describes the secret event handler registered for a route, for debugging.
+type HandlerInfo struct {
+	// Key is the key of the secret monitored by the handler.
+	Key secret.ObjectKey
+	// RouteIndex is the position of the route among the routes of the manager registered with the secret,
+	// sorted by route key. It is computed by the manager and changes as routes are registered and unregistered.
+	RouteIndex int
+	// NumHandlers is the number of handlers of the monitor of the secret, including the handlers of other
+	// routes and the handlers not added by the manager, see secret.SecretMonitor.ListHandlers.
+	NumHandlers int
+	// Certificate is the name of the cert-manager Certificate the route was registered from, if any,
+	// see RegisterFromCertManagerCertificate.
+	Certificate string
+}
+
+// HandlerInfo returns the handler registered for a route. Routes sharing a secret have distinct
+// handlers on the same monitor, so their HandlerInfo have the same Key and NumHandlers but distinct route indices.
+func (m *manager) HandlerInfo(namespace, routeName string) (HandlerInfo, error) {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+
+	key := generateKey(namespace, routeName)
+	handlerRegistration, exists := m.registeredHandlers[key]
+	if !exists {
+		return HandlerInfo{}, fmt.Errorf("no handler registered with key %s", key)
+	}
+
+	secretKey := handlerRegistration.GetKey()
+	routes := sets.List(m.secretRoutes[secretKey])
+	return HandlerInfo{
+		Key:         secretKey,
+		RouteIndex:  sort.SearchStrings(routes, key),
+		NumHandlers: len(m.monitor.ListHandlers(secretKey)),
+		Certificate: m.certificates[key],
+	}, nil
+}
+
+// secretOverheadBytes is the approximate size of a cached secret besides its data, e.g. of its metadata.
+const secretOverheadBytes = 1024
+
+// EstimatedCacheBytes returns a rough estimate of the memory used by the cached secrets of the registered routes,
+// e.g. for capacity dashboards: the size of the data keys and values of every distinct secret, plus a fixed
+// overhead per secret. The data is not copied. Secrets which are not cached, e.g. not synced yet, are not counted.
+func (m *manager) EstimatedCacheBytes() int64 {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+
+	var size int64
+	for secretKey := range m.secretRoutes {
+		obj, err := m.monitor.GetSecretByKey(secretKey)
+		if err != nil || obj == nil {
+			continue
+		}
+		size += secretOverheadBytes
+		for key, value := range obj.Data {
+			size += int64(len(key) + len(value))
+		}
+	}
+	return size
+}
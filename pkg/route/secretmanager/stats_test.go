@@ -0,0 +1,123 @@
+package secretmanager
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestStats(t *testing.T) {
+	mgr := newManager(secret.NewSecretMonitor(kubefake.NewSimpleClientset()), nil)
+
+	for _, rs := range []struct{ namespace, routeName, secretName string }{
+		{"ns1", "route1", "shared"},
+		{"ns1", "route2", "shared"},
+		{"ns1", "route3", "secret"},
+		// same secret name, different namespace
+		{"ns2", "route1", "shared"},
+	} {
+		if err := mgr.RegisterRoute(context.TODO(), rs.namespace, rs.routeName, rs.secretName, cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := ManagerStats{Routes: 4, Secrets: 3, Handlers: 4}
+	if got := mgr.Stats(); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+
+	// subscriptions add handlers to the monitor of the secret
+	_, cancel, err := mgr.Subscribe("ns1", "route1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = ManagerStats{Routes: 4, Secrets: 3, Handlers: 5}
+	if got := mgr.Stats(); got != expected {
+		t.Errorf("expected %+v with a subscription, got %+v", expected, got)
+	}
+	cancel()
+
+	if err := mgr.UnregisterRoute("ns1", "route3"); err != nil {
+		t.Fatal(err)
+	}
+	expected = ManagerStats{Routes: 3, Secrets: 2, Handlers: 3}
+	if got := mgr.Stats(); got != expected {
+		t.Errorf("expected %+v after unregistering, got %+v", expected, got)
+	}
+}
+
+func TestHandlerInfo(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "ns"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"}},
+	)
+	monitor := secret.NewSecretMonitor(kubeClient)
+	mgr := newManager(monitor, nil)
+
+	for _, routeName := range []string{"route2", "route1"} {
+		if err := mgr.RegisterRoute(context.TODO(), "ns", routeName, "shared", cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mgr.RegisterRoute(context.TODO(), "ns", "route3", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// handlers added to the monitor by others are counted as well
+	if _, err := monitor.AddSecretEventHandler(context.TODO(), "ns", "shared", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+
+	sharedKey := secret.NewObjectKey("ns", "shared")
+	for routeName, expected := range map[string]HandlerInfo{
+		"route1": {Key: sharedKey, RouteIndex: 0, NumHandlers: 3},
+		"route2": {Key: sharedKey, RouteIndex: 1, NumHandlers: 3},
+		"route3": {Key: secret.NewObjectKey("ns", "secret"), RouteIndex: 0, NumHandlers: 1},
+	} {
+		got, err := mgr.HandlerInfo("ns", routeName)
+		if err != nil {
+			t.Fatalf("unexpected error for route %s: %v", routeName, err)
+		}
+		if got != expected {
+			t.Errorf("expected %+v for route %s, got %+v", expected, routeName, got)
+		}
+	}
+
+	if _, err := mgr.HandlerInfo("ns", "unregistered"); err == nil {
+		t.Error("expected an error for an unregistered route")
+	}
+}
+
+func TestEstimatedCacheBytes(t *testing.T) {
+	// 7 + 1000 and 7 + 3000 bytes of data
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "small"}, Data: map[string][]byte{"tls.crt": []byte(strings.Repeat("a", 1000))}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "large"}, Data: map[string][]byte{"tls.crt": []byte(strings.Repeat("a", 3000))}},
+	)
+	mgr := newManager(secret.NewSecretMonitor(kubeClient), nil)
+
+	if got := mgr.EstimatedCacheBytes(); got != 0 {
+		t.Errorf("expected no cached bytes without routes, got %d", got)
+	}
+	// the shared secret is only counted once
+	for _, rs := range []routeSecret{{"route1", "small"}, {"route2", "small"}, {"route3", "large"}} {
+		if err := mgr.RegisterRoute(context.TODO(), "ns", rs.routeName, rs.secretName, cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := mgr.GetSecretBlocking(context.TODO(), "ns", rs.routeName, 5*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dataBytes := int64(7 + 1000 + 7 + 3000)
+	if got := mgr.EstimatedCacheBytes(); got < dataBytes || got > dataBytes+2*4096 {
+		t.Errorf("expected an estimate between %d and %d bytes, got %d", dataBytes, dataBytes+2*4096, got)
+	}
+}
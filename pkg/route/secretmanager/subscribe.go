@@ -0,0 +1,164 @@
+package secretmanager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// EventType is the type of a SecretEvent.
+type EventType string
+
+const (
+	SecretAdded   EventType = "Added"
+	SecretUpdated EventType = "Updated"
+	SecretDeleted EventType = "Deleted"
+)
+
+// SecretEvent is a change of the secret of a route, delivered by Subscribe and NewSecretEventHandler.
+type SecretEvent struct {
+	Type EventType
+	// Old is the secret before an update, or the last known state of a deleted secret. It is nil for adds.
+	Old *v1.Secret
+	// New is the secret after an add or an update. It is nil for deletes.
+	New *v1.Secret
+}
+
+// NewSecretEventHandler returns a handler translating the events of a secret into SecretEvents delivered to
+// handle. The final state of secrets whose deletion was missed, see cache.DeletedFinalStateUnknown, is the
+// last known state of the secret in Old. Objects which are not secrets are delivered as nil secrets.
+func NewSecretEventHandler(handle func(event SecretEvent)) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			handle(SecretEvent{Type: SecretAdded, New: toSecret(obj)})
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			handle(SecretEvent{Type: SecretUpdated, Old: toSecret(oldObj), New: toSecret(newObj)})
+		},
+		DeleteFunc: func(obj interface{}) {
+			handle(SecretEvent{Type: SecretDeleted, Old: toSecret(obj)})
+		},
+	}
+}
+
+// toSecret returns the secret of the object, or of the tombstone, nil if it is not a secret.
+func toSecret(obj interface{}) *v1.Secret {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	secret, _ := obj.(*v1.Secret)
+	return secret
+}
+
+// SlowConsumerPolicy defines what happens to the events of a subscription whose buffer is full.
+type SlowConsumerPolicy int
+
+const (
+	// DropEvents drops the events delivered while the buffer is full, which is the default.
+	DropEvents SlowConsumerPolicy = iota
+	// BlockEvents blocks the delivery of the events of the secret to the subscription until the buffer has room.
+	// The other handlers of the secret are not blocked.
+	BlockEvents
+)
+
+// defaultSubscriptionBufferSize is the default size of the buffer of the subscription channels.
+const defaultSubscriptionBufferSize = 16
+
+// WithSubscriptionBuffer sets the size of the buffer of the channels returned by Subscribe, and the policy
+// applied when a buffer is full. Defaults to a buffer of 16 events, dropping the events once full.
+func WithSubscriptionBuffer(size int, policy SlowConsumerPolicy) Option {
+	return func(m *manager) {
+		m.subscriptionBufferSize = size
+		m.slowConsumerPolicy = policy
+	}
+}
+
+// subscription delivers the events of a secret to a channel until it is closed.
+type subscription struct {
+	lock   sync.Mutex
+	closed bool
+	events chan SecretEvent
+	// done is closed on unsubscribe, unblocking a pending delivery.
+	done   chan struct{}
+	policy SlowConsumerPolicy
+	// routeKey is the key of the route, used in logs.
+	routeKey string
+}
+
+// send delivers the event unless the subscription is closed, applying the policy if the buffer is full.
+func (s *subscription) send(event SecretEvent) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return
+	}
+	if s.policy == BlockEvents {
+		select {
+		case s.events <- event:
+		case <-s.done:
+		}
+		return
+	}
+	select {
+	case s.events <- event:
+	default:
+		klog.Warningf("secret manager dropped %s secret event of slow subscriber of route key %s", event.Type, s.routeKey)
+	}
+}
+
+// close closes the channel of the subscription, after unblocking a pending delivery.
+func (s *subscription) close() {
+	close(s.done)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.closed = true
+	close(s.events)
+}
+
+// Subscribe returns a channel receiving the events of the secret of the registered route, and a function
+// unsubscribing from them. Unsubscribing removes the handler of the subscription and closes the channel;
+// it must be called once the events are not needed anymore, including after the route is unregistered,
+// since the subscription keeps the secret monitored. See WithSubscriptionBuffer for slow consumers.
+func (m *manager) Subscribe(namespace, routeName string) (<-chan SecretEvent, func(), error) {
+	m.handlersLock.RLock()
+	key := generateKey(namespace, routeName)
+	handlerRegistration, exists := m.registeredHandlers[key]
+	m.handlersLock.RUnlock()
+	if !exists {
+		return nil, nil, fmt.Errorf("no handler registered with key %s", key)
+	}
+
+	size := m.subscriptionBufferSize
+	if size <= 0 {
+		size = defaultSubscriptionBufferSize
+	}
+	s := &subscription{
+		events:   make(chan SecretEvent, size),
+		done:     make(chan struct{}),
+		policy:   m.slowConsumerPolicy,
+		routeKey: key,
+	}
+	secretKey := handlerRegistration.GetKey()
+	registration, err := m.monitor.AddSecretEventHandler(context.Background(), secretKey.Namespace, secretKey.Name, NewSecretEventHandler(s.send))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to subscribe to secret of route key %s: %w", key, err)
+	}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			s.close()
+			if err := m.monitor.RemoveSecretEventHandler(registration); err != nil {
+				klog.Errorf("secret manager failed to remove subscription handler of route key %s: %v", key, err)
+			}
+		})
+	}
+	return s.events, unsubscribe, nil
+}
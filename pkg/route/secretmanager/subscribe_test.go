@@ -0,0 +1,195 @@
+package secretmanager
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func receiveEvent(t *testing.T, events <-chan SecretEvent) SecretEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("unexpected closed channel")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for secret event")
+	}
+	return SecretEvent{}
+}
+
+func TestSubscribe(t *testing.T) {
+	namespace := "ns"
+	kubeClient := kubefake.NewSimpleClientset()
+	monitor := secret.NewSecretMonitor(kubeClient)
+	mgr := newManager(monitor, nil)
+	if err := mgr.RegisterRoute(context.TODO(), namespace, "route", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := mgr.Subscribe(namespace, "unregistered"); err == nil {
+		t.Error("expected error subscribing to an unregistered route")
+	}
+
+	events, unsubscribe, err := mgr.Subscribe(namespace, "route")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secretKey := secret.NewObjectKey(namespace, "secret")
+	if handlers := monitor.ListHandlers(secretKey); len(handlers) != 2 {
+		t.Fatalf("expected the route and the subscription handlers, got %d", len(handlers))
+	}
+
+	s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "secret"}}
+	if _, err := kubeClient.CoreV1().Secrets(namespace).Create(context.TODO(), s, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	s.Labels = map[string]string{"updated": "true"}
+	if _, err := kubeClient.CoreV1().Secrets(namespace).Update(context.TODO(), s, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := kubeClient.CoreV1().Secrets(namespace).Delete(context.TODO(), s.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []EventType{SecretAdded, SecretUpdated, SecretDeleted} {
+		event := receiveEvent(t, events)
+		if event.Type != expected {
+			t.Fatalf("expected %s event, got %s", expected, event.Type)
+		}
+		secret := event.New
+		if expected == SecretDeleted {
+			secret = event.Old
+		}
+		if secret == nil || secret.Name != "secret" {
+			t.Errorf("expected %s event of secret, got %+v", expected, event)
+		}
+	}
+
+	unsubscribe()
+	unsubscribe()
+	if _, ok := <-events; ok {
+		t.Error("expected the channel to be closed")
+	}
+	if handlers := monitor.ListHandlers(secretKey); len(handlers) != 1 {
+		t.Errorf("expected only the route handler, got %d", len(handlers))
+	}
+}
+
+func TestSubscribeSlowConsumer(t *testing.T) {
+	namespace := "ns"
+	scenarios := []struct {
+		name   string
+		policy SlowConsumerPolicy
+	}{
+		{name: "drop", policy: DropEvents},
+		{name: "block", policy: BlockEvents},
+	}
+
+	for _, sc := range scenarios {
+		t.Run(sc.name, func(t *testing.T) {
+			kubeClient := kubefake.NewSimpleClientset()
+			mgr := newManager(secret.NewSecretMonitor(kubeClient), nil, WithSubscriptionBuffer(1, sc.policy))
+			if err := mgr.RegisterRoute(context.TODO(), namespace, "route", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+				t.Fatal(err)
+			}
+			events, unsubscribe, err := mgr.Subscribe(namespace, "route")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer unsubscribe()
+
+			s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "secret"}}
+			if _, err := kubeClient.CoreV1().Secrets(namespace).Create(context.TODO(), s, metav1.CreateOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			for _, version := range []string{"1", "2"} {
+				s.Labels = map[string]string{"version": version}
+				if _, err := kubeClient.CoreV1().Secrets(namespace).Update(context.TODO(), s, metav1.UpdateOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// let the events fill the buffer
+			time.Sleep(200 * time.Millisecond)
+
+			if event := receiveEvent(t, events); event.Type != SecretAdded {
+				t.Fatalf("expected %s event, got %s", SecretAdded, event.Type)
+			}
+			if sc.policy == DropEvents {
+				select {
+				case event := <-events:
+					t.Errorf("expected the events delivered while the buffer was full to be dropped, got %s", event.Type)
+				case <-time.After(200 * time.Millisecond):
+				}
+				return
+			}
+			for _, version := range []string{"1", "2"} {
+				event := receiveEvent(t, events)
+				if event.Type != SecretUpdated || event.New.Labels["version"] != version {
+					t.Errorf("expected update to version %s, got %s event %v", version, event.Type, event.New.Labels)
+				}
+			}
+		})
+	}
+}
+
+func TestNewSecretEventHandler(t *testing.T) {
+	oldSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret", ResourceVersion: "1"}}
+	newSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret", ResourceVersion: "2"}}
+
+	scenarios := []struct {
+		name     string
+		deliver  func(handler cache.ResourceEventHandler)
+		expected SecretEvent
+	}{
+		{
+			name:     "add",
+			deliver:  func(handler cache.ResourceEventHandler) { handler.OnAdd(newSecret, false) },
+			expected: SecretEvent{Type: SecretAdded, New: newSecret},
+		},
+		{
+			name:     "update",
+			deliver:  func(handler cache.ResourceEventHandler) { handler.OnUpdate(oldSecret, newSecret) },
+			expected: SecretEvent{Type: SecretUpdated, Old: oldSecret, New: newSecret},
+		},
+		{
+			name:     "delete",
+			deliver:  func(handler cache.ResourceEventHandler) { handler.OnDelete(oldSecret) },
+			expected: SecretEvent{Type: SecretDeleted, Old: oldSecret},
+		},
+		{
+			name: "tombstone delete",
+			deliver: func(handler cache.ResourceEventHandler) {
+				handler.OnDelete(cache.DeletedFinalStateUnknown{Key: "ns/secret", Obj: oldSecret})
+			},
+			expected: SecretEvent{Type: SecretDeleted, Old: oldSecret},
+		},
+		{
+			name: "tombstone delete of an unknown object",
+			deliver: func(handler cache.ResourceEventHandler) {
+				handler.OnDelete(cache.DeletedFinalStateUnknown{Key: "ns/secret"})
+			},
+			expected: SecretEvent{Type: SecretDeleted},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var events []SecretEvent
+			s.deliver(NewSecretEventHandler(func(event SecretEvent) {
+				events = append(events, event)
+			}))
+			if len(events) != 1 || !reflect.DeepEqual(events[0], s.expected) {
+				t.Errorf("expected event %+v, got %+v", s.expected, events)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package secretmanager
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+// WithReregistrationWindow throttles the re-registrations of a route with another secret, e.g. by UpdateRoute or
+// EnsureAndGet, to one per window, so that a route whose spec flaps doesn't keep recreating informers.
+// The re-registrations made within window of the previous one are coalesced: the route stays registered with its
+// current secret, and the latest desired secret and handler are applied once the window closes.
+// By default routes are re-registered immediately.
+func WithReregistrationWindow(window time.Duration) Option {
+	return func(m *manager) {
+		m.reregistrationWindow = window
+	}
+}
+
+// reregistration tracks the re-registrations of a route, see WithReregistrationWindow.
+type reregistration struct {
+	// last is when the route was last re-registered.
+	last time.Time
+	// pending is the latest re-registration throttled within the window, nil if none.
+	pending *pendingReregistration
+	// timer applies the pending re-registration once the window closes.
+	timer clock.Timer
+}
+
+// pendingReregistration is a re-registration of a route throttled until the window closes.
+type pendingReregistration struct {
+	namespace  string
+	routeName  string
+	secretName string
+	handler    cache.ResourceEventHandlerFuncs
+}
+
+// throttleReregistration returns true if the re-registration of the route is throttled, in which case it is
+// applied once the window closes, and false if the route can be re-registered now. The caller must hold handlersLock.
+func (m *manager) throttleReregistration(namespace, routeName, secretName string, handler cache.ResourceEventHandlerFuncs) bool {
+	if m.reregistrationWindow <= 0 {
+		return false
+	}
+	key := generateKey(namespace, routeName)
+	now := m.clock.Now()
+
+	r, exists := m.reregistrations[key]
+	if !exists || now.Sub(r.last) >= m.reregistrationWindow {
+		// a re-registration applied now supersedes the one pending, if its timer didn't fire yet
+		m.cancelReregistration(key)
+		if m.reregistrations == nil {
+			m.reregistrations = make(map[string]*reregistration)
+		}
+		m.reregistrations[key] = &reregistration{last: now}
+		return false
+	}
+
+	r.pending = &pendingReregistration{namespace: namespace, routeName: routeName, secretName: secretName, handler: handler}
+	if r.timer == nil {
+		r.timer = m.clock.AfterFunc(r.last.Add(m.reregistrationWindow).Sub(now), func() {
+			m.applyReregistration(key, r)
+		})
+	}
+	klog.V(4).Infof("secret manager throttled re-registering route key %s with secret %s", key, secretName)
+	return true
+}
+
+// applyReregistration applies the pending re-registration of the route once its window closed,
+// unless the route was unregistered meanwhile.
+func (m *manager) applyReregistration(key string, r *reregistration) {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+
+	if m.reregistrations[key] != r || r.pending == nil {
+		return
+	}
+	pending := r.pending
+	r.pending = nil
+	r.timer = nil
+
+	// the clock must not be read here, since fake clocks invoke the function while holding their lock
+	var err error
+	_, deferred := m.deferred[key]
+	_, detached := m.detached[key]
+	if previous, registered := m.registeredHandlers[key]; registered {
+		r.last = r.last.Add(m.reregistrationWindow)
+		_, err = m.reregisterRoute(context.Background(), pending.namespace, pending.routeName, pending.secretName, pending.handler, previous)
+	} else if deferred || detached {
+		_, err = m.replaceRoute(context.Background(), pending.namespace, pending.routeName, pending.secretName, pending.handler)
+	}
+	if err != nil {
+		klog.Errorf("secret manager failed to re-register throttled route key %s with secret %s: %v", key, pending.secretName, err)
+	}
+}
+
+// cancelReregistration drops the pending re-registration of the route, if any. The caller must hold handlersLock.
+func (m *manager) cancelReregistration(key string) {
+	r, exists := m.reregistrations[key]
+	if !exists {
+		return
+	}
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	delete(m.reregistrations, key)
+}
@@ -0,0 +1,82 @@
+package secretmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestReregistrationWindow(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	var secrets []*corev1.Secret
+	for _, name := range []string{"secret0", "secret1", "secret2", "secret3"} {
+		secrets = append(secrets, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}})
+	}
+	kubeClient := kubefake.NewSimpleClientset(secrets[0], secrets[1], secrets[2], secrets[3])
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	mgr := newManager(secret.NewSecretMonitor(kubeClient), nil, WithReregistrationWindow(time.Minute), WithClock(fakeClock))
+	informers := func() int {
+		count := 0
+		for _, action := range kubeClient.Actions() {
+			if action.GetVerb() == "list" && action.GetResource().Resource == "secrets" {
+				count += 1
+			}
+		}
+		return count
+	}
+	secretName := func() string {
+		obj, err := mgr.GetSecret(context.TODO(), namespace, routeName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return obj.Name
+	}
+
+	if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "secret0", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	// the first re-registration is applied immediately, the following ones are coalesced
+	for _, name := range []string{"secret1", "secret2", "secret3", "secret1", "secret2"} {
+		if err := mgr.UpdateRoute(context.TODO(), namespace, routeName, name, cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got := secretName(); got != "secret1" {
+		t.Errorf("expected the route to stay registered with secret1 within the window, got %s", got)
+	}
+	if got := informers(); got != 2 {
+		t.Errorf("expected 2 informers within the window, got %d", got)
+	}
+
+	// the latest secret is applied once the window closes
+	fakeClock.Step(time.Minute)
+	err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return secretName() == "secret2", nil
+	})
+	if err != nil {
+		t.Fatalf("expected the route to be re-registered with secret2: %v", err)
+	}
+	if got := informers(); got != 3 {
+		t.Errorf("expected 3 informers after the window, got %d", got)
+	}
+
+	// pending re-registrations are dropped with the route
+	if err := mgr.UpdateRoute(context.TODO(), namespace, routeName, "secret3", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.UnregisterRoute(namespace, routeName); err != nil {
+		t.Fatal(err)
+	}
+	fakeClock.Step(time.Minute)
+	if mgr.IsRouteRegistered(namespace, routeName) {
+		t.Error("expected the unregistered route not to be re-registered")
+	}
+}
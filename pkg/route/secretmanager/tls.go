@@ -0,0 +1,107 @@
+package secretmanager
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// GetTLSKeyPair returns the key pair stored in the secret registered with a route.
+// The certificate and key are read from the data keys configured with WithKeyNames.
+func (m *manager) GetTLSKeyPair(ctx context.Context, namespace, routeName string) (*tls.Certificate, error) {
+	secret, err := m.GetSecret(ctx, namespace, routeName)
+	if err != nil {
+		return nil, err
+	}
+	return m.keyPairFromSecret(secret)
+}
+
+// keyPairFromSecret parses the key pair stored in the data keys configured with WithKeyNames.
+func (m *manager) keyPairFromSecret(secret *v1.Secret) (*tls.Certificate, error) {
+	certPEM, ok := secret.Data[m.certKey]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no %q key", secret.Namespace, secret.Name, m.certKey)
+	}
+	keyPEM, ok := secret.Data[m.keyKey]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no %q key", secret.Namespace, secret.Name, m.keyKey)
+	}
+
+	keyPair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key pair from secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+	return &keyPair, nil
+}
+
+// GetCertificateExpiry returns the NotAfter time of the leaf certificate
+// stored in the secret registered with a route.
+func (m *manager) GetCertificateExpiry(ctx context.Context, namespace, routeName string) (time.Time, error) {
+	secret, err := m.GetSecret(ctx, namespace, routeName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return m.certificateExpiry(secret)
+}
+
+// certificateExpiry returns the NotAfter time of the leaf certificate of the key pair stored in the secret.
+func (m *manager) certificateExpiry(secret *v1.Secret) (time.Time, error) {
+	keyPair, err := m.keyPairFromSecret(secret)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return leaf.NotAfter, nil
+}
+
+// GetParsedCertificate returns the leaf certificate, which is the first certificate of the chain stored in the
+// secret registered with a route, read from the data key configured with WithKeyNames. The secret is read from
+// the cache without waiting for it to sync. Returns an error if the key is missing or holds no valid certificate.
+func (m *manager) GetParsedCertificate(namespace, routeName string) (*x509.Certificate, error) {
+	secret, err := m.cachedSecret(namespace, routeName)
+	if err != nil {
+		return nil, err
+	}
+
+	certPEM, ok := secret.Data[m.certKey]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no %q key", secret.Namespace, secret.Name, m.certKey)
+	}
+	for rest := certPEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, fmt.Errorf("secret %s/%s has no certificate in its %q key", secret.Namespace, secret.Name, m.certKey)
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		leaf, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate from secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		return leaf, nil
+	}
+}
+
+// cachedSecret returns the secret registered with a route from the cache, without waiting for it to sync.
+func (m *manager) cachedSecret(namespace, routeName string) (*v1.Secret, error) {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+
+	key := generateKey(namespace, routeName)
+	handlerRegistration, exists := m.registeredHandlers[key]
+	if !exists {
+		return nil, fmt.Errorf("no handler registered with key %s", key)
+	}
+	return handlerRegistration.GetSecret()
+}
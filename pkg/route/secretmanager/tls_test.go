@@ -0,0 +1,211 @@
+package secretmanager
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/secret/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// newCertKeyPEM generates a self-signed certificate valid for the given lifetime.
+func newCertKeyPEM(t *testing.T, lifetime time.Duration) ([]byte, []byte, time.Time) {
+	t.Helper()
+
+	config, err := crypto.MakeSelfSignedCAConfigForDuration("secretmanager-test", lifetime)
+	if err != nil {
+		t.Fatalf("failed to generate certificate: %v", err)
+	}
+	certPEM, keyPEM, err := config.GetPEMBytes()
+	if err != nil {
+		t.Fatalf("failed to encode certificate: %v", err)
+	}
+	return certPEM, keyPEM, config.Certs[0].NotAfter
+}
+
+func TestGetTLSKeyPair(t *testing.T) {
+	var (
+		namespace = "ns"
+		routeName = "route"
+	)
+	certPEM, keyPEM, notAfter := newCertKeyPEM(t, time.Hour)
+
+	scenarios := []struct {
+		name      string
+		opts      []Option
+		data      map[string][]byte
+		expectErr bool
+	}{
+		{
+			name: "standard key names",
+			data: map[string][]byte{
+				corev1.TLSCertKey:       certPEM,
+				corev1.TLSPrivateKeyKey: keyPEM,
+			},
+			expectErr: false,
+		},
+		{
+			name: "custom key names",
+			opts: []Option{WithKeyNames("certificate", "private_key")},
+			data: map[string][]byte{
+				"certificate": certPEM,
+				"private_key": keyPEM,
+			},
+			expectErr: false,
+		},
+		{
+			name: "custom key names with standard layout",
+			opts: []Option{WithKeyNames("certificate", "private_key")},
+			data: map[string][]byte{
+				corev1.TLSCertKey:       certPEM,
+				corev1.TLSPrivateKeyKey: keyPEM,
+			},
+			expectErr: true,
+		},
+		{
+			name: "missing private key",
+			data: map[string][]byte{
+				corev1.TLSCertKey: certPEM,
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid certificate",
+			data: map[string][]byte{
+				corev1.TLSCertKey:       []byte("invalid"),
+				corev1.TLSPrivateKeyKey: keyPEM,
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			sm := &fake.SecretMonitor{
+				Secret: &corev1.Secret{
+					Type:       corev1.SecretTypeTLS,
+					ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace},
+					Data:       s.data,
+				},
+			}
+			mgr := newManager(sm, nil, s.opts...)
+			if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+				t.Fatalf("failed to register route: %v", err)
+			}
+
+			keyPair, err := mgr.GetTLSKeyPair(context.TODO(), namespace, routeName)
+			if (err != nil) != s.expectErr {
+				t.Fatalf("expected errors to be %t, but got %v", s.expectErr, err)
+			}
+			if s.expectErr {
+				return
+			}
+			if len(keyPair.Certificate) != 1 {
+				t.Fatalf("expected 1 certificate, got %d", len(keyPair.Certificate))
+			}
+
+			gotNotAfter, err := mgr.GetCertificateExpiry(context.TODO(), namespace, routeName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !gotNotAfter.Equal(notAfter) {
+				t.Errorf("expected expiry %v, got %v", notAfter, gotNotAfter)
+			}
+		})
+	}
+}
+
+// newServingCertPEM generates a self-signed serving certificate for the given DNS names.
+func newServingCertPEM(t *testing.T, commonName string, dnsNames ...string) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to generate certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestGetParsedCertificate(t *testing.T) {
+	namespace, routeName := "ns", "route"
+	certPEM := newServingCertPEM(t, "route.example.com", "route.example.com", "www.route.example.com")
+
+	scenarios := []struct {
+		name      string
+		data      map[string][]byte
+		expectErr bool
+	}{
+		{
+			name: "valid certificate",
+			data: map[string][]byte{corev1.TLSCertKey: certPEM},
+		},
+		{
+			name:      "missing certificate",
+			data:      map[string][]byte{},
+			expectErr: true,
+		},
+		{
+			name:      "invalid PEM",
+			data:      map[string][]byte{corev1.TLSCertKey: []byte("invalid")},
+			expectErr: true,
+		},
+		{
+			name:      "corrupted certificate",
+			data:      map[string][]byte{corev1.TLSCertKey: []byte("-----BEGIN CERTIFICATE-----\naW52YWxpZA==\n-----END CERTIFICATE-----\n")},
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			sm := &fake.SecretMonitor{
+				Secret: &corev1.Secret{
+					Type:       corev1.SecretTypeTLS,
+					ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace},
+					Data:       s.data,
+				},
+			}
+			mgr := newManager(sm, nil)
+			if err := mgr.RegisterRoute(context.TODO(), namespace, routeName, "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+				t.Fatalf("failed to register route: %v", err)
+			}
+
+			cert, err := mgr.GetParsedCertificate(namespace, routeName)
+			if (err != nil) != s.expectErr {
+				t.Fatalf("expected errors to be %t, but got %v", s.expectErr, err)
+			}
+			if s.expectErr {
+				return
+			}
+			if cert.Subject.CommonName != "route.example.com" {
+				t.Errorf("expected subject route.example.com, got %s", cert.Subject.CommonName)
+			}
+			if expected := []string{"route.example.com", "www.route.example.com"}; !reflect.DeepEqual(cert.DNSNames, expected) {
+				t.Errorf("expected SANs %v, got %v", expected, cert.DNSNames)
+			}
+		})
+	}
+}
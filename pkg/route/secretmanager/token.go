@@ -0,0 +1,59 @@
+package secretmanager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openshift/library-go/pkg/secret"
+)
+
+// RegistrationToken returns a stable token identifying the registration of a route with a secret,
+// in the form namespace/route/secret. Since names can't contain a slash, the token can be
+// persisted and later resolved with ResolveToken, e.g. after a restart.
+func RegistrationToken(namespace, routeName, secretName string) string {
+	return fmt.Sprintf("%s/%s/%s", namespace, routeName, secretName)
+}
+
+// parseRegistrationToken splits a token produced by RegistrationToken.
+func parseRegistrationToken(token string) (namespace, routeName, secretName string, err error) {
+	parts := strings.Split(token, "/")
+	if len(parts) != 3 || len(parts[0]) == 0 || len(parts[1]) == 0 || len(parts[2]) == 0 {
+		return "", "", "", fmt.Errorf("invalid registration token %q, expected namespace/route/secret", token)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
+
+// GetRegistrationToken returns the token of the current registration of a route.
+func (m *manager) GetRegistrationToken(namespace, routeName string) (string, error) {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+
+	key := generateKey(namespace, routeName)
+	handlerRegistration, exists := m.registeredHandlers[key]
+	if !exists {
+		return "", fmt.Errorf("no handler registered with key %s", key)
+	}
+	return RegistrationToken(namespace, routeName, handlerRegistration.GetKey().Name), nil
+}
+
+// ResolveToken returns the live registration identified by a token produced by RegistrationToken.
+// Returns an error if the route isn't registered, or is registered with another secret.
+func (m *manager) ResolveToken(token string) (secret.SecretEventHandlerRegistration, error) {
+	namespace, routeName, secretName, err := parseRegistrationToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+
+	key := generateKey(namespace, routeName)
+	handlerRegistration, exists := m.registeredHandlers[key]
+	if !exists {
+		return nil, fmt.Errorf("no handler registered with key %s", key)
+	}
+	if handlerRegistration.GetKey().Name != secretName {
+		return nil, fmt.Errorf("route key %s is registered with secret %s, not %s", key, handlerRegistration.GetKey().Name, secretName)
+	}
+	return handlerRegistration, nil
+}
@@ -0,0 +1,57 @@
+package secretmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/secret/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestRegistrationTokenRoundTrip(t *testing.T) {
+	namespace, routeName, secretName := "ns", "route", "secret"
+	mgr := newManager(&fake.SecretMonitor{}, nil)
+
+	registration, err := mgr.RegisterRouteWithResult(context.TODO(), namespace, routeName, secretName, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := mgr.GetRegistrationToken(namespace, routeName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != RegistrationToken(namespace, routeName, secretName) {
+		t.Errorf("unexpected token %q", token)
+	}
+
+	resolved, err := mgr.ResolveToken(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved != registration {
+		t.Error("expected the token to resolve to the live registration")
+	}
+
+	// a token of another secret doesn't match the registration
+	if _, err := mgr.ResolveToken(RegistrationToken(namespace, routeName, "other")); err == nil {
+		t.Error("expected an error for a token of another secret")
+	}
+
+	// the token no longer resolves once the route is unregistered
+	if err := mgr.UnregisterRoute(namespace, routeName); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.ResolveToken(token); err == nil {
+		t.Error("expected an error for a token of an unregistered route")
+	}
+}
+
+func TestResolveInvalidToken(t *testing.T) {
+	mgr := newManager(&fake.SecretMonitor{}, nil)
+	for _, token := range []string{"", "ns/route", "ns/route/secret/extra", "ns//secret"} {
+		if _, err := mgr.ResolveToken(token); err == nil {
+			t.Errorf("expected an error for token %q", token)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package secretmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/openshift/library-go/pkg/secret"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// ErrWrongSecretType is returned when the secret referenced by a route is not a TLS secret.
+	ErrWrongSecretType = errors.New("secret has the wrong type")
+
+	// ErrCrossNamespaceSecret is returned when a route references a secret of another namespace.
+	ErrCrossNamespaceSecret = errors.New("secret must be in the namespace of the route")
+
+	// ErrCABundleMissing is returned when the secret referenced by a route has no CA bundle.
+	ErrCABundleMissing = errors.New("secret has no CA bundle")
+
+	// ErrInvalidCABundle is returned when the CA bundle of a secret doesn't hold any CA certificate.
+	ErrInvalidCABundle = errors.New("secret has an invalid CA bundle")
+
+	// ErrSelfOwnedSecret is returned when registering a route with a secret owned by the route itself,
+	// see WithRejectSelfOwnedSecrets.
+	ErrSelfOwnedSecret = errors.New("secret is owned by the route")
+)
+
+// ValidateRoute checks that the route could be registered with the secret, without registering it.
+// The secret name may be qualified as namespace/name, in which case the namespace must be the one of the route.
+// The secret is read with a live GET, so it must exist and be of type kubernetes.io/tls, and its CA bundle,
+// if any, must hold at least one CA certificate. Immutable secrets are valid, but a warning is logged since
+// they can't be rotated in place, see IsSecretImmutable.
+// No informer is created, and the registered routes are left untouched.
+func (m *manager) ValidateRoute(ctx context.Context, namespace, routeName, secretName string) error {
+	key := generateKey(namespace, routeName)
+
+	if secretNamespace, name, qualified := strings.Cut(secretName, "/"); qualified {
+		if secretNamespace != namespace {
+			return fmt.Errorf("%w: route key %s references secret %s", ErrCrossNamespaceSecret, key, secretName)
+		}
+		secretName = name
+	}
+	if err := secret.NewObjectKey(namespace, secretName).Validate(); err != nil {
+		return fmt.Errorf("invalid secret reference for route key %s: %w", key, err)
+	}
+
+	if m.kubeClient == nil {
+		return fmt.Errorf("no client to validate secret %s/%s of route key %s", namespace, secretName, key)
+	}
+	obj, err := m.kubeClient.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("%w: %w", secret.ErrSecretNotFound, err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s/%s of route key %s: %w", namespace, secretName, key, err)
+	}
+	if err := validateSecretType(obj); err != nil {
+		return err
+	}
+	if err := validateSecretCABundle(obj); err != nil {
+		return err
+	}
+	if isImmutable(obj) {
+		klog.Warningf("secret %s/%s of route key %s is immutable, it can only be rotated by referencing a new secret", namespace, secretName, key)
+	}
+
+	return nil
+}
+
+// IsSecretImmutable returns true if the cached secret of a registered route is immutable, in which case its
+// certificate can't be rotated by updating the secret in place, but only by registering the route with a new secret.
+// The secret is read from the cache without waiting for it to sync.
+func (m *manager) IsSecretImmutable(namespace, routeName string) (bool, error) {
+	obj, err := m.cachedSecret(namespace, routeName)
+	if err != nil {
+		return false, err
+	}
+	return isImmutable(obj), nil
+}
+
+// isImmutable returns true if the secret is marked as immutable.
+func isImmutable(obj *v1.Secret) bool {
+	return obj.Immutable != nil && *obj.Immutable
+}
+
+// validateSecretType returns ErrWrongSecretType if the secret is not of type kubernetes.io/tls.
+// Service account token secrets get a specific message, since their token is often mistaken for a certificate.
+func validateSecretType(obj *v1.Secret) error {
+	switch obj.Type {
+	case v1.SecretTypeTLS:
+		return nil
+	case v1.SecretTypeServiceAccountToken:
+		return fmt.Errorf("%w: secret %s/%s is a service account token, which is not a valid external certificate", ErrWrongSecretType, obj.Namespace, obj.Name)
+	default:
+		return fmt.Errorf("%w: secret %s/%s is of type %q, expected %q", ErrWrongSecretType, obj.Namespace, obj.Name, obj.Type, v1.SecretTypeTLS)
+	}
+}
+
+// validateNotSelfOwned returns ErrSelfOwnedSecret if the secret is owned by the route, which is read with a live GET.
+// Secrets which don't exist yet are not owned by the route.
+func (m *manager) validateNotSelfOwned(ctx context.Context, namespace, routeName, secretName string) error {
+	key := generateKey(namespace, routeName)
+	if m.kubeClient == nil {
+		return fmt.Errorf("no client to check the owners of secret %s/%s of route key %s", namespace, secretName, key)
+	}
+	obj, err := m.kubeClient.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s/%s of route key %s: %w", namespace, secretName, key, err)
+	}
+	for _, owner := range obj.OwnerReferences {
+		gv, err := schema.ParseGroupVersion(owner.APIVersion)
+		if err != nil {
+			continue
+		}
+		if gv.Group == routev1.GroupName && owner.Kind == "Route" && owner.Name == routeName {
+			return fmt.Errorf("%w: secret %s/%s of route key %s", ErrSelfOwnedSecret, namespace, secretName, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package secretmanager
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/secret"
+	"github.com/openshift/library-go/pkg/secret/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestValidateRoute(t *testing.T) {
+	namespace := "ns"
+	tlsSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: namespace},
+		Type:       corev1.SecretTypeTLS,
+	}
+	opaqueSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "opaque", Namespace: namespace},
+		Type:       corev1.SecretTypeOpaque,
+	}
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: namespace},
+		Type:       corev1.SecretTypeServiceAccountToken,
+		Data:       map[string][]byte{corev1.ServiceAccountTokenKey: []byte("token")},
+	}
+	invalidCASecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid-ca", Namespace: namespace},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{secret.TLSCABundleKey: []byte("invalid")},
+	}
+	otherNamespaceSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "other"},
+		Type:       corev1.SecretTypeTLS,
+	}
+
+	scenarios := []struct {
+		name         string
+		secretName   string
+		expectErr    error
+		expectErrMsg string
+	}{
+		{
+			name:       "valid secret",
+			secretName: "tls",
+		},
+		{
+			name:       "valid secret qualified with the route namespace",
+			secretName: namespace + "/tls",
+		},
+		{
+			name:       "missing secret",
+			secretName: "missing",
+			expectErr:  secret.ErrSecretNotFound,
+		},
+		{
+			name:       "wrong secret type",
+			secretName: "opaque",
+			expectErr:  ErrWrongSecretType,
+		},
+		{
+			name:         "service account token secret",
+			secretName:   "token",
+			expectErr:    ErrWrongSecretType,
+			expectErrMsg: "is a service account token, which is not a valid external certificate",
+		},
+		{
+			name:       "invalid CA bundle",
+			secretName: "invalid-ca",
+			expectErr:  ErrInvalidCABundle,
+		},
+		{
+			name:       "secret in another namespace",
+			secretName: "other/tls",
+			expectErr:  ErrCrossNamespaceSecret,
+		},
+		{
+			name:       "empty secret name",
+			secretName: "",
+			expectErr:  secret.ErrEmptySecretName,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			sm := &fake.SecretMonitor{}
+			mgr := newManager(sm, nil)
+			mgr.kubeClient = kubefake.NewSimpleClientset(tlsSecret, opaqueSecret, tokenSecret, invalidCASecret, otherNamespaceSecret)
+
+			err := mgr.ValidateRoute(context.TODO(), namespace, "route", s.secretName)
+			if !errors.Is(err, s.expectErr) {
+				t.Fatalf("expected %v, got %v", s.expectErr, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), s.expectErrMsg) {
+				t.Errorf("expected error message to contain %q, got %q", s.expectErrMsg, err)
+			}
+			if len(mgr.registeredHandlers) != 0 {
+				t.Errorf("expected no route to be registered, got %v", mgr.registeredHandlers)
+			}
+		})
+	}
+}
+
+func TestRejectSelfOwnedSecrets(t *testing.T) {
+	namespace := "ns"
+	ownedBy := func(name, routeName string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "route.openshift.io/v1",
+					Kind:       "Route",
+					Name:       routeName,
+				}},
+			},
+		}
+	}
+	kubeClient := kubefake.NewSimpleClientset(
+		ownedBy("self-owned", "route"),
+		ownedBy("other-owned", "other"),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "unowned"}},
+	)
+
+	scenarios := []struct {
+		name       string
+		secretName string
+		expectErr  error
+	}{
+		{
+			name:       "secret owned by the route",
+			secretName: "self-owned",
+			expectErr:  ErrSelfOwnedSecret,
+		},
+		{
+			name:       "secret owned by another route",
+			secretName: "other-owned",
+		},
+		{
+			name:       "secret without owner",
+			secretName: "unowned",
+		},
+		{
+			name:       "missing secret",
+			secretName: "missing",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			mgr := NewManager(kubeClient, nil, WithRejectSelfOwnedSecrets()).(*manager)
+
+			err := mgr.RegisterRoute(context.TODO(), namespace, "route", s.secretName, cache.ResourceEventHandlerFuncs{})
+			if !errors.Is(err, s.expectErr) {
+				t.Fatalf("expected %v, got %v", s.expectErr, err)
+			}
+			if registered := mgr.IsRouteRegistered(namespace, "route"); registered != (s.expectErr == nil) {
+				t.Errorf("expected registered %t, got %t", s.expectErr == nil, registered)
+			}
+		})
+	}
+
+	// self-owned secrets are allowed by default
+	mgr := NewManager(kubeClient, nil)
+	if err := mgr.RegisterRoute(context.TODO(), namespace, "route", "self-owned", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestIsSecretImmutable(t *testing.T) {
+	immutable, mutable := true, false
+	scenarios := []struct {
+		name      string
+		immutable *bool
+		expected  bool
+	}{
+		{name: "immutable secret", immutable: &immutable, expected: true},
+		{name: "mutable secret", immutable: &mutable},
+		{name: "secret without immutable flag"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			monitor := &fake.SecretMonitor{Secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+				Immutable:  s.immutable,
+			}}
+			mgr := newManager(monitor, nil)
+			if err := mgr.RegisterRoute(context.TODO(), "ns", "route", "secret", cache.ResourceEventHandlerFuncs{}); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := mgr.IsSecretImmutable("ns", "route")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != s.expected {
+				t.Errorf("expected immutable to be %v, got %v", s.expected, got)
+			}
+		})
+	}
+
+	mgr := newManager(&fake.SecretMonitor{}, nil)
+	if _, err := mgr.IsSecretImmutable("ns", "unregistered"); err == nil {
+		t.Error("expected an error for an unregistered route")
+	}
+}
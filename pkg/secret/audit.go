@@ -0,0 +1,28 @@
+package secret
+
+import "context"
+
+// readerKey is the context key of the identity of the component reading secrets.
+type readerKey struct{}
+
+// ContextWithReader returns a copy of ctx identifying the component reading secrets with it,
+// e.g. the name of a controller. The reader is reported to the WithOnSecretAccessed callback.
+func ContextWithReader(ctx context.Context, reader string) context.Context {
+	return context.WithValue(ctx, readerKey{}, reader)
+}
+
+// ReaderFromContext returns the reader set by ContextWithReader, empty if none.
+func ReaderFromContext(ctx context.Context) string {
+	reader, _ := ctx.Value(readerKey{}).(string)
+	return reader
+}
+
+// WithOnSecretAccessed registers a callback invoked on every successful GetSecret with the key of the secret
+// and the reader of the context, see ContextWithReader, e.g. to keep an audit trail of the secrets read from
+// the cache. The secret itself is never passed to the callback. The callback is invoked synchronously and
+// must not block nor call the monitor.
+func WithOnSecretAccessed(onSecretAccessed func(key ObjectKey, reader string)) Option {
+	return func(s *secretMonitor) {
+		s.onSecretAccessed = onSecretAccessed
+	}
+}
@@ -0,0 +1,51 @@
+package secret
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestOnSecretAccessed(t *testing.T) {
+	type access struct {
+		key    ObjectKey
+		reader string
+	}
+	key := NewObjectKey("ns", "secret")
+	var accesses []access
+	sm := newSecretMonitor(fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name)), WithOnSecretAccessed(func(k ObjectKey, reader string) {
+		accesses = append(accesses, access{key: k, reader: reader})
+	}))
+
+	registration, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, "missing", cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sm.GetSecret(ContextWithReader(context.TODO(), "router"), registration); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := sm.GetSecret(context.TODO(), registration); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// cache misses are not reported
+	if _, err := sm.GetSecret(ContextWithReader(context.TODO(), "router"), missing); err == nil {
+		t.Fatal("expected error for a missing secret")
+	}
+
+	expected := []access{{key: key, reader: "router"}, {key: key}}
+	if len(accesses) != len(expected) {
+		t.Fatalf("expected accesses %v, got %v", expected, accesses)
+	}
+	for i := range expected {
+		if accesses[i] != expected[i] {
+			t.Errorf("expected access %v, got %v", expected[i], accesses[i])
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package secret
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// circuitState is the state of a circuitBreaker.
+type circuitState int
+
+const (
+	// circuitClosed lets informers be created.
+	circuitClosed circuitState = iota
+	// circuitOpen fails the creation of informers until the cooldown expires.
+	circuitOpen
+	// circuitHalfOpen lets a single informer be created to probe the apiserver.
+	circuitHalfOpen
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitClosed:
+		return "closed"
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// circuitBreaker stops the creation of new informers after failureThreshold consecutive
+// informer start failures within window, for cooldown. Once the cooldown expires, the next
+// informer start is a probe: its success closes the breaker, its failure opens it again.
+type circuitBreaker struct {
+	// name is the monitor label of the state metric.
+	name             string
+	failureThreshold int
+	window           time.Duration
+	cooldown         time.Duration
+
+	lock     sync.Mutex
+	state    circuitState
+	failures []time.Time
+	openedAt time.Time
+}
+
+func newCircuitBreaker(name string, failureThreshold int, window, cooldown time.Duration) *circuitBreaker {
+	b := &circuitBreaker{
+		name:             name,
+		failureThreshold: failureThreshold,
+		window:           window,
+		cooldown:         cooldown,
+	}
+	circuitBreakerState.WithLabelValues(name).Set(float64(circuitClosed))
+	return b
+}
+
+// allow returns ErrCircuitOpen if no informer may be started at now.
+func (b *circuitBreaker) allow(now time.Time) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.state == circuitOpen {
+		if remaining := b.openedAt.Add(b.cooldown).Sub(now); remaining > 0 {
+			return fmt.Errorf("%w: retrying in %v", ErrCircuitOpen, remaining)
+		}
+		b.setState(circuitHalfOpen)
+	}
+	return nil
+}
+
+// recordSuccess closes the breaker and forgets the previous failures.
+func (b *circuitBreaker) recordSuccess() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.failures = nil
+	b.setState(circuitClosed)
+}
+
+// recordFailure records an informer start failure at now, opening the breaker
+// if the probe failed or too many failures happened within the window.
+func (b *circuitBreaker) recordFailure(now time.Time) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.state == circuitHalfOpen {
+		b.open(now)
+		return
+	}
+
+	// only keep the failures within the window
+	recent := b.failures[:0]
+	for _, failure := range b.failures {
+		if now.Sub(failure) < b.window {
+			recent = append(recent, failure)
+		}
+	}
+	b.failures = append(recent, now)
+
+	if len(b.failures) >= b.failureThreshold {
+		b.open(now)
+	}
+}
+
+// open opens the breaker at now. The caller must hold the lock.
+func (b *circuitBreaker) open(now time.Time) {
+	b.failures = nil
+	b.openedAt = now
+	b.setState(circuitOpen)
+}
+
+// setState updates the state and its metric. The caller must hold the lock.
+func (b *circuitBreaker) setState(state circuitState) {
+	if b.state != state {
+		klog.Info("secret monitor circuit breaker state changed", " monitor ", b.name, " from ", b.state, " to ", state)
+	}
+	b.state = state
+	circuitBreakerState.WithLabelValues(b.name).Set(float64(state))
+}
+
+// getState returns the current state of the breaker.
+func (b *circuitBreaker) getState() circuitState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.state
+}
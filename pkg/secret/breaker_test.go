@@ -0,0 +1,126 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics/testutil"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// expectCircuitState checks the state of the breaker and its metric.
+func expectCircuitState(t *testing.T, b *circuitBreaker, expected circuitState) {
+	t.Helper()
+	if registerTestMetrics() {
+		// the gauge only reports once registered
+		circuitBreakerState.WithLabelValues(b.name).Set(float64(b.getState()))
+	}
+
+	if state := b.getState(); state != expected {
+		t.Errorf("expected circuit breaker to be %v, got %v", expected, state)
+	}
+	value, err := testutil.GetGaugeMetricValue(circuitBreakerState.WithLabelValues(b.name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != float64(expected) {
+		t.Errorf("expected circuit breaker state metric %v, got %v", float64(expected), value)
+	}
+}
+
+// addHandlerWithTimeout adds a handler for the secret, waiting at most 100ms for its informer to sync.
+func addHandlerWithTimeout(sm *secretMonitor, name string) error {
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+	_, err := sm.AddSecretEventHandler(ctx, "ns", name, cache.ResourceEventHandlerFuncs{})
+	return err
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	blockingClient := &blockingListKubeClient{Interface: fake.NewSimpleClientset()}
+	sm := newSecretMonitor(blockingClient,
+		WithClock(fakeClock),
+		WithCircuitBreaker("circuit-breaker", 2, time.Minute, time.Minute),
+	)
+	expectCircuitState(t, sm.breaker, circuitClosed)
+
+	// consecutive informer start failures open the breaker
+	for _, name := range []string{"secret1", "secret2"} {
+		if err := addHandlerWithTimeout(sm, name); !errors.Is(err, ErrCacheNotSynced) {
+			t.Fatalf("expected %v, got %v", ErrCacheNotSynced, err)
+		}
+	}
+	expectCircuitState(t, sm.breaker, circuitOpen)
+
+	// no informer is started while the breaker is open
+	start := time.Now()
+	if err := addHandlerWithTimeout(sm, "secret3"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected %v, got %v", ErrCircuitOpen, err)
+	}
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Errorf("expected to fail fast, took %v", elapsed)
+	}
+
+	// a failed probe after the cooldown opens the breaker again
+	fakeClock.Step(time.Minute)
+	if err := addHandlerWithTimeout(sm, "secret3"); !errors.Is(err, ErrCacheNotSynced) {
+		t.Fatalf("expected %v, got %v", ErrCacheNotSynced, err)
+	}
+	expectCircuitState(t, sm.breaker, circuitOpen)
+	if err := addHandlerWithTimeout(sm, "secret3"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected %v, got %v", ErrCircuitOpen, err)
+	}
+
+	// a successful probe closes the breaker
+	fakeClock.Step(time.Minute)
+	sm.SetKubeClient(fake.NewSimpleClientset(fakeSecret("ns", "secret3"), fakeSecret("ns", "secret4")))
+	if err := addHandlerWithTimeout(sm, "secret3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectCircuitState(t, sm.breaker, circuitClosed)
+	if err := addHandlerWithTimeout(sm, "secret4"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker("circuit-breaker-window", 2, time.Minute, time.Minute)
+
+	// failures outside of the window are forgotten
+	b.recordFailure(now)
+	b.recordFailure(now.Add(time.Minute))
+	expectCircuitState(t, b, circuitClosed)
+
+	// a success resets the failures
+	b.recordSuccess()
+	b.recordFailure(now.Add(time.Minute + time.Second))
+	expectCircuitState(t, b, circuitClosed)
+
+	b.recordFailure(now.Add(time.Minute + 2*time.Second))
+	expectCircuitState(t, b, circuitOpen)
+	if err := b.allow(now.Add(2*time.Minute + time.Second)); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected %v, got %v", ErrCircuitOpen, err)
+	}
+	if err := b.allow(now.Add(2*time.Minute + 2*time.Second)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	expectCircuitState(t, b, circuitHalfOpen)
+}
+
+func TestCircuitBreakerStatePerMonitor(t *testing.T) {
+	registerTestMetrics()
+	now := time.Now()
+	opened := newCircuitBreaker("opened-monitor", 1, time.Minute, time.Minute)
+	opened.recordFailure(now)
+
+	// creating the breaker of another monitor doesn't reset the state of the first one
+	closed := newCircuitBreaker("closed-monitor", 1, time.Minute, time.Minute)
+	expectCircuitState(t, opened, circuitOpen)
+	expectCircuitState(t, closed, circuitClosed)
+}
@@ -0,0 +1,51 @@
+package secret
+
+// MonitorCallbacks bundles the lifecycle callbacks of the monitor, so that they can be registered with
+// a single option, see WithCallbacks. Every callback is optional.
+type MonitorCallbacks struct {
+	// OnSynced is invoked once the informer of a secret has synced, see WithOnSynced.
+	OnSynced func(key ObjectKey)
+	// OnInformerExited is invoked when the informer of a secret exits unexpectedly, see WithOnInformerExited.
+	OnInformerExited func(key ObjectKey)
+	// OnNamespaceTerminating is invoked once the monitors of a terminating namespace have been removed,
+	// see WithOnNamespaceTerminating.
+	OnNamespaceTerminating func(namespace string)
+	// OnListWatchError is invoked whenever a list or watch call of an informer fails, see WithOnListWatchError.
+	OnListWatchError func(key ObjectKey, err error)
+	// OnPermissionDenied is invoked with the key of a secret whenever a list or watch call of its informer
+	// is forbidden or unauthorized, e.g. to report missing RBAC. It is invoked after OnListWatchError,
+	// from the informer goroutine, and must not block.
+	OnPermissionDenied func(key ObjectKey, err error)
+	// OnSecretAccessed is invoked on every successful GetSecret, see WithOnSecretAccessed.
+	OnSecretAccessed func(key ObjectKey, reader string)
+	// OnSecretTooLarge is invoked when the data of an oversized secret is dropped, see WithOnSecretTooLarge.
+	OnSecretTooLarge func(key ObjectKey, size int)
+}
+
+// WithCallbacks registers the callbacks of the bundle, instead of an option per callback.
+// Nil callbacks are ignored, keeping the callbacks registered by previous options.
+func WithCallbacks(callbacks MonitorCallbacks) Option {
+	return func(s *secretMonitor) {
+		if callbacks.OnSynced != nil {
+			s.onSynced = callbacks.OnSynced
+		}
+		if callbacks.OnInformerExited != nil {
+			s.onInformerExited = callbacks.OnInformerExited
+		}
+		if callbacks.OnNamespaceTerminating != nil {
+			s.onNamespaceTerminating = callbacks.OnNamespaceTerminating
+		}
+		if callbacks.OnListWatchError != nil {
+			s.onListWatchError = callbacks.OnListWatchError
+		}
+		if callbacks.OnPermissionDenied != nil {
+			s.onPermissionDenied = callbacks.OnPermissionDenied
+		}
+		if callbacks.OnSecretAccessed != nil {
+			s.onSecretAccessed = callbacks.OnSecretAccessed
+		}
+		if callbacks.OnSecretTooLarge != nil {
+			s.onSecretTooLarge = callbacks.OnSecretTooLarge
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestWithCallbacks(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	deniedKey := NewObjectKey("denied", "secret")
+	kubeClient := fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name))
+	kubeClient.PrependReactor("list", "secrets", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == deniedKey.Namespace {
+			return true, nil, apierrors.NewForbidden(action.GetResource().GroupResource(), "", errors.New("no RBAC"))
+		}
+		return false, nil, nil
+	})
+
+	synced := make(chan ObjectKey, 1)
+	accessed := make(chan string, 1)
+	listWatchErrors := make(chan ObjectKey, 10)
+	denied := make(chan ObjectKey, 10)
+	sm := newSecretMonitor(kubeClient,
+		WithOnSynced(func(k ObjectKey) {
+			t.Errorf("expected the callback of the bundle to replace the one of WithOnSynced, got %v", k)
+		}),
+		WithCallbacks(MonitorCallbacks{
+			OnSynced: func(k ObjectKey) {
+				synced <- k
+			},
+			OnSecretAccessed: func(_ ObjectKey, reader string) {
+				accessed <- reader
+			},
+			OnListWatchError: func(k ObjectKey, _ error) {
+				listWatchErrors <- k
+			},
+			OnPermissionDenied: func(k ObjectKey, err error) {
+				if !apierrors.IsForbidden(err) {
+					t.Errorf("expected a forbidden error, got %v", err)
+				}
+				denied <- k
+			},
+		}),
+		// nil callbacks don't reset the bundle
+		WithCallbacks(MonitorCallbacks{}),
+	)
+
+	registration, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case k := <-synced:
+		if k != key {
+			t.Errorf("expected %v to sync, got %v", key, k)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the informer to sync")
+	}
+	if _, err := sm.GetSecret(ContextWithReader(context.TODO(), "reader"), registration); err != nil {
+		t.Fatal(err)
+	}
+	if reader := <-accessed; reader != "reader" {
+		t.Errorf("expected an access by reader, got %q", reader)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 500*time.Millisecond)
+	defer cancel()
+	if _, err := sm.AddSecretEventHandler(ctx, deniedKey.Namespace, deniedKey.Name, cache.ResourceEventHandlerFuncs{}); err == nil {
+		t.Error("expected the informer of a forbidden secret not to sync")
+	}
+	for name, callback := range map[string]chan ObjectKey{"OnListWatchError": listWatchErrors, "OnPermissionDenied": denied} {
+		select {
+		case k := <-callback:
+			if k != deniedKey {
+				t.Errorf("expected %s for %v, got %v", name, deniedKey, k)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s", name)
+		}
+	}
+}
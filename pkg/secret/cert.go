@@ -0,0 +1,40 @@
+package secret
+
+import (
+	"bytes"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Standard data keys of TLS secrets, shared by the helpers reading the certificate material of a secret.
+const (
+	// TLSCertKey is the data key holding the PEM encoded certificate chain, see corev1.TLSCertKey.
+	TLSCertKey = corev1.TLSCertKey
+	// TLSPrivateKeyKey is the data key holding the PEM encoded private key, see corev1.TLSPrivateKeyKey.
+	TLSPrivateKeyKey = corev1.TLSPrivateKeyKey
+	// TLSCABundleKey is the data key holding the PEM encoded CA bundle of a TLS secret, by convention ca.crt.
+	// It is not defined by the kubernetes.io/tls secret type, which only requires tls.crt and tls.key.
+	TLSCABundleKey = "ca.crt"
+)
+
+// CertMaterialChanged returns true if the certificate, the private key or the CA bundle
+// (tls.crt, tls.key and ca.crt) differ between the two secrets. Changes of any other field,
+// like annotations, labels or the resource version, are ignored. dataKeys replaces the compared
+// data keys, e.g. for certificates and keys stored under other keys than tls.crt and tls.key.
+// A nil secret only equals another nil secret.
+func CertMaterialChanged(old, new *corev1.Secret, dataKeys ...string) bool {
+	if old == nil || new == nil {
+		return old != new
+	}
+	if len(dataKeys) == 0 {
+		dataKeys = minimalSecretKeys
+	}
+	for _, key := range dataKeys {
+		oldValue, oldExists := old.Data[key]
+		newValue, newExists := new.Data[key]
+		if oldExists != newExists || !bytes.Equal(oldValue, newValue) {
+			return true
+		}
+	}
+	return false
+}
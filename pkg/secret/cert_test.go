@@ -0,0 +1,124 @@
+package secret
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCertMaterialChanged(t *testing.T) {
+	newSecret := func(resourceVersion string, annotations map[string]string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns", ResourceVersion: resourceVersion, Annotations: annotations},
+			Data:       data,
+		}
+	}
+	data := map[string][]byte{
+		corev1.TLSCertKey:       []byte("cert"),
+		corev1.TLSPrivateKeyKey: []byte("key"),
+		"ca.crt":                []byte("ca"),
+		"other":                 []byte("other"),
+	}
+	with := func(key string, value []byte) map[string][]byte {
+		changed := map[string][]byte{}
+		for k, v := range data {
+			changed[k] = v
+		}
+		if value == nil {
+			delete(changed, key)
+		} else {
+			changed[key] = value
+		}
+		return changed
+	}
+
+	scenarios := []struct {
+		name          string
+		old, new      *corev1.Secret
+		dataKeys      []string
+		expectChanged bool
+	}{
+		{
+			name: "identical data",
+			old:  newSecret("1", nil, data),
+			new:  newSecret("1", nil, with(corev1.TLSCertKey, []byte("cert"))),
+		},
+		{
+			name: "changed annotations, labels and resource version only",
+			old:  newSecret("1", nil, data),
+			new:  newSecret("2", map[string]string{"a": "b"}, data),
+		},
+		{
+			name: "changed non-certificate key",
+			old:  newSecret("1", nil, data),
+			new:  newSecret("2", nil, with("other", []byte("changed"))),
+		},
+		{
+			name:          "changed certificate",
+			old:           newSecret("1", nil, data),
+			new:           newSecret("2", nil, with(corev1.TLSCertKey, []byte("new cert"))),
+			expectChanged: true,
+		},
+		{
+			name:          "changed private key",
+			old:           newSecret("1", nil, data),
+			new:           newSecret("2", nil, with(corev1.TLSPrivateKeyKey, []byte("new key"))),
+			expectChanged: true,
+		},
+		{
+			name:          "removed CA bundle",
+			old:           newSecret("1", nil, data),
+			new:           newSecret("2", nil, with("ca.crt", nil)),
+			expectChanged: true,
+		},
+		{
+			name:          "empty value added",
+			old:           newSecret("1", nil, with("ca.crt", nil)),
+			new:           newSecret("2", nil, with("ca.crt", []byte{})),
+			expectChanged: true,
+		},
+		{
+			name:          "nil secret",
+			old:           nil,
+			new:           newSecret("1", nil, data),
+			expectChanged: true,
+		},
+		{
+			name: "both nil",
+		},
+		{
+			name:          "changed given key",
+			old:           newSecret("1", nil, data),
+			new:           newSecret("2", nil, with("other", []byte("changed"))),
+			dataKeys:      []string{"other"},
+			expectChanged: true,
+		},
+		{
+			name:     "changed certificate not in the given keys",
+			old:      newSecret("1", nil, data),
+			new:      newSecret("2", nil, with(corev1.TLSCertKey, []byte("new cert"))),
+			dataKeys: []string{"other"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if got := CertMaterialChanged(s.old, s.new, s.dataKeys...); got != s.expectChanged {
+				t.Errorf("expected changed %t, got %t", s.expectChanged, got)
+			}
+		})
+	}
+}
+
+func TestTLSKeys(t *testing.T) {
+	for key, expected := range map[string]string{
+		TLSCertKey:       "tls.crt",
+		TLSPrivateKeyKey: "tls.key",
+		TLSCABundleKey:   "ca.crt",
+	} {
+		if key != expected {
+			t.Errorf("expected key %q, got %q", expected, key)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package secret
+
+import "errors"
+
+var (
+	// ErrRegistrationKeyMismatch is returned when a handler registration is used with a monitor of a different key.
+	ErrRegistrationKeyMismatch = errors.New("handler registration key does not match the monitor key")
+
+	// ErrEmptySecretName is returned when an empty secret name is provided.
+	ErrEmptySecretName = errors.New("secret name must not be empty")
+
+	// ErrInvalidSecretName is returned when a secret name is not a valid DNS subdomain.
+	ErrInvalidSecretName = errors.New("invalid secret name")
+
+	// ErrEmptyNamespace is returned when an empty namespace is provided.
+	ErrEmptyNamespace = errors.New("namespace must not be empty")
+
+	// ErrTooManyHandlers is returned when adding a handler would exceed the maximum number of handlers of a secret.
+	ErrTooManyHandlers = errors.New("too many handlers for secret")
+
+	// ErrInformerAlreadyStarted is returned when the InformerFactoryFunc of a namespaced monitor returns a factory
+	// whose secrets informer was already started, by the monitor or by the caller, e.g. a cached factory.
+	ErrInformerAlreadyStarted = errors.New("secrets informer was already started")
+
+	// ErrCacheNotSynced is returned when the informer cache could not be synced.
+	ErrCacheNotSynced = errors.New("failed waiting for cache sync")
+
+	// ErrSecretNotFound is returned when the secret is not present in the cache.
+	// Errors wrapping it also satisfy apierrors.IsNotFound.
+	ErrSecretNotFound = errors.New("secret not found in cache")
+
+	// ErrSecretKeyMismatch is returned when the cache holds a secret whose namespace or name differs from the monitored key.
+	ErrSecretKeyMismatch = errors.New("cached secret does not match the monitored key")
+
+	// ErrSecretDeleted is returned when the secret is not present in the cache because its deletion was observed.
+	ErrSecretDeleted = errors.New("secret was deleted")
+
+	// ErrSecretsAPIUnavailable is returned when discovery doesn't report the v1 secrets resource as served.
+	ErrSecretsAPIUnavailable = errors.New("secrets API is unavailable")
+
+	// ErrNamespaceNotAllowed is returned when a handler is added for a secret outside of the allowed namespaces.
+	ErrNamespaceNotAllowed = errors.New("namespace is not allowed")
+
+	// ErrCircuitOpen is returned when no informer is created because too many informers recently failed to start.
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+)
@@ -5,6 +5,7 @@ import (
 
 	"github.com/openshift/library-go/pkg/secret"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -13,8 +14,17 @@ type SecretMonitor struct {
 	Secret *corev1.Secret
 }
 
-func (sm *SecretMonitor) AddSecretEventHandler(_ context.Context, _ string, _ string, _ cache.ResourceEventHandler) (secret.SecretEventHandlerRegistration, error) {
-	return nil, sm.Err
+func (sm *SecretMonitor) AddSecretEventHandler(_ context.Context, namespace string, secretName string, _ cache.ResourceEventHandler) (secret.SecretEventHandlerRegistration, error) {
+	if sm.Err != nil {
+		return nil, sm.Err
+	}
+	return &SecretEventHandlerRegistration{
+		Key:    secret.NewObjectKey(namespace, secretName),
+		Secret: sm.Secret,
+	}, nil
+}
+func (sm *SecretMonitor) AddSecretEventHandlerWithInformer(ctx context.Context, key secret.ObjectKey, _ cache.SharedInformer, handler cache.ResourceEventHandler) (secret.SecretEventHandlerRegistration, error) {
+	return sm.AddSecretEventHandler(ctx, key.Namespace, key.Name, handler)
 }
 func (sm *SecretMonitor) RemoveSecretEventHandler(_ secret.SecretEventHandlerRegistration) error {
 	return sm.Err
@@ -22,3 +32,68 @@ func (sm *SecretMonitor) RemoveSecretEventHandler(_ secret.SecretEventHandlerReg
 func (sm *SecretMonitor) GetSecret(_ context.Context, _ secret.SecretEventHandlerRegistration) (*corev1.Secret, error) {
 	return sm.Secret, sm.Err
 }
+func (sm *SecretMonitor) GetSecretByKey(_ secret.ObjectKey) (*corev1.Secret, error) {
+	return sm.Secret, sm.Err
+}
+func (sm *SecretMonitor) ListMonitoredKeys() []secret.ObjectKey {
+	return nil
+}
+func (sm *SecretMonitor) ShutdownNamespace(_ string) error {
+	return sm.Err
+}
+func (sm *SecretMonitor) ListCachedSecrets() []secret.SecretSummary {
+	return nil
+}
+func (sm *SecretMonitor) ReregisterAll() error {
+	return sm.Err
+}
+func (sm *SecretMonitor) Reregister(_ secret.ObjectKey) error {
+	return sm.Err
+}
+func (sm *SecretMonitor) SetKubeClient(_ kubernetes.Interface) {
+}
+func (sm *SecretMonitor) ByIndex(_, _ string) ([]*corev1.Secret, error) {
+	if sm.Err != nil || sm.Secret == nil {
+		return nil, sm.Err
+	}
+	return []*corev1.Secret{sm.Secret}, nil
+}
+func (sm *SecretMonitor) ListHandlers(_ secret.ObjectKey) []secret.SecretEventHandlerRegistration {
+	return nil
+}
+
+type SecretEventHandlerRegistration struct {
+	Key    secret.ObjectKey
+	Err    error
+	Secret *corev1.Secret
+
+	// NotSynced makes HasSynced return false.
+	NotSynced bool
+
+	// Adds, Updates and Deletes are returned by EventCounts.
+	Adds    int64
+	Updates int64
+	Deletes int64
+
+	// SyncResourceVersion is returned by LastSyncResourceVersion.
+	SyncResourceVersion string
+}
+
+func (r *SecretEventHandlerRegistration) HasSynced() bool {
+	return !r.NotSynced
+}
+func (r *SecretEventHandlerRegistration) GetKey() secret.ObjectKey {
+	return r.Key
+}
+func (r *SecretEventHandlerRegistration) GetHandler() cache.ResourceEventHandlerRegistration {
+	return r
+}
+func (r *SecretEventHandlerRegistration) GetSecret() (*corev1.Secret, error) {
+	return r.Secret, r.Err
+}
+func (r *SecretEventHandlerRegistration) EventCounts() (adds, updates, deletes int64) {
+	return r.Adds, r.Updates, r.Deletes
+}
+func (r *SecretEventHandlerRegistration) LastSyncResourceVersion() string {
+	return r.SyncResourceVersion
+}
@@ -0,0 +1,227 @@
+package secret
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// recoveringHandler wraps a ResourceEventHandler and recovers from panics raised by it,
+// so that a faulty handler of one secret doesn't crash the whole process.
+// Events and panics are logged with the logger of the secret, see loggerForKey.
+type recoveringHandler struct {
+	logger  klog.Logger
+	handler cache.ResourceEventHandler
+}
+
+func newRecoveringHandler(logger klog.Logger, handler cache.ResourceEventHandler) *recoveringHandler {
+	return &recoveringHandler{
+		logger:  logger,
+		handler: handler,
+	}
+}
+
+func (h *recoveringHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	defer h.recover("add")
+	h.logger.V(5).Info("delivering secret event", "event", "add", "isInInitialList", isInInitialList)
+	h.handler.OnAdd(obj, isInInitialList)
+}
+
+func (h *recoveringHandler) OnUpdate(oldObj, newObj interface{}) {
+	defer h.recover("update")
+	h.logger.V(5).Info("delivering secret event", "event", "update")
+	h.handler.OnUpdate(oldObj, newObj)
+}
+
+func (h *recoveringHandler) OnDelete(obj interface{}) {
+	defer h.recover("delete")
+	h.logger.V(5).Info("delivering secret event", "event", "delete")
+	h.handler.OnDelete(obj)
+}
+
+func (h *recoveringHandler) recover(event string) {
+	if r := recover(); r != nil {
+		h.logger.Error(fmt.Errorf("%v", r), "recovered from panic in secret handler", "event", event)
+	}
+}
+
+// deletionTrackingHandler wraps a ResourceEventHandler and records whether the last
+// event was a deletion, before delegating to the wrapped handler.
+type deletionTrackingHandler struct {
+	deleted *atomic.Bool
+	handler cache.ResourceEventHandler
+}
+
+func newDeletionTrackingHandler(deleted *atomic.Bool, handler cache.ResourceEventHandler) *deletionTrackingHandler {
+	return &deletionTrackingHandler{
+		deleted: deleted,
+		handler: handler,
+	}
+}
+
+func (h *deletionTrackingHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	h.deleted.Store(false)
+	h.handler.OnAdd(obj, isInInitialList)
+}
+
+func (h *deletionTrackingHandler) OnUpdate(oldObj, newObj interface{}) {
+	h.deleted.Store(false)
+	h.handler.OnUpdate(oldObj, newObj)
+}
+
+func (h *deletionTrackingHandler) OnDelete(obj interface{}) {
+	h.deleted.Store(true)
+	h.handler.OnDelete(obj)
+}
+
+// eventCounter is a ResourceEventHandler counting the events delivered by an informer.
+// Updates changing the certificate material of a secret, see CertMaterialChanged, are also
+// counted by the secret_monitor_cert_material_changes_total metric.
+type eventCounter struct {
+	adds    atomic.Int64
+	updates atomic.Int64
+	deletes atomic.Int64
+}
+
+func (c *eventCounter) OnAdd(_ interface{}, _ bool) {
+	c.adds.Add(1)
+}
+
+func (c *eventCounter) OnUpdate(oldObj, newObj interface{}) {
+	c.updates.Add(1)
+
+	oldSecret, oldOk := oldObj.(*corev1.Secret)
+	newSecret, newOk := newObj.(*corev1.Secret)
+	if oldOk && newOk && CertMaterialChanged(oldSecret, newSecret) {
+		certMaterialChangesTotal.WithLabelValues(newSecret.Namespace).Inc()
+	}
+}
+
+func (c *eventCounter) OnDelete(_ interface{}) {
+	c.deletes.Add(1)
+}
+
+// counts returns the number of add, update and delete events counted so far.
+func (c *eventCounter) counts() (adds, updates, deletes int64) {
+	return c.adds.Load(), c.updates.Load(), c.deletes.Load()
+}
+
+// keyedEventCounter is a ResourceEventHandler counting the events delivered by an informer per secret.
+// Only the events of tracked secrets, i.e. with registered handlers, are counted.
+type keyedEventCounter struct {
+	lock sync.Mutex
+	// tracked are the keys whose events are counted.
+	tracked  sets.Set[ObjectKey]
+	counters map[ObjectKey]*eventCounter
+}
+
+func newKeyedEventCounter() *keyedEventCounter {
+	return &keyedEventCounter{
+		tracked:  sets.New[ObjectKey](),
+		counters: map[ObjectKey]*eventCounter{},
+	}
+}
+
+func (c *keyedEventCounter) OnAdd(obj interface{}, isInInitialList bool) {
+	if counter, _ := c.counterOf(obj); counter != nil {
+		counter.OnAdd(obj, isInInitialList)
+	}
+}
+
+func (c *keyedEventCounter) OnUpdate(oldObj, newObj interface{}) {
+	if counter, _ := c.counterOf(newObj); counter != nil {
+		counter.OnUpdate(oldObj, newObj)
+	}
+}
+
+// OnDelete counts the deletion and drops the counter of the secret, the counts of a recreated secret start over.
+func (c *keyedEventCounter) OnDelete(obj interface{}) {
+	if counter, key := c.counterOf(obj); counter != nil {
+		counter.OnDelete(obj)
+
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		delete(c.counters, key)
+	}
+}
+
+// counterOf returns the counter of the secret and its key, creating the counter if needed.
+// Returns a nil counter if obj is not a secret or if its key is not tracked.
+func (c *keyedEventCounter) counterOf(obj interface{}) (*eventCounter, ObjectKey) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return nil, ObjectKey{}
+	}
+	key := NewObjectKey(secret.Namespace, secret.Name)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.tracked.Has(key) {
+		return nil, key
+	}
+	counter, exists := c.counters[key]
+	if !exists {
+		counter = &eventCounter{}
+		c.counters[key] = counter
+	}
+	return counter, key
+}
+
+// track starts counting the events of the key.
+func (c *keyedEventCounter) track(key ObjectKey) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.tracked.Insert(key)
+}
+
+// forget stops counting the events of the key and drops its counter.
+func (c *keyedEventCounter) forget(key ObjectKey) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.tracked.Delete(key)
+	delete(c.counters, key)
+}
+
+// counts returns the number of events counted for the key.
+func (c *keyedEventCounter) counts(key ObjectKey) (adds, updates, deletes int64) {
+	c.lock.Lock()
+	counter, exists := c.counters[key]
+	c.lock.Unlock()
+
+	if !exists {
+		return 0, 0, 0
+	}
+	return counter.counts()
+}
+
+// initialListFilteringHandler wraps a ResourceEventHandler and drops the add events of the initial list of the
+// informer, either because they are delivered separately or because they are skipped, see WithSkipInitialEvents.
+type initialListFilteringHandler struct {
+	handler cache.ResourceEventHandler
+}
+
+func (h *initialListFilteringHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	if isInInitialList {
+		return
+	}
+	h.handler.OnAdd(obj, isInInitialList)
+}
+
+func (h *initialListFilteringHandler) OnUpdate(oldObj, newObj interface{}) {
+	h.handler.OnUpdate(oldObj, newObj)
+}
+
+func (h *initialListFilteringHandler) OnDelete(obj interface{}) {
+	h.handler.OnDelete(obj)
+}
@@ -0,0 +1,231 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// listerSecretMonitor is an implementation of the SecretMonitor which reads the secrets from a lister
+// provided by the caller, and never runs any informer.
+type listerSecretMonitor struct {
+	lister corev1listers.SecretNamespaceLister
+
+	lock sync.Mutex
+	// keys are the registered secrets, with their registrations.
+	keys map[ObjectKey]map[*secretEventHandlerRegistration]struct{}
+}
+
+// NewListerBackedSecretMonitor creates a SecretMonitor reading the secrets from the lister of an informer run by
+// the caller, e.g. from its SharedInformerFactory, so that no additional watch is opened.
+// Handlers are not added to any informer: the caller delivers the events by adding its handlers to its own informer,
+// and must wait for it to sync. Registrations are always synced, and are only used to read the secrets.
+// Secrets of another namespace than the one of the lister are reported with ErrSecretKeyMismatch or ErrSecretNotFound.
+func NewListerBackedSecretMonitor(lister corev1listers.SecretNamespaceLister) SecretMonitor {
+	return &listerSecretMonitor{
+		lister: lister,
+		keys:   map[ObjectKey]map[*secretEventHandlerRegistration]struct{}{},
+	}
+}
+
+// AddSecretEventHandler registers the secret without adding the handler anywhere, see NewListerBackedSecretMonitor.
+func (s *listerSecretMonitor) AddSecretEventHandler(_ context.Context, namespace, secretName string, handler cache.ResourceEventHandler) (SecretEventHandlerRegistration, error) {
+	key := NewObjectKey(namespace, secretName)
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("nil handler is provided")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	registration := &secretEventHandlerRegistration{
+		handle:    syncedRegistration{},
+		handler:   handler,
+		objectKey: key,
+		owner:     s,
+	}
+	if s.keys[key] == nil {
+		s.keys[key] = map[*secretEventHandlerRegistration]struct{}{}
+	}
+	s.keys[key][registration] = struct{}{}
+	klog.V(5).Info("lister backed secret registered", " item key ", key)
+
+	return registration, nil
+}
+
+// AddSecretEventHandlerWithInformer is not supported, since the secrets are read from the lister.
+func (s *listerSecretMonitor) AddSecretEventHandlerWithInformer(_ context.Context, key ObjectKey, _ cache.SharedInformer, _ cache.ResourceEventHandler) (SecretEventHandlerRegistration, error) {
+	return nil, fmt.Errorf("lister backed secret monitor doesn't support a custom informer for item key %v", key)
+}
+
+// RemoveSecretEventHandler unregisters the secret of the registration.
+func (s *listerSecretMonitor) RemoveSecretEventHandler(handlerRegistration SecretEventHandlerRegistration) error {
+	if handlerRegistration == nil {
+		return fmt.Errorf("nil secret handler registration is provided")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	key := handlerRegistration.GetKey()
+	if len(s.keys[key]) == 0 {
+		return fmt.Errorf("secret monitor already removed for item key %v", key)
+	}
+	registration, ok := handlerRegistration.(*secretEventHandlerRegistration)
+	if _, tracked := s.keys[key][registration]; !ok || !tracked {
+		return fmt.Errorf("handler registration not found in monitor for item key %v", key)
+	}
+	delete(s.keys[key], registration)
+	if len(s.keys[key]) == 0 {
+		delete(s.keys, key)
+	}
+	return nil
+}
+
+// GetSecret reads the secret of the registration from the lister.
+func (s *listerSecretMonitor) GetSecret(_ context.Context, handlerRegistration SecretEventHandlerRegistration) (*corev1.Secret, error) {
+	if handlerRegistration == nil {
+		return nil, fmt.Errorf("nil secret handler registration is provided")
+	}
+	return s.getSecret(handlerRegistration.GetKey())
+}
+
+// GetSecretByKey reads the secret from the lister. Returns an error if the secret is not registered.
+func (s *listerSecretMonitor) GetSecretByKey(key ObjectKey) (*corev1.Secret, error) {
+	s.lock.Lock()
+	registered := len(s.keys[key]) > 0
+	s.lock.Unlock()
+
+	if !registered {
+		return nil, fmt.Errorf("secret monitor doesn't exist for key %v", key)
+	}
+	return s.getSecret(key)
+}
+
+// ListMonitoredKeys returns the keys of the registered secrets.
+func (s *listerSecretMonitor) ListMonitoredKeys() []ObjectKey {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	keys := make([]ObjectKey, 0, len(s.keys))
+	for key := range s.keys {
+		keys = append(keys, key)
+	}
+	sortObjectKeys(keys)
+
+	return keys
+}
+
+// ShutdownNamespace unregisters every secret of the namespace.
+func (s *listerSecretMonitor) ShutdownNamespace(namespace string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for key := range s.keys {
+		if key.Namespace == namespace {
+			delete(s.keys, key)
+		}
+	}
+	return nil
+}
+
+// ListCachedSecrets returns a summary of every registered secret sorted by namespace and name.
+func (s *listerSecretMonitor) ListCachedSecrets() []SecretSummary {
+	s.lock.Lock()
+	keys := make([]ObjectKey, 0, len(s.keys))
+	for key := range s.keys {
+		keys = append(keys, key)
+	}
+	s.lock.Unlock()
+
+	summaries := make([]SecretSummary, 0, len(keys))
+	for _, key := range keys {
+		summary := SecretSummary{Namespace: key.Namespace, Name: key.Name, Synced: true}
+		if secret, err := s.getSecret(key); err == nil {
+			summary.Type = string(secret.Type)
+			summary.ResourceVersion = secret.ResourceVersion
+		}
+		summaries = append(summaries, summary)
+	}
+	sortSecretSummaries(summaries)
+
+	return summaries
+}
+
+// ReregisterAll does nothing, the informer of the lister is run by the caller.
+func (s *listerSecretMonitor) ReregisterAll() error {
+	return nil
+}
+
+// Reregister does nothing, the informer of the lister is owned by the caller.
+func (s *listerSecretMonitor) Reregister(key ObjectKey) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.keys[key]) == 0 {
+		return fmt.Errorf("secret monitor doesn't exist for key %v", key)
+	}
+	return nil
+}
+
+// SetKubeClient does nothing, the secrets are read from the lister.
+func (s *listerSecretMonitor) SetKubeClient(_ kubernetes.Interface) {
+}
+
+// ListHandlers returns the registrations of the secret.
+func (s *listerSecretMonitor) ListHandlers(key ObjectKey) []SecretEventHandlerRegistration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.keys[key]) == 0 {
+		return nil
+	}
+	return listRegistrations(s.keys[key], key)
+}
+
+// ByIndex is not supported, the lister doesn't expose the indexer of its informer.
+func (s *listerSecretMonitor) ByIndex(indexName, _ string) ([]*corev1.Secret, error) {
+	return nil, fmt.Errorf("lister backed secret monitor doesn't support index %s", indexName)
+}
+
+// eventCounts implements registrationOwner. No event is delivered by the monitor.
+func (s *listerSecretMonitor) eventCounts(_ ObjectKey) (adds, updates, deletes int64) {
+	return 0, 0, 0
+}
+
+// lastSyncResourceVersion implements registrationOwner. It is unknown, the informer of the lister is run by the caller.
+func (s *listerSecretMonitor) lastSyncResourceVersion(_ ObjectKey) string {
+	return ""
+}
+
+// getSecret implements registrationOwner.
+func (s *listerSecretMonitor) getSecret(key ObjectKey) (*corev1.Secret, error) {
+	secret, err := s.lister.Get(key.Name)
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%w: %w", ErrSecretNotFound, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSecretKey(secret, key); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// syncedRegistration is a cache.ResourceEventHandlerRegistration which is always synced.
+type syncedRegistration struct{}
+
+func (syncedRegistration) HasSynced() bool {
+	return true
+}
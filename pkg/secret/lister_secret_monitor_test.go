@@ -0,0 +1,88 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestListerBackedSecretMonitor(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(fakeSecret("ns", "secret")); err != nil {
+		t.Fatal(err)
+	}
+	sm := NewListerBackedSecretMonitor(corev1listers.NewSecretLister(indexer).Secrets("ns"))
+
+	h, err := sm.AddSecretEventHandler(context.TODO(), "ns", "secret", cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.HasSynced() {
+		t.Error("expected the registration to be synced")
+	}
+	gotSecret, err := sm.GetSecret(context.TODO(), h)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotSecret.Namespace != "ns" || gotSecret.Name != "secret" {
+		t.Errorf("expected secret ns/secret, got %s/%s", gotSecret.Namespace, gotSecret.Name)
+	}
+
+	// changes of the lister are read
+	updated := fakeSecret("ns", "secret")
+	updated.ResourceVersion = "2"
+	if err := indexer.Update(updated); err != nil {
+		t.Fatal(err)
+	}
+	if gotSecret, err = h.GetSecret(); err != nil || gotSecret.ResourceVersion != "2" {
+		t.Errorf("expected the updated secret, got %v, %v", gotSecret, err)
+	}
+
+	missing, err := sm.AddSecretEventHandler(context.TODO(), "ns", "missing", cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.GetSecret(context.TODO(), missing); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("expected %v, got %v", ErrSecretNotFound, err)
+	}
+
+	if cached := sm.ListCachedSecrets(); len(cached) != 2 || cached[1].ResourceVersion != "2" {
+		t.Errorf("expected 2 registered secrets, got %v", cached)
+	}
+	if err := sm.RemoveSecretEventHandler(h); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.RemoveSecretEventHandler(h); err == nil {
+		t.Error("expected an error removing the handler twice")
+	}
+}
+
+func TestListerBackedSecretMonitorListHandlers(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	sm := NewListerBackedSecretMonitor(corev1listers.NewSecretLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})).Secrets("ns"))
+
+	var registrations []SecretEventHandlerRegistration
+	for i := 0; i < 2; i++ {
+		h, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		registrations = append(registrations, h)
+	}
+	if handlers := sm.ListHandlers(key); len(handlers) != 2 {
+		t.Fatalf("expected 2 handlers, got %d", len(handlers))
+	}
+
+	if err := sm.RemoveSecretEventHandler(registrations[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.RemoveSecretEventHandler(registrations[0]); err == nil {
+		t.Error("expected an error removing a handler twice")
+	}
+	if handlers := sm.ListHandlers(key); len(handlers) != 1 || handlers[0] != registrations[1] {
+		t.Errorf("expected the remaining handler to be listed, got %v", handlers)
+	}
+}
@@ -0,0 +1,37 @@
+package secret
+
+import (
+	compbasemetrics "k8s.io/component-base/metrics"
+)
+
+type registerables []compbasemetrics.Registerable
+
+var (
+	circuitBreakerState = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "secret_monitor_circuit_breaker_state",
+			Help:           "State of the secret monitor circuit breaker: 0 closed, 1 open, 2 half-open. Partitioned by monitor.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"monitor"},
+	)
+
+	certMaterialChangesTotal = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "secret_monitor_cert_material_changes_total",
+			Help:           "Number of updates of monitored secrets changing their certificate, private key or CA bundle. Partitioned by namespace.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"namespace"},
+	)
+
+	metrics = registerables{
+		circuitBreakerState,
+		certMaterialChangesTotal,
+	}
+)
+
+// RegisterMetrics registers the secret monitor metrics with the given register function.
+func RegisterMetrics(registerFn func(...compbasemetrics.Registerable)) {
+	registerFn(metrics...)
+}
@@ -0,0 +1,63 @@
+package secret
+
+import (
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+var registerMetricsOnce sync.Once
+
+// registerTestMetrics registers the metrics with a test registry, so that they report values.
+// Returns true on the first call, which actually registers them.
+func registerTestMetrics() bool {
+	registered := false
+	registerMetricsOnce.Do(func() {
+		RegisterMetrics(compbasemetrics.NewKubeRegistry().MustRegister)
+		registered = true
+	})
+	return registered
+}
+
+func TestCertMaterialChangesTotal(t *testing.T) {
+	registerTestMetrics()
+	// the namespace is unique to the test, since the metric is global
+	namespace := "cert-material-changes"
+	newSecret := func(cert string, labels map[string]string) *corev1.Secret {
+		secret := fakeSecret(namespace, "secret")
+		secret.Labels = labels
+		secret.Data = map[string][]byte{corev1.TLSCertKey: []byte(cert), "other": []byte(cert)}
+		return secret
+	}
+
+	counter := &eventCounter{}
+	updates := []struct {
+		old, new *corev1.Secret
+	}{
+		// certificate changes
+		{newSecret("cert1", nil), newSecret("cert2", nil)},
+		{newSecret("cert2", nil), newSecret("cert3", map[string]string{"rotated": "true"})},
+		// other changes
+		{newSecret("cert3", nil), newSecret("cert3", map[string]string{"rotated": "true"})},
+		{newSecret("cert3", nil), newSecret("cert3", nil)},
+	}
+	for _, update := range updates {
+		counter.OnUpdate(update.old, update.new)
+	}
+	// not secrets
+	counter.OnUpdate(nil, nil)
+
+	value, err := testutil.GetCounterMetricValue(certMaterialChangesTotal.WithLabelValues(namespace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 2 {
+		t.Errorf("expected 2 certificate material changes, got %v", value)
+	}
+	if _, updates, _ := counter.counts(); updates != 5 {
+		t.Errorf("expected 5 updates to be counted, got %d", updates)
+	}
+}
@@ -3,10 +3,17 @@ package secret
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 )
 
 // ObjectKey represents the unique identifier for a resource, used to access it in the cache.
@@ -17,6 +24,22 @@ type ObjectKey struct {
 	Name string
 }
 
+// Validate returns ErrEmptyNamespace or ErrEmptySecretName if the namespace or the name of the key is empty,
+// and ErrInvalidSecretName if the name is not a valid DNS subdomain, which no secret can be named after.
+// Secrets are always monitored within a namespace, so there is no cluster-wide key.
+func (k ObjectKey) Validate() error {
+	if len(k.Namespace) == 0 {
+		return ErrEmptyNamespace
+	}
+	if len(k.Name) == 0 {
+		return ErrEmptySecretName
+	}
+	if msgs := validation.IsDNS1123Subdomain(k.Name); len(msgs) > 0 {
+		return fmt.Errorf("%w %q: %s", ErrInvalidSecretName, k.Name, strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
 // singleItemMonitor monitors a single resource using a SharedInformer.
 type singleItemMonitor struct {
 	key      ObjectKey
@@ -24,29 +47,101 @@ type singleItemMonitor struct {
 	lock     sync.Mutex
 	stopped  bool
 	stopCh   chan struct{}
+
+	// startupDelay returns how long to wait before running the informer, nil means no delay.
+	startupDelay func() time.Duration
+
+	// clock is used to wait for the startup delay.
+	clock clock.Clock
+
+	// onSynced is invoked once every time an informer of the monitor syncs, nil means no callback.
+	onSynced func(key ObjectKey)
+
+	// onExited is invoked when the informer exits without being stopped, nil means no callback.
+	onExited func(key ObjectKey)
+
+	// exited is true if the informer exited without being stopped. The monitor is stopped when it happens.
+	exited bool
+
+	// syncPollInterval is how often informers are polled while waiting for them to sync, zero means the default.
+	syncPollInterval time.Duration
+
+	// syncWaiter waits for the informers to sync, nil means waitForSync. It is the syncWaiter of the secretMonitor.
+	syncWaiter syncWaiter
+
+	// lastObservedDeleted is true if the last event delivered to the handlers was a deletion.
+	// It is updated before the handlers are invoked.
+	lastObservedDeleted atomic.Bool
+
+	// ctx is the context the informer was started with.
+	ctx context.Context
+
+	// registrations are the active handler registrations, re-added when the informer is replaced.
+	registrations map[*secretEventHandlerRegistration]struct{}
+
+	// events counts the events delivered by the informers of the monitor.
+	events *eventCounter
+
+	// logger logs the events delivered to the handlers, with the key of the item as values.
+	logger klog.Logger
 }
 
-// NewObjectKey creates a new ObjectKey for the given namespace and name.
+// NewObjectKey creates a new ObjectKey for the given namespace and name, trimming their surrounding whitespace,
+// e.g. accidentally copied from a route spec, so that both refer to the same secret as the trimmed ones.
 func NewObjectKey(namespace, name string) ObjectKey {
 	return ObjectKey{
-		Namespace: namespace,
-		Name:      name,
+		Namespace: strings.TrimSpace(namespace),
+		Name:      strings.TrimSpace(name),
 	}
 }
 
+// loggerForKey returns the logger with the namespace and the name of the secret identified by key as values,
+// so that the logs of a secret can be filtered.
+func loggerForKey(logger klog.Logger, key ObjectKey) klog.Logger {
+	return logger.WithValues("namespace", key.Namespace, "secret", key.Name)
+}
+
 // newSingleItemMonitor creates a new singleItemMonitor for the given key and informer.
 func newSingleItemMonitor(key ObjectKey, informer cache.SharedInformer) *singleItemMonitor {
-	return &singleItemMonitor{
-		key:      key,
-		informer: informer,
-		stopped:  true,
-		stopCh:   make(chan struct{}),
+	i := &singleItemMonitor{
+		key:           key,
+		informer:      informer,
+		stopped:       true,
+		stopCh:        make(chan struct{}),
+		ctx:           context.Background(),
+		registrations: map[*secretEventHandlerRegistration]struct{}{},
+		events:        &eventCounter{},
+		clock:         clock.RealClock{},
+		logger:        loggerForKey(klog.Background(), key),
 	}
+	if _, err := informer.AddEventHandler(i.events); err != nil {
+		klog.Errorf("failed to add event counter for item key %v: %v", key, err)
+	}
+	return i
+}
+
+// EventCounts returns the number of add, update and delete events delivered by the informers of the monitor,
+// including the events of the initial list. Replacing the informer doesn't reset the counts.
+func (i *singleItemMonitor) EventCounts() (adds, updates, deletes int64) {
+	return i.events.counts()
 }
 
 // HasSynced returns true if the informer's cache has been successfully synced.
 func (i *singleItemMonitor) HasSynced() bool {
-	return i.informer.HasSynced()
+	return i.currentInformer().HasSynced()
+}
+
+// LastSyncResourceVersion returns the resource version last observed by the current informer of the monitor.
+func (i *singleItemMonitor) LastSyncResourceVersion() string {
+	return i.currentInformer().LastSyncResourceVersion()
+}
+
+// currentInformer returns the informer currently backing the monitor.
+func (i *singleItemMonitor) currentInformer() cache.SharedInformer {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	return i.informer
 }
 
 // StartInformer starts and runs the informer until the provided context is canceled,
@@ -60,23 +155,142 @@ func (i *singleItemMonitor) StartInformer(ctx context.Context) {
 		return
 	}
 
-	go func() {
-		select {
-		case <-ctx.Done():
-			klog.V(5).Info("stopping informer due to context cancellation")
-			if !i.StopInformer() {
-				klog.Error("failed to stop informer")
-			}
-		// this case is required to exit from the goroutine
-		// after normal StopInformer() call i.e when stopCh is closed.
-		case <-i.stopCh:
-		}
-	}()
+	i.ctx = ctx
+	go i.stopOnContextDone(ctx, i.stopCh)
 
 	klog.Info("starting informer")
 	i.stopped = false
 
-	go i.informer.Run(i.stopCh)
+	go i.run(i.informer, i.stopCh)
+	if i.onSynced != nil {
+		go i.notifyOnSynced(i.informer, i.stopCh)
+	}
+}
+
+// notifyOnSynced invokes the onSynced callback once the informer has synced,
+// unless stopCh is closed before.
+func (i *singleItemMonitor) notifyOnSynced(informer cache.SharedInformer, stopCh <-chan struct{}) {
+	if !i.syncWaiter.wait(wait.ContextForChannel(stopCh), i.syncPollInterval, informer.HasSynced) {
+		return
+	}
+	i.onSynced(i.key)
+}
+
+// stopOnContextDone stops the informer when the context is canceled.
+func (i *singleItemMonitor) stopOnContextDone(ctx context.Context, stopCh <-chan struct{}) {
+	select {
+	case <-ctx.Done():
+		klog.V(5).Info("stopping informer due to context cancellation")
+		if !i.StopInformer() {
+			klog.Error("failed to stop informer")
+		}
+	// this case is required to exit from the goroutine
+	// after normal StopInformer() call i.e when stopCh is closed.
+	case <-stopCh:
+	}
+}
+
+// run runs the informer until stopCh is closed, after the optional startup delay.
+func (i *singleItemMonitor) run(informer cache.SharedInformer, stopCh <-chan struct{}) {
+	if i.startupDelay != nil {
+		delay := i.startupDelay()
+		klog.V(5).Info("delaying informer start", " item key ", i.key, " delay ", delay)
+		select {
+		case <-i.clock.After(delay):
+		case <-stopCh:
+			// stopped before the informer was run
+			return
+		}
+	}
+	i.runInformer(informer, stopCh)
+}
+
+// runInformer runs the informer until stopCh is closed. informer.Run is only expected to return once stopCh
+// is closed: if it returns before while the informer still backs the monitor, the monitor is stopped, marked
+// as exited and the onExited callback is invoked.
+func (i *singleItemMonitor) runInformer(informer cache.SharedInformer, stopCh <-chan struct{}) {
+	informer.Run(stopCh)
+
+	select {
+	case <-stopCh:
+		return
+	default:
+	}
+
+	i.lock.Lock()
+	if i.stopped || i.informer != informer {
+		i.lock.Unlock()
+		return
+	}
+	i.stopped = true
+	i.exited = true
+	close(i.stopCh)
+	onExited := i.onExited
+	i.lock.Unlock()
+
+	klog.Error("informer exited unexpectedly", " item key ", i.key)
+	if onExited != nil {
+		onExited(i.key)
+	}
+}
+
+// ReplaceInformer runs a new informer built by newInformer and, once it has synced, moves every
+// handler to it and stops the previous informer. Registrations remain valid across the replacement,
+// and the cache of the previous informer keeps being served until the new one takes over.
+func (i *singleItemMonitor) ReplaceInformer(newInformer func(ctx context.Context) cache.SharedInformer) error {
+	i.lock.Lock()
+	if i.stopped {
+		i.lock.Unlock()
+		return fmt.Errorf("cannot replace informer of stopped monitor for item key %v", i.key)
+	}
+	ctx := i.ctx
+	i.lock.Unlock()
+
+	// sync the new informer without holding the lock, so the current cache keeps being served
+	informer := newInformer(ctx)
+	if _, err := informer.AddEventHandler(i.events); err != nil {
+		return err
+	}
+	stopCh := make(chan struct{})
+	go i.runInformer(informer, stopCh)
+	if !i.syncWaiter.wait(ctx, i.syncPollInterval, informer.HasSynced) {
+		close(stopCh)
+		return fmt.Errorf("%w for item key %v", ErrCacheNotSynced, i.key)
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if i.stopped {
+		close(stopCh)
+		return fmt.Errorf("monitor stopped while replacing informer for item key %v", i.key)
+	}
+
+	handles := make(map[*secretEventHandlerRegistration]cache.ResourceEventHandlerRegistration, len(i.registrations))
+	for r := range i.registrations {
+		handle, err := informer.AddEventHandler(r.handler)
+		if err != nil {
+			close(stopCh)
+			return err
+		}
+		handles[r] = handle
+	}
+	for r, handle := range handles {
+		r.setHandle(handle)
+	}
+
+	previousStopCh := i.stopCh
+	i.informer = informer
+	i.stopCh = stopCh
+	close(previousStopCh)
+	go i.stopOnContextDone(ctx, stopCh)
+	if i.onSynced != nil {
+		// the new informer has already synced
+		go i.onSynced(i.key)
+	}
+
+	klog.Info("informer replaced", " item key ", i.key)
+	return nil
 }
 
 // StopInformer stops the informer.
@@ -94,8 +308,25 @@ func (i *singleItemMonitor) StopInformer() bool {
 	return true
 }
 
+// IsStopped returns true if the informer is not running.
+func (i *singleItemMonitor) IsStopped() bool {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	return i.stopped
+}
+
+// HasExited returns true if the informer exited without being stopped, in which case the monitor is stopped.
+func (i *singleItemMonitor) HasExited() bool {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	return i.exited
+}
+
 // AddEventHandler adds an event handler to the informer and returns
 // secretEventHandlerRegistration after populating objectKey and registration.
+// Panics raised by the handler are recovered and logged, and deletions are tracked.
 func (i *singleItemMonitor) AddEventHandler(handler cache.ResourceEventHandler) (SecretEventHandlerRegistration, error) {
 	i.lock.Lock()
 	defer i.lock.Unlock()
@@ -104,18 +335,43 @@ func (i *singleItemMonitor) AddEventHandler(handler cache.ResourceEventHandler)
 		return nil, fmt.Errorf("cannot add handler %v to already stopped informer", handler)
 	}
 
-	registration, err := i.informer.AddEventHandler(handler)
+	wrapped := newDeletionTrackingHandler(&i.lastObservedDeleted, newRecoveringHandler(i.logger, handler))
+	handle, err := i.informer.AddEventHandler(wrapped)
 	if err != nil {
 		return nil, err
 	}
 
-	return &secretEventHandlerRegistration{
-		ResourceEventHandlerRegistration: registration,
-		objectKey:                        i.key,
-	}, nil
+	registration := &secretEventHandlerRegistration{
+		handle:    handle,
+		handler:   wrapped,
+		objectKey: i.key,
+		owner:     i,
+	}
+	i.registrations[registration] = struct{}{}
+
+	return registration, nil
+}
+
+// eventCounts implements registrationOwner.
+func (i *singleItemMonitor) eventCounts(_ ObjectKey) (adds, updates, deletes int64) {
+	return i.EventCounts()
+}
+
+// lastSyncResourceVersion implements registrationOwner.
+func (i *singleItemMonitor) lastSyncResourceVersion(_ ObjectKey) string {
+	return i.LastSyncResourceVersion()
+}
+
+// getSecret implements registrationOwner.
+func (i *singleItemMonitor) getSecret(key ObjectKey) (*corev1.Secret, error) {
+	if i.IsStopped() {
+		return nil, fmt.Errorf("secret monitor already stopped for item key %v", key)
+	}
+	return getSecretFromMonitor(i)
 }
 
 // RemoveEventHandler removes an event handler from the informer.
+// Returns ErrRegistrationKeyMismatch if the registration doesn't belong to this monitor's key.
 func (i *singleItemMonitor) RemoveEventHandler(handle SecretEventHandlerRegistration) error {
 	i.lock.Lock()
 	defer i.lock.Unlock()
@@ -124,16 +380,55 @@ func (i *singleItemMonitor) RemoveEventHandler(handle SecretEventHandlerRegistra
 		return fmt.Errorf("nil handler registration is provided")
 	}
 
+	// guard against registrations obtained from a different monitor
+	if handle.GetKey() != i.key {
+		return fmt.Errorf("%w: registration key %v, monitor key %v", ErrRegistrationKeyMismatch, handle.GetKey(), i.key)
+	}
+
 	if i.stopped {
 		return fmt.Errorf("can not remove handler %v from stopped informer", handle.GetHandler())
 	}
 
-	return i.informer.RemoveEventHandler(handle.GetHandler())
+	registration, ok := handle.(*secretEventHandlerRegistration)
+	if ok {
+		// registrations of a previous monitor of the same key must not remove handlers of this one
+		if _, tracked := i.registrations[registration]; !tracked {
+			return fmt.Errorf("handler registration not found in monitor for item key %v", i.key)
+		}
+	}
+
+	// the informer returns no error for handlers it doesn't know, e.g. already removed from it
+	if err := i.informer.RemoveEventHandler(handle.GetHandler()); err != nil {
+		return err
+	}
+	if ok {
+		delete(i.registrations, registration)
+	}
+	return nil
+}
+
+// ListHandlers returns the active handler registrations of the monitor.
+func (i *singleItemMonitor) ListHandlers() []SecretEventHandlerRegistration {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	return listRegistrations(i.registrations, i.key)
+}
+
+// listRegistrations returns the registrations with the given key.
+func listRegistrations(registrations map[*secretEventHandlerRegistration]struct{}, key ObjectKey) []SecretEventHandlerRegistration {
+	list := []SecretEventHandlerRegistration{}
+	for registration := range registrations {
+		if registration.objectKey == key {
+			list = append(list, registration)
+		}
+	}
+	return list
 }
 
 // GetItem returns the accumulator being monitored
 // by informer, using keyFunc (namespace/name).
 func (i *singleItemMonitor) GetItem() (item interface{}, exists bool, err error) {
 	keyFunc := i.key.Namespace + "/" + i.key.Name
-	return i.informer.GetStore().GetByKey(keyFunc)
+	return i.currentInformer().GetStore().GetByKey(keyFunc)
 }
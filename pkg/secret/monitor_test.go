@@ -2,6 +2,8 @@ package secret
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -10,6 +12,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
@@ -352,3 +355,293 @@ func TestGetItem(t *testing.T) {
 		})
 	}
 }
+
+func TestAddEventHandlerRecoversPanic(t *testing.T) {
+	key := NewObjectKey("sandbox", "secret")
+	fakeKubeClient := fake.NewSimpleClientset()
+	monitor := newMonitor(context.TODO(), fakeKubeClient, key)
+	monitor.StartInformer(context.TODO())
+	defer monitor.StopInformer()
+	if !cache.WaitForCacheSync(context.TODO().Done(), monitor.HasSynced) {
+		t.Fatal("cache not synced yet")
+	}
+
+	updated := make(chan struct{})
+	_, err := monitor.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			panic("buggy handler")
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			close(updated)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	secret := fakeSecret(key.Namespace, key.Name)
+	if _, err := fakeKubeClient.CoreV1().Secrets(key.Namespace).Create(context.TODO(), secret, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	secret.Data["test"] = []byte{5, 6, 7, 8}
+	if _, err := fakeKubeClient.CoreV1().Secrets(key.Namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for update event after handler panic")
+	}
+}
+
+func TestRemoveEventHandlerKeyMismatch(t *testing.T) {
+	fakeKubeClient := fake.NewSimpleClientset()
+	monitorA := newMonitor(context.TODO(), fakeKubeClient, NewObjectKey("ns", "secretA"))
+	monitorB := newMonitor(context.TODO(), fakeKubeClient, NewObjectKey("ns", "secretB"))
+	for _, monitor := range []*singleItemMonitor{monitorA, monitorB} {
+		monitor.StartInformer(context.TODO())
+		defer monitor.StopInformer()
+		if !cache.WaitForCacheSync(context.TODO().Done(), monitor.HasSynced) {
+			t.Fatal("cache not synced yet")
+		}
+	}
+
+	handlerRegistration, err := monitorA.AddEventHandler(cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if err := monitorB.RemoveEventHandler(handlerRegistration); !errors.Is(err, ErrRegistrationKeyMismatch) {
+		t.Fatalf("expected %v, got %v", ErrRegistrationKeyMismatch, err)
+	}
+	if err := monitorA.RemoveEventHandler(handlerRegistration); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestStartInformerWithDelay(t *testing.T) {
+	delay := 300 * time.Millisecond
+	fakeKubeClient := fake.NewSimpleClientset()
+
+	monitor := newMonitor(context.TODO(), fakeKubeClient, ObjectKey{})
+	monitor.startupDelay = func() time.Duration { return delay }
+	start := time.Now()
+	monitor.StartInformer(context.TODO())
+	defer monitor.StopInformer()
+
+	time.Sleep(delay / 3)
+	if monitor.HasSynced() {
+		t.Fatal("expected informer start to be delayed")
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), monitor.HasSynced) {
+		t.Fatal("cache not synced yet")
+	}
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Errorf("expected informer to sync after %v, synced after %v", delay, elapsed)
+	}
+
+	// stopping is not delayed
+	delayed := newMonitor(context.TODO(), fakeKubeClient, ObjectKey{})
+	delayed.startupDelay = func() time.Duration { return time.Hour }
+	delayed.StartInformer(context.TODO())
+	stopped := make(chan struct{})
+	go func() {
+		delayed.StopInformer()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for delayed informer to stop")
+	}
+}
+
+func TestObjectKeyValidate(t *testing.T) {
+	scenarios := []struct {
+		name      string
+		key       ObjectKey
+		expectErr error
+	}{
+		{
+			name: "valid key",
+			key:  NewObjectKey("ns", "secret"),
+		},
+		{
+			name:      "empty name",
+			key:       NewObjectKey("ns", ""),
+			expectErr: ErrEmptySecretName,
+		},
+		{
+			name:      "empty namespace",
+			key:       NewObjectKey("", "secret"),
+			expectErr: ErrEmptyNamespace,
+		},
+		{
+			name:      "whitespace only name",
+			key:       NewObjectKey("ns", " \t"),
+			expectErr: ErrEmptySecretName,
+		},
+		{
+			name:      "uppercase name",
+			key:       NewObjectKey("ns", "Secret"),
+			expectErr: ErrInvalidSecretName,
+		},
+		{
+			name:      "name with invalid characters",
+			key:       NewObjectKey("ns", "my_secret"),
+			expectErr: ErrInvalidSecretName,
+		},
+		{
+			name:      "name inside whitespace",
+			key:       ObjectKey{Namespace: "ns", Name: " secret "},
+			expectErr: ErrInvalidSecretName,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if err := s.key.Validate(); !errors.Is(err, s.expectErr) {
+				t.Errorf("expected %v, got %v", s.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestEventCounts(t *testing.T) {
+	key := NewObjectKey("namespace", "name")
+	fakeKubeClient := fake.NewSimpleClientset()
+	monitor := newMonitor(context.TODO(), fakeKubeClient, key)
+	monitor.StartInformer(context.TODO())
+	defer monitor.StopInformer()
+	if !cache.WaitForCacheSync(context.TODO().Done(), monitor.HasSynced) {
+		t.Fatal("cache not synced yet")
+	}
+
+	secrets := fakeKubeClient.CoreV1().Secrets(key.Namespace)
+	secret := fakeSecret(key.Namespace, key.Name)
+	if _, err := secrets.Create(context.TODO(), secret, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		secret.Labels = map[string]string{"update": fmt.Sprint(i)}
+		if _, err := secrets.Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := secrets.Delete(context.TODO(), key.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var adds, updates, deletes int64
+	err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(_ context.Context) (bool, error) {
+		adds, updates, deletes = monitor.EventCounts()
+		return deletes == 1, nil
+	})
+	if err != nil {
+		t.Fatalf("timed out waiting for the delete event: %v", err)
+	}
+	if adds != 1 || updates != 3 || deletes != 1 {
+		t.Errorf("expected 1 add, 3 updates and 1 delete, got %d adds, %d updates and %d deletes", adds, updates, deletes)
+	}
+}
+
+func TestLastSyncResourceVersion(t *testing.T) {
+	key := NewObjectKey("namespace", "name")
+	secret := fakeSecret(key.Namespace, key.Name)
+	secret.ResourceVersion = "1"
+	fakeKubeClient := fake.NewSimpleClientset(secret)
+	monitor := newMonitor(context.TODO(), fakeKubeClient, key)
+	monitor.StartInformer(context.TODO())
+	defer monitor.StopInformer()
+	if !cache.WaitForCacheSync(context.TODO().Done(), monitor.HasSynced) {
+		t.Fatal("cache not synced yet")
+	}
+	registration, err := monitor.AddEventHandler(cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	initial := registration.LastSyncResourceVersion()
+
+	// the fake client doesn't bump resource versions
+	secret.ResourceVersion = "2"
+	if _, err := fakeKubeClient.CoreV1().Secrets(key.Namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	err = wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(_ context.Context) (bool, error) {
+		return monitor.LastSyncResourceVersion() == "2", nil
+	})
+	if err != nil {
+		t.Fatalf("expected the resource version to advance from %q to 2, got %q", initial, monitor.LastSyncResourceVersion())
+	}
+	if got := registration.LastSyncResourceVersion(); got != "2" {
+		t.Errorf("expected the registration to report resource version 2, got %q", got)
+	}
+}
+
+// exitingInformer is a SharedInformer whose Run returns without waiting for stopCh to be closed.
+type exitingInformer struct {
+	cache.SharedInformer
+	exit chan struct{}
+}
+
+func (i *exitingInformer) Run(_ <-chan struct{}) {
+	<-i.exit
+}
+
+func TestInformerExited(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	informer := &exitingInformer{
+		SharedInformer: fakeSecretInformer(context.TODO(), fake.NewSimpleClientset(), key.Namespace, key.Name),
+		exit:           make(chan struct{}),
+	}
+	exited := make(chan ObjectKey, 1)
+	monitor := newSingleItemMonitor(key, informer)
+	monitor.onExited = func(k ObjectKey) {
+		exited <- k
+	}
+	monitor.StartInformer(context.TODO())
+	if monitor.HasExited() || monitor.IsStopped() {
+		t.Fatal("expected the informer to be running")
+	}
+
+	close(informer.exit)
+	select {
+	case k := <-exited:
+		if k != key {
+			t.Errorf("expected callback for %v, got %v", key, k)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the exited callback")
+	}
+	if !monitor.HasExited() || !monitor.IsStopped() {
+		t.Error("expected the monitor to be stopped and marked as exited")
+	}
+	if _, err := monitor.getSecret(key); err == nil {
+		t.Error("expected error getting the secret of an exited monitor")
+	}
+
+	// a stopped informer doesn't exit unexpectedly
+	stopped := newMonitor(context.TODO(), fake.NewSimpleClientset(), key)
+	stopped.onExited = func(k ObjectKey) {
+		exited <- k
+	}
+	stopped.StartInformer(context.TODO())
+	stopped.StopInformer()
+	select {
+	case k := <-exited:
+		t.Errorf("unexpected exited callback for %v", k)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if stopped.HasExited() {
+		t.Error("expected the stopped monitor not to be marked as exited")
+	}
+}
+
+func TestNewObjectKeyTrimsWhitespace(t *testing.T) {
+	if key := NewObjectKey(" ns\t", "secret \n"); key != (ObjectKey{Namespace: "ns", Name: "secret"}) {
+		t.Errorf("expected the namespace and the name to be trimmed, got %#v", key)
+	}
+}
@@ -0,0 +1,553 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// InformerFactoryFunc returns the SharedInformerFactory the secrets informer of a namespace is taken from.
+// It must return a new factory on every call, which is not started by the caller: the secrets informer is run
+// and stopped by the monitor, and a stopped informer can't be run again. Returning a cached or shared factory
+// fails with ErrInformerAlreadyStarted.
+type InformerFactoryFunc func(namespace string) informers.SharedInformerFactory
+
+// namespaceInformer is a secrets informer shared by all the handlers of one namespace.
+type namespaceInformer struct {
+	namespace   string
+	informer    cache.SharedIndexInformer
+	lister      corev1listers.SecretNamespaceLister
+	lock        sync.Mutex
+	stopped     bool
+	stopCh      chan struct{}
+	numHandlers int
+
+	// registrations are the active handler registrations, re-added when the informer is recreated.
+	registrations map[*secretEventHandlerRegistration]struct{}
+
+	// events counts the events delivered by the informers of the namespace, per secret.
+	events *keyedEventCounter
+}
+
+// namespacedSecretMonitor is an implementation of the SecretMonitor which
+// uses a single secrets informer per namespace, and filters secrets by name.
+type namespacedSecretMonitor struct {
+	newFactory InformerFactoryFunc
+	lock       sync.RWMutex
+	namespaces map[string]*namespaceInformer
+
+	// syncWaiter waits for the informers to sync, nil means waitForSync. It is only set by tests.
+	syncWaiter syncWaiter
+}
+
+// NewNamespacedSecretMonitor creates a SecretMonitor which shares one secrets informer across all the
+// handlers of a namespace, trading a broader cache for fewer watches.
+// newFactory is called whenever the informer of a namespace needs to be (re)created, and must return a new
+// factory every time, see InformerFactoryFunc.
+// Indexers used by ByIndex can be added to the secrets informer of the factory before returning it.
+func NewNamespacedSecretMonitor(newFactory InformerFactoryFunc) SecretMonitor {
+	return &namespacedSecretMonitor{
+		newFactory: newFactory,
+		namespaces: map[string]*namespaceInformer{},
+	}
+}
+
+// AddSecretEventHandler adds a secret event handler to the informer of the namespace,
+// starting the informer if not already running. Only events of the given secret are delivered to the handler.
+func (s *namespacedSecretMonitor) AddSecretEventHandler(ctx context.Context, namespace, secretName string, handler cache.ResourceEventHandler) (SecretEventHandlerRegistration, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if handler == nil {
+		return nil, fmt.Errorf("nil handler is provided")
+	}
+
+	key := NewObjectKey(namespace, secretName)
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	namespace = key.Namespace
+
+	n, exists := s.namespaces[namespace]
+	if !exists {
+		events := newKeyedEventCounter()
+		// count the initial add of the secret, delivered while the informer starts
+		events.track(key)
+		informer, lister, stopCh, err := startNamespaceInformer(ctx, s.newFactory, s.syncWaiter, namespace, events)
+		if err != nil {
+			return nil, err
+		}
+		n = &namespaceInformer{
+			namespace:     namespace,
+			informer:      informer,
+			lister:        lister,
+			stopCh:        stopCh,
+			registrations: map[*secretEventHandlerRegistration]struct{}{},
+			events:        events,
+		}
+		s.namespaces[namespace] = n
+		klog.Info("namespace secret informer started", " namespace ", namespace)
+	}
+
+	registration, err := n.addEventHandler(key, handler)
+	if err != nil {
+		return nil, err
+	}
+	n.numHandlers += 1
+	klog.Info("secret handler added", " item key ", key)
+
+	return registration, nil
+}
+
+// SetKubeClient replaces the InformerFactoryFunc of the monitor with one building
+// namespaced factories from the given client. Running namespace informers keep the previous client.
+func (s *namespacedSecretMonitor) SetKubeClient(kubeClient kubernetes.Interface) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.newFactory = func(namespace string) informers.SharedInformerFactory {
+		return informers.NewSharedInformerFactoryWithOptions(kubeClient, 0, informers.WithNamespace(namespace))
+	}
+	klog.Info("namespaced secret monitor client replaced")
+}
+
+// waitForSync waits for hasSynced with the syncWaiter of the monitor, see waitForSync.
+func (s *namespacedSecretMonitor) waitForSync(ctx context.Context, hasSynced cache.InformerSynced) bool {
+	return s.syncWaiter.wait(ctx, defaultSyncPollInterval, hasSynced)
+}
+
+// AddSecretEventHandlerWithInformer is not supported, since the informer of a namespace
+// is built from the InformerFactoryFunc and shared by all the secrets of the namespace.
+func (s *namespacedSecretMonitor) AddSecretEventHandlerWithInformer(_ context.Context, key ObjectKey, _ cache.SharedInformer, _ cache.ResourceEventHandler) (SecretEventHandlerRegistration, error) {
+	return nil, fmt.Errorf("namespaced secret monitor doesn't support a custom informer for item key %v", key)
+}
+
+// startNamespaceInformer creates and runs a new secrets informer for the namespace, and waits for its first sync
+// with waiter. The events delivered by the informer are counted by events.
+func startNamespaceInformer(ctx context.Context, newFactory InformerFactoryFunc, waiter syncWaiter, namespace string, events *keyedEventCounter) (cache.SharedIndexInformer, corev1listers.SecretNamespaceLister, chan struct{}, error) {
+	secretsInformer := newFactory(namespace).Core().V1().Secrets()
+	informer := secretsInformer.Informer()
+	// The watch error handler can only be set before the informer is started, which detects the informers
+	// of factories reused by the InformerFactoryFunc, or started by the caller.
+	if err := informer.SetWatchErrorHandler(cache.DefaultWatchErrorHandler); err != nil {
+		return nil, nil, nil, fmt.Errorf("%w for namespace %s: %w", ErrInformerAlreadyStarted, namespace, err)
+	}
+	if _, err := informer.AddEventHandler(events); err != nil {
+		return nil, nil, nil, err
+	}
+	stopCh := make(chan struct{})
+	go informer.Run(stopCh)
+
+	// wait for first sync
+	if !waiter.wait(ctx, defaultSyncPollInterval, informer.HasSynced) {
+		close(stopCh)
+		return nil, nil, nil, fmt.Errorf("%w for namespace %s", ErrCacheNotSynced, namespace)
+	}
+
+	return informer, secretsInformer.Lister().Secrets(namespace), stopCh, nil
+}
+
+// ReregisterAll recreates the informer of every namespace, preserving its handlers.
+func (s *namespacedSecretMonitor) ReregisterAll() error {
+	s.lock.RLock()
+	namespaces := make([]*namespaceInformer, 0, len(s.namespaces))
+	for _, n := range s.namespaces {
+		namespaces = append(namespaces, n)
+	}
+	newFactory := s.newFactory
+	s.lock.RUnlock()
+
+	var errs []error
+	for _, n := range namespaces {
+		if err := n.recreateInformer(newFactory, s.syncWaiter); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Reregister recreates the informer of the namespace of the secret, which is shared by every secret of the namespace.
+func (s *namespacedSecretMonitor) Reregister(key ObjectKey) error {
+	s.lock.RLock()
+	n, exists := s.namespaces[key.Namespace]
+	newFactory := s.newFactory
+	s.lock.RUnlock()
+
+	if !exists || !n.hasRegistration(key) {
+		return fmt.Errorf("secret monitor doesn't exist for key %v", key)
+	}
+	return n.recreateInformer(newFactory, s.syncWaiter)
+}
+
+// recreateInformer starts a new informer of the namespace and moves the handlers to it.
+func (n *namespaceInformer) recreateInformer(newFactory InformerFactoryFunc, waiter syncWaiter) error {
+	informer, lister, stopCh, err := startNamespaceInformer(context.Background(), newFactory, waiter, n.namespace, n.events)
+	if err != nil {
+		return err
+	}
+	return n.replaceInformer(informer, lister, stopCh)
+}
+
+// RemoveSecretEventHandler removes a secret event handler and stops the informer
+// of the namespace if no handlers are left.
+func (s *namespacedSecretMonitor) RemoveSecretEventHandler(handlerRegistration SecretEventHandlerRegistration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if handlerRegistration == nil {
+		return fmt.Errorf("nil secret handler registration is provided")
+	}
+
+	key := handlerRegistration.GetKey()
+	n, exists := s.namespaces[key.Namespace]
+	if !exists {
+		return fmt.Errorf("secret monitor already removed for item key %v", key)
+	}
+
+	if err := n.removeEventHandler(handlerRegistration); err != nil {
+		return err
+	}
+	n.numHandlers -= 1
+	klog.Info("secret handler removed", " item key", key)
+
+	if n.numHandlers <= 0 {
+		n.stop()
+		delete(s.namespaces, key.Namespace)
+		klog.Info("namespace secret informer stopped", " namespace ", key.Namespace)
+	}
+
+	return nil
+}
+
+// GetSecret retrieves the secret object from the cache of the namespace informer.
+func (s *namespacedSecretMonitor) GetSecret(ctx context.Context, handlerRegistration SecretEventHandlerRegistration) (*corev1.Secret, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if handlerRegistration == nil {
+		return nil, fmt.Errorf("nil secret handler registration is provided")
+	}
+	key := handlerRegistration.GetKey()
+
+	n, exists := s.namespaces[key.Namespace]
+	if !exists {
+		return nil, fmt.Errorf("secret monitor doesn't exist for key %v", key)
+	}
+
+	if !s.waitForSync(ctx, handlerRegistration.HasSynced) {
+		return nil, fmt.Errorf("%w for item key %v", ErrCacheNotSynced, key)
+	}
+
+	return n.get(key.Name)
+}
+
+// ShutdownNamespace stops the informer of the namespace and removes it.
+func (s *namespacedSecretMonitor) ShutdownNamespace(namespace string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	n, exists := s.namespaces[namespace]
+	if !exists {
+		return nil
+	}
+	n.stop()
+	delete(s.namespaces, namespace)
+	klog.Info("namespace secret informer stopped", " namespace ", namespace)
+
+	return nil
+}
+
+// GetSecretByKey retrieves the secret from the cache of its namespace informer.
+// Returns an error if no handler of the secret is registered.
+func (s *namespacedSecretMonitor) GetSecretByKey(key ObjectKey) (*corev1.Secret, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	n, exists := s.namespaces[key.Namespace]
+	if !exists || !n.hasRegistration(key) {
+		return nil, fmt.Errorf("secret monitor doesn't exist for key %v", key)
+	}
+	return n.get(key.Name)
+}
+
+// ListMonitoredKeys returns the keys of the secrets with a registered handler.
+func (s *namespacedSecretMonitor) ListMonitoredKeys() []ObjectKey {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	monitored := sets.New[ObjectKey]()
+	for _, n := range s.namespaces {
+		n.lock.Lock()
+		for registration := range n.registrations {
+			monitored.Insert(registration.objectKey)
+		}
+		n.lock.Unlock()
+	}
+	keys := monitored.UnsortedList()
+	sortObjectKeys(keys)
+
+	return keys
+}
+
+// ListCachedSecrets returns a summary of every secret cached by the namespace informers sorted by namespace and name.
+func (s *namespacedSecretMonitor) ListCachedSecrets() []SecretSummary {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	summaries := []SecretSummary{}
+	for _, n := range s.namespaces {
+		informer, lister := n.current()
+		synced := informer.HasSynced()
+		secrets, err := lister.List(labels.Everything())
+		if err != nil {
+			klog.Errorf("failed to list cached secrets of namespace %s: %v", n.namespace, err)
+			continue
+		}
+		for _, secret := range secrets {
+			summaries = append(summaries, SecretSummary{
+				Namespace:       secret.Namespace,
+				Name:            secret.Name,
+				Type:            string(secret.Type),
+				ResourceVersion: secret.ResourceVersion,
+				Synced:          synced,
+			})
+		}
+	}
+	sortSecretSummaries(summaries)
+
+	return summaries
+}
+
+// ListHandlers returns the handler registrations of the secret in its namespace informer,
+// nil if the namespace is not monitored.
+func (s *namespacedSecretMonitor) ListHandlers(key ObjectKey) []SecretEventHandlerRegistration {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	n, exists := s.namespaces[key.Namespace]
+	if !exists {
+		return nil
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return listRegistrations(n.registrations, key)
+}
+
+// ByIndex returns the secrets matching the index from the caches of every namespace informer.
+// Namespace informers which lack the index are skipped.
+func (s *namespacedSecretMonitor) ByIndex(indexName, indexedValue string) ([]*corev1.Secret, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	secrets := []*corev1.Secret{}
+	found := false
+	for _, n := range s.namespaces {
+		informer, _ := n.current()
+		indexer := informer.GetIndexer()
+		if _, exists := indexer.GetIndexers()[indexName]; !exists {
+			continue
+		}
+		found = true
+		objs, err := indexer.ByIndex(indexName, indexedValue)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			if secret, ok := obj.(*corev1.Secret); ok {
+				secrets = append(secrets, secret)
+			}
+		}
+	}
+	if !found && len(s.namespaces) > 0 {
+		return nil, fmt.Errorf("index with name %s does not exist", indexName)
+	}
+	sortSecrets(secrets)
+
+	return secrets, nil
+}
+
+// stop stops the namespace informer. Returns false if already stopped.
+func (n *namespaceInformer) stop() bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.stopped {
+		return false
+	}
+	n.stopped = true
+	close(n.stopCh)
+	return true
+}
+
+// addEventHandler adds the handler, filtered by key, to the namespace informer.
+func (n *namespaceInformer) addEventHandler(key ObjectKey, handler cache.ResourceEventHandler) (*secretEventHandlerRegistration, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	filtered := cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			return hasObjectKey(obj, key)
+		},
+		Handler: newRecoveringHandler(loggerForKey(klog.Background(), key), handler),
+	}
+	n.events.track(key)
+	handle, err := n.informer.AddEventHandler(filtered)
+	if err != nil {
+		n.forgetUnregistered(key)
+		return nil, err
+	}
+
+	registration := &secretEventHandlerRegistration{
+		handle:    handle,
+		handler:   filtered,
+		objectKey: key,
+		owner:     n,
+	}
+	n.registrations[registration] = struct{}{}
+	return registration, nil
+}
+
+// hasRegistration returns true if a handler of the secret with the given key is registered.
+func (n *namespaceInformer) hasRegistration(key ObjectKey) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return len(listRegistrations(n.registrations, key)) > 0
+}
+
+// removeEventHandler removes the handler of the registration from the namespace informer.
+func (n *namespaceInformer) removeEventHandler(handlerRegistration SecretEventHandlerRegistration) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	registration, ok := handlerRegistration.(*secretEventHandlerRegistration)
+	if ok {
+		// registrations already removed must not be counted again
+		if _, tracked := n.registrations[registration]; !tracked {
+			return fmt.Errorf("handler registration not found in monitor for item key %v", handlerRegistration.GetKey())
+		}
+	}
+
+	// the informer returns no error for handlers it doesn't know, e.g. already removed from it
+	if err := n.informer.RemoveEventHandler(handlerRegistration.GetHandler()); err != nil {
+		return err
+	}
+	if ok {
+		delete(n.registrations, registration)
+	}
+	n.forgetUnregistered(handlerRegistration.GetKey())
+	return nil
+}
+
+// forgetUnregistered stops counting the events of the secret once it has no handler. The caller must hold the lock.
+func (n *namespaceInformer) forgetUnregistered(key ObjectKey) {
+	if len(listRegistrations(n.registrations, key)) == 0 {
+		n.events.forget(key)
+	}
+}
+
+// replaceInformer moves every handler to the given running informer and stops the previous one.
+func (n *namespaceInformer) replaceInformer(informer cache.SharedIndexInformer, lister corev1listers.SecretNamespaceLister, stopCh chan struct{}) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.stopped {
+		close(stopCh)
+		return fmt.Errorf("informer stopped while replacing it for namespace %s", n.namespace)
+	}
+
+	handles := make(map[*secretEventHandlerRegistration]cache.ResourceEventHandlerRegistration, len(n.registrations))
+	for r := range n.registrations {
+		handle, err := informer.AddEventHandler(r.handler)
+		if err != nil {
+			close(stopCh)
+			return err
+		}
+		handles[r] = handle
+	}
+	for r, handle := range handles {
+		r.setHandle(handle)
+	}
+
+	close(n.stopCh)
+	n.informer = informer
+	n.lister = lister
+	n.stopCh = stopCh
+	klog.Info("namespace secret informer replaced", " namespace ", n.namespace)
+	return nil
+}
+
+// current returns the informer and lister currently backing the namespace.
+func (n *namespaceInformer) current() (cache.SharedIndexInformer, corev1listers.SecretNamespaceLister) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.informer, n.lister
+}
+
+// eventCounts implements registrationOwner.
+func (n *namespaceInformer) eventCounts(key ObjectKey) (adds, updates, deletes int64) {
+	return n.events.counts(key)
+}
+
+// lastSyncResourceVersion implements registrationOwner. The resource version is the one of the namespace informer,
+// which is at least the one of the secret.
+func (n *namespaceInformer) lastSyncResourceVersion(_ ObjectKey) string {
+	informer, _ := n.current()
+	return informer.LastSyncResourceVersion()
+}
+
+// getSecret implements registrationOwner.
+func (n *namespaceInformer) getSecret(key ObjectKey) (*corev1.Secret, error) {
+	n.lock.Lock()
+	stopped := n.stopped
+	n.lock.Unlock()
+
+	if stopped {
+		return nil, fmt.Errorf("secret monitor already stopped for item key %v", key)
+	}
+	return n.get(key.Name)
+}
+
+// get returns the secret with the given name from the lister.
+func (n *namespaceInformer) get(name string) (*corev1.Secret, error) {
+	_, lister := n.current()
+	secret, err := lister.Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%w: %w", ErrSecretNotFound, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSecretKey(secret, NewObjectKey(n.namespace, name)); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// hasObjectKey returns true if obj is the secret identified by key.
+// Tombstones of deleted secrets are unwrapped.
+func hasObjectKey(obj interface{}, key ObjectKey) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return false
+	}
+	return secret.Namespace == key.Namespace && secret.Name == key.Name
+}
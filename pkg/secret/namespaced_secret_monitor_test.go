@@ -0,0 +1,311 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNamespacedSecretMonitor(t *testing.T) {
+	keys := []ObjectKey{
+		{Namespace: "ns1", Name: "secret1"},
+		{Namespace: "ns1", Name: "secret2"},
+		{Namespace: "ns1", Name: "secret3"},
+		{Namespace: "ns2", Name: "secret1"},
+	}
+
+	fakeKubeClient := fake.NewSimpleClientset(
+		fakeSecret("ns1", "secret1"),
+		fakeSecret("ns1", "secret2"),
+		fakeSecret("ns1", "secret3"),
+		fakeSecret("ns2", "secret1"),
+	)
+	factoryCalls := map[string]int{}
+	sm := NewNamespacedSecretMonitor(func(namespace string) informers.SharedInformerFactory {
+		factoryCalls[namespace] += 1
+		return informers.NewSharedInformerFactoryWithOptions(fakeKubeClient, 0, informers.WithNamespace(namespace))
+	}).(*namespacedSecretMonitor)
+
+	registrations := map[ObjectKey]SecretEventHandlerRegistration{}
+	for _, k := range keys {
+		h, err := sm.AddSecretEventHandler(context.TODO(), k.Namespace, k.Name, cache.ResourceEventHandlerFuncs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		registrations[k] = h
+	}
+
+	// one informer per namespace
+	if !reflect.DeepEqual(factoryCalls, map[string]int{"ns1": 1, "ns2": 1}) {
+		t.Errorf("expected one informer per namespace, got factory calls %v", factoryCalls)
+	}
+	if len(sm.namespaces) != 2 {
+		t.Fatalf("expected 2 namespace informers, got %d", len(sm.namespaces))
+	}
+	if sm.namespaces["ns1"].numHandlers != 3 {
+		t.Errorf("expected 3 handlers in ns1, got %d", sm.namespaces["ns1"].numHandlers)
+	}
+
+	// secrets are filtered by name on read
+	for k, h := range registrations {
+		gotSec, err := sm.GetSecret(context.TODO(), h)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotSec.Namespace != k.Namespace || gotSec.Name != k.Name {
+			t.Errorf("expected secret %v, got %s/%s", k, gotSec.Namespace, gotSec.Name)
+		}
+	}
+
+	// the informer is stopped with the last handler of the namespace
+	for _, k := range keys[:3] {
+		if err := sm.RemoveSecretEventHandler(registrations[k]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, exists := sm.namespaces["ns1"]; exists {
+		t.Error("expected ns1 informer to be removed")
+	}
+	if _, exists := sm.namespaces["ns2"]; !exists {
+		t.Error("expected ns2 informer to exist")
+	}
+}
+
+func TestNamespacedSecretMonitorFiltersEvents(t *testing.T) {
+	fakeKubeClient := fake.NewSimpleClientset()
+	sm := NewNamespacedSecretMonitor(func(namespace string) informers.SharedInformerFactory {
+		return informers.NewSharedInformerFactoryWithOptions(fakeKubeClient, 0, informers.WithNamespace(namespace))
+	})
+
+	added := make(chan string, 2)
+	_, err := sm.AddSecretEventHandler(context.TODO(), "ns", "secret1", cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			added <- obj.(metav1.Object).GetName()
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"other", "secret1"} {
+		if _, err := fakeKubeClient.CoreV1().Secrets("ns").Create(context.TODO(), fakeSecret("ns", name), metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case name := <-added:
+		if name != "secret1" {
+			t.Errorf("expected event for secret1, got %s", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for add event")
+	}
+	select {
+	case name := <-added:
+		t.Errorf("unexpected event for %s", name)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNamespacedSecretMonitorEventCounts(t *testing.T) {
+	fakeKubeClient := fake.NewSimpleClientset(fakeSecret("ns", "secret"), fakeSecret("ns", "other"))
+	sm := NewNamespacedSecretMonitor(func(namespace string) informers.SharedInformerFactory {
+		return informers.NewSharedInformerFactoryWithOptions(fakeKubeClient, 0, informers.WithNamespace(namespace))
+	}).(*namespacedSecretMonitor)
+
+	registration, err := sm.AddSecretEventHandler(context.TODO(), "ns", "secret", cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := sm.namespaces["ns"].events
+	counterKeys := func() []ObjectKey {
+		events.lock.Lock()
+		defer events.lock.Unlock()
+		keys := []ObjectKey{}
+		for key := range events.counters {
+			keys = append(keys, key)
+		}
+		return keys
+	}
+
+	// only the events of the registered secret are counted
+	if _, err := fakeKubeClient.CoreV1().Secrets("ns").Create(context.TODO(), fakeSecret("ns", "created"), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	updated := fakeSecret("ns", "secret")
+	updated.Labels = map[string]string{"updated": "true"}
+	if _, err := fakeKubeClient.CoreV1().Secrets("ns").Update(context.TODO(), updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		_, updates, _ := registration.EventCounts()
+		return updates == 1, nil
+	}); err != nil {
+		t.Fatalf("timed out waiting for the update to be counted: %v", err)
+	}
+	if adds, _, _ := registration.EventCounts(); adds != 1 {
+		t.Errorf("expected the initial add to be counted, got %d adds", adds)
+	}
+	if keys := counterKeys(); !reflect.DeepEqual(keys, []ObjectKey{registration.GetKey()}) {
+		t.Errorf("expected only the registered secret to be counted, got %v", keys)
+	}
+
+	// the counter of a deleted secret is dropped
+	if err := fakeKubeClient.CoreV1().Secrets("ns").Delete(context.TODO(), "secret", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return len(counterKeys()) == 0, nil
+	}); err != nil {
+		t.Fatalf("timed out waiting for the counter of the deleted secret to be dropped: %v", err)
+	}
+
+	// the events of a secret without handlers are no longer counted
+	other, err := sm.AddSecretEventHandler(context.TODO(), "ns", "other", cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sm.RemoveSecretEventHandler(other)
+	if err := sm.RemoveSecretEventHandler(registration); err != nil {
+		t.Fatal(err)
+	}
+	events.lock.Lock()
+	defer events.lock.Unlock()
+	if !events.tracked.Equal(sets.New(other.GetKey())) {
+		t.Errorf("expected only %v to be tracked, got %v", other.GetKey(), events.tracked.UnsortedList())
+	}
+}
+
+func TestNamespacedSecretMonitorReregisterAll(t *testing.T) {
+	fakeKubeClient := fake.NewSimpleClientset(fakeSecret("ns", "secret"))
+	factoryCalls := 0
+	sm := NewNamespacedSecretMonitor(func(namespace string) informers.SharedInformerFactory {
+		factoryCalls += 1
+		return informers.NewSharedInformerFactoryWithOptions(fakeKubeClient, 0, informers.WithNamespace(namespace))
+	})
+
+	h, err := sm.AddSecretEventHandler(context.TODO(), "ns", "secret", cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.ReregisterAll(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if factoryCalls != 2 {
+		t.Errorf("expected the informer to be recreated, got %d factory calls", factoryCalls)
+	}
+
+	if _, err := h.GetSecret(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sm.RemoveSecretEventHandler(h); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNamespacedSecretMonitorReusedFactory(t *testing.T) {
+	fakeKubeClient := fake.NewSimpleClientset(fakeSecret("ns", "secret"))
+	factory := informers.NewSharedInformerFactoryWithOptions(fakeKubeClient, 0, informers.WithNamespace("ns"))
+	sm := NewNamespacedSecretMonitor(func(_ string) informers.SharedInformerFactory {
+		return factory
+	})
+
+	h, err := sm.AddSecretEventHandler(context.TODO(), "ns", "secret", cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the informer being run can't be recreated from the same factory, and keeps serving the secret
+	if err := sm.Reregister(h.GetKey()); !errors.Is(err, ErrInformerAlreadyStarted) {
+		t.Errorf("expected %v recreating the informer, got %v", ErrInformerAlreadyStarted, err)
+	}
+	if _, err := h.GetSecret(); err != nil {
+		t.Errorf("expected the running informer to keep serving the secret, got %v", err)
+	}
+
+	// the stopped informer of a removed namespace can't be run again
+	if err := sm.RemoveSecretEventHandler(h); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.AddSecretEventHandler(context.TODO(), "ns", "secret", cache.ResourceEventHandlerFuncs{}); !errors.Is(err, ErrInformerAlreadyStarted) {
+		t.Errorf("expected %v restarting the informer of the namespace, got %v", ErrInformerAlreadyStarted, err)
+	}
+
+	// nor can an informer started by the caller
+	started := informers.NewSharedInformerFactoryWithOptions(fakeKubeClient, 0, informers.WithNamespace("ns"))
+	started.Core().V1().Secrets().Informer()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	started.Start(stopCh)
+	started.WaitForCacheSync(stopCh)
+	sm = NewNamespacedSecretMonitor(func(_ string) informers.SharedInformerFactory {
+		return started
+	})
+	if _, err := sm.AddSecretEventHandler(context.TODO(), "ns", "secret", cache.ResourceEventHandlerFuncs{}); !errors.Is(err, ErrInformerAlreadyStarted) {
+		t.Errorf("expected %v for an informer started by the caller, got %v", ErrInformerAlreadyStarted, err)
+	}
+}
+
+func TestNamespacedSecretMonitorRemoveTwice(t *testing.T) {
+	fakeKubeClient := fake.NewSimpleClientset(fakeSecret("ns", "secret"))
+	sm := NewNamespacedSecretMonitor(func(namespace string) informers.SharedInformerFactory {
+		return informers.NewSharedInformerFactoryWithOptions(fakeKubeClient, 0, informers.WithNamespace(namespace))
+	})
+
+	registration, err := sm.AddSecretEventHandler(context.TODO(), "ns", "secret", cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	remaining, err := sm.AddSecretEventHandler(context.TODO(), "ns", "secret", cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sm.RemoveSecretEventHandler(remaining)
+
+	if err := sm.RemoveSecretEventHandler(registration); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sm.RemoveSecretEventHandler(registration); err == nil {
+		t.Error("expected error removing a registration no longer tracked")
+	}
+	if _, err := remaining.GetSecret(); err != nil {
+		t.Errorf("expected the namespace informer to keep running for the remaining handler, got %v", err)
+	}
+}
+
+func TestNamespacedSecretMonitorByIndex(t *testing.T) {
+	owned := fakeSecret("ns", "owned")
+	owned.Labels = map[string]string{"route": "route1"}
+	fakeKubeClient := fake.NewSimpleClientset(owned, fakeSecret("ns", "unowned"))
+	sm := NewNamespacedSecretMonitor(func(namespace string) informers.SharedInformerFactory {
+		factory := informers.NewSharedInformerFactoryWithOptions(fakeKubeClient, 0, informers.WithNamespace(namespace))
+		if err := factory.Core().V1().Secrets().Informer().AddIndexers(cache.Indexers{"ownerRoute": ownerRouteIndex}); err != nil {
+			t.Fatal(err)
+		}
+		return factory
+	})
+
+	if _, err := sm.AddSecretEventHandler(context.TODO(), "ns", "owned", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+
+	secrets, err := sm.ByIndex("ownerRoute", "ns/route1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(secrets) != 1 || secrets[0].Name != "owned" {
+		t.Errorf("expected secret owned, got %v", secrets)
+	}
+	if _, err := sm.ByIndex("unknown", "ns/route1"); err == nil {
+		t.Error("expected error for unknown index")
+	}
+}
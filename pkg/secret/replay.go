@@ -0,0 +1,51 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// AddSecretEventHandlerWithReplay adds the handler to the monitor like AddSecretEventHandler, then blocks
+// until the handler has synced and delivers the current secret, if any, to handler.OnAdd exactly once,
+// with isInInitialList set to true. It returns only after the delivery, so the caller can't miss the current state.
+// Add events of the initial list are never delivered by the informer to the handler, but later events may be
+// delivered concurrently with the replayed one.
+// If ctx is done before the handler has synced, the handler is removed and ErrCacheNotSynced is returned.
+func AddSecretEventHandlerWithReplay(ctx context.Context, monitor SecretMonitor, namespace, secretName string, handler cache.ResourceEventHandler) (SecretEventHandlerRegistration, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("nil handler is provided")
+	}
+
+	registration, err := monitor.AddSecretEventHandler(ctx, namespace, secretName, &initialListFilteringHandler{handler: handler})
+	if err != nil {
+		return nil, err
+	}
+	key := registration.GetKey()
+
+	if !waitForMonitorSync(ctx, monitor, registration.HasSynced) {
+		if err := monitor.RemoveSecretEventHandler(registration); err != nil {
+			klog.Errorf("failed to remove secret handler for item key %v: %v", key, err)
+		}
+		return nil, fmt.Errorf("%w for item key %v: %w", ErrCacheNotSynced, key, ctx.Err())
+	}
+
+	secret, err := registration.GetSecret()
+	switch {
+	case errors.Is(err, ErrSecretNotFound) || errors.Is(err, ErrSecretDeleted):
+		// nothing to replay
+		klog.V(5).Info("no secret to replay", " item key ", key)
+	case err != nil:
+		if err := monitor.RemoveSecretEventHandler(registration); err != nil {
+			klog.Errorf("failed to remove secret handler for item key %v: %v", key, err)
+		}
+		return nil, err
+	default:
+		newRecoveringHandler(loggerForKey(klog.Background(), key), handler).OnAdd(secret, true)
+	}
+
+	return registration, nil
+}
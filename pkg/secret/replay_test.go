@@ -0,0 +1,78 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestAddSecretEventHandlerWithReplay(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+
+	scenarios := []struct {
+		name       string
+		kubeClient *fake.Clientset
+		expectAdds int64
+	}{
+		{
+			name:       "current secret is delivered exactly once",
+			kubeClient: fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name)),
+			expectAdds: 1,
+		},
+		{
+			name:       "nothing is delivered without a secret",
+			kubeClient: fake.NewSimpleClientset(),
+			expectAdds: 0,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			sm := newSecretMonitor(s.kubeClient)
+			var adds atomic.Int64
+			handler := cache.ResourceEventHandlerFuncs{
+				AddFunc: func(_ interface{}) { adds.Add(1) },
+			}
+
+			if _, err := AddSecretEventHandlerWithReplay(context.TODO(), sm, key.Namespace, key.Name, handler); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// the delivery happened before returning
+			if got := adds.Load(); got != s.expectAdds {
+				t.Errorf("expected %d adds on return, got %d", s.expectAdds, got)
+			}
+
+			// the informer doesn't deliver the initial list again
+			time.Sleep(100 * time.Millisecond)
+			if got := adds.Load(); got != s.expectAdds {
+				t.Errorf("expected %d adds, got %d", s.expectAdds, got)
+			}
+		})
+	}
+}
+
+func TestAddSecretEventHandlerWithReplayDeadline(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	kubeClient := fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name))
+	unblock := make(chan struct{})
+	defer close(unblock)
+	kubeClient.PrependReactor("list", "secrets", func(_ clienttesting.Action) (bool, runtime.Object, error) {
+		<-unblock
+		return false, nil, nil
+	})
+	sm := newSecretMonitor(kubeClient)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := AddSecretEventHandlerWithReplay(ctx, sm, key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{})
+	if !errors.Is(err, ErrCacheNotSynced) {
+		t.Fatalf("expected %v, got %v", ErrCacheNotSynced, err)
+	}
+}
@@ -3,14 +3,26 @@ package secret
 import (
 	"context"
 	"fmt"
+	"math/rand"
+	"sort"
 	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 )
 
 // SecretEventHandlerRegistration is for registering and unregistering event handlers for secret monitoring.
@@ -19,31 +31,122 @@ type SecretEventHandlerRegistration interface {
 
 	GetKey() ObjectKey
 	GetHandler() cache.ResourceEventHandlerRegistration
+
+	// GetSecret retrieves the secret from the cache of the monitor owning this registration.
+	// Returns an error once the owning monitor has been stopped.
+	GetSecret() (*corev1.Secret, error)
+
+	// EventCounts returns the number of add, update and delete events of the secret
+	// delivered by the informer of the monitor owning this registration.
+	EventCounts() (adds, updates, deletes int64)
+
+	// LastSyncResourceVersion returns the resource version last observed by the informer of the monitor
+	// owning this registration, e.g. to record which version of the secret a reconcile observed.
+	// It is empty if unknown, e.g. before the informer synced.
+	LastSyncResourceVersion() string
+}
+
+// SecretReader reads the secrets monitored by a SecretMonitor, without registering or unregistering watches.
+// Components which only read secrets should accept a SecretReader rather than a SecretMonitor.
+type SecretReader interface {
+	// GetSecret retrieves the secret object from the informer's cache using the provided SecretEventHandlerRegistration.
+	// This allows accessing the latest state of the secret without making an API call.
+	// Either a non-nil secret or a non-nil error is returned, never both nil.
+	GetSecret(ctx context.Context, handlerRegistration SecretEventHandlerRegistration) (*corev1.Secret, error)
+
+	// GetSecretByKey retrieves the secret with the given key from the cache, without waiting for it to sync.
+	// Returns an error if the secret is not monitored.
+	GetSecretByKey(key ObjectKey) (*corev1.Secret, error)
+
+	// ListMonitoredKeys returns the keys of the monitored secrets, sorted by namespace and name.
+	ListMonitoredKeys() []ObjectKey
 }
 
 // SecretMonitor helps in monitoring and handling a specific secret using singleItemMonitor.
 type SecretMonitor interface {
+	SecretReader
+
 	// AddSecretEventHandler adds a secret event handler to the monitor for a specific secret in the given namespace.
 	// The handler will be notified of events related to the "specified" secret only.
 	// The returned SecretEventHandlerRegistration can be used to later remove the handler.
 	AddSecretEventHandler(ctx context.Context, namespace, secretName string, handler cache.ResourceEventHandler) (SecretEventHandlerRegistration, error)
 
+	// AddSecretEventHandlerWithInformer is like AddSecretEventHandler, but the secret is monitored with the
+	// provided informer instead of one built by the monitor. The informer must hold the given secret only,
+	// and must not be running yet. It is ignored if the secret is already monitored.
+	AddSecretEventHandlerWithInformer(ctx context.Context, key ObjectKey, informer cache.SharedInformer, handler cache.ResourceEventHandler) (SecretEventHandlerRegistration, error)
+
 	// RemoveSecretEventHandler removes a previously added secret event handler using the provided registration.
 	// If the handler is not found or if there is an issue removing it, an error is returned.
 	RemoveSecretEventHandler(handlerRegistration SecretEventHandlerRegistration) error
 
-	// GetSecret retrieves the secret object from the informer's cache using the provided SecretEventHandlerRegistration.
-	// This allows accessing the latest state of the secret without making an API call.
-	GetSecret(ctx context.Context, handlerRegistration SecretEventHandlerRegistration) (*corev1.Secret, error)
+	// ShutdownNamespace stops and removes all the secret monitors of the given namespace.
+	// Handler registrations of the removed monitors are no longer usable.
+	ShutdownNamespace(namespace string) error
+
+	// ListCachedSecrets returns a summary of every secret currently monitored, without any secret data.
+	ListCachedSecrets() []SecretSummary
+
+	// ReregisterAll recreates the informers of all monitored secrets and moves the existing handlers to them.
+	// Handler registrations remain valid.
+	ReregisterAll() error
+
+	// Reregister is like ReregisterAll for the informer of a single secret, e.g. if its cache is suspected
+	// to be stale. The new informer lists the secret again, and has synced once Reregister returns.
+	Reregister(key ObjectKey) error
+
+	// SetKubeClient replaces the client used by informers created from now on, e.g. after credential rotation.
+	// Running informers and their in-flight watches keep using the previous client until they are
+	// recreated, for instance by ReregisterAll.
+	SetKubeClient(kubeClient kubernetes.Interface)
+
+	// ByIndex returns the cached secrets whose indexedValue is indexed under indexName,
+	// sorted by namespace and name.
+	ByIndex(indexName, indexedValue string) ([]*corev1.Secret, error)
+
+	// ListHandlers returns the active handler registrations of the secret with the given key, in no particular order,
+	// so that a specific handler can be removed with RemoveSecretEventHandler even if its registration was lost.
+	ListHandlers(key ObjectKey) []SecretEventHandlerRegistration
+}
+
+// SecretSummary describes a monitored secret without exposing its data.
+type SecretSummary struct {
+	Namespace       string
+	Name            string
+	Type            string
+	ResourceVersion string
+	// Synced is true if the informer of the secret has synced.
+	Synced bool
 }
 
 // secretEventHandlerRegistration is an implementation of the SecretEventHandlerRegistration.
 type secretEventHandlerRegistration struct {
-	cache.ResourceEventHandlerRegistration
+	// handle is the registration of the handler with the current informer.
+	// It is replaced when the informer is recreated.
+	handle     cache.ResourceEventHandlerRegistration
+	handleLock sync.RWMutex
+
+	// handler is the handler added to the informer, re-added when the informer is recreated.
+	handler cache.ResourceEventHandler
 
 	// objectKey represents the unique identifier for the secret associated with this event handler registration.
 	// It will be populated during AddEventHandler, and will be used during RemoveEventHandler, GetSecret.
 	objectKey ObjectKey
+
+	// owner is the monitor which created this registration.
+	owner registrationOwner
+}
+
+// registrationOwner reads the secret of a registration from the cache of the monitor owning it.
+type registrationOwner interface {
+	// getSecret returns the secret with the given key, or an error if the monitor is stopped.
+	getSecret(key ObjectKey) (*corev1.Secret, error)
+
+	// eventCounts returns the number of events of the secret with the given key.
+	eventCounts(key ObjectKey) (adds, updates, deletes int64)
+
+	// lastSyncResourceVersion returns the resource version last observed by the informer of the secret.
+	lastSyncResourceVersion(key ObjectKey) string
 }
 
 func (r *secretEventHandlerRegistration) GetKey() ObjectKey {
@@ -51,49 +154,630 @@ func (r *secretEventHandlerRegistration) GetKey() ObjectKey {
 }
 
 func (r *secretEventHandlerRegistration) GetHandler() cache.ResourceEventHandlerRegistration {
-	return r.ResourceEventHandlerRegistration
+	r.handleLock.RLock()
+	defer r.handleLock.RUnlock()
+
+	return r.handle
+}
+
+func (r *secretEventHandlerRegistration) HasSynced() bool {
+	return r.GetHandler().HasSynced()
+}
+
+// setHandle replaces the registration of the handler with the current informer.
+func (r *secretEventHandlerRegistration) setHandle(handle cache.ResourceEventHandlerRegistration) {
+	r.handleLock.Lock()
+	defer r.handleLock.Unlock()
+
+	r.handle = handle
+}
+
+func (r *secretEventHandlerRegistration) GetSecret() (*corev1.Secret, error) {
+	if r.owner == nil {
+		return nil, fmt.Errorf("no secret monitor associated with item key %v", r.objectKey)
+	}
+	return r.owner.getSecret(r.objectKey)
+}
+
+func (r *secretEventHandlerRegistration) EventCounts() (adds, updates, deletes int64) {
+	if r.owner == nil {
+		return 0, 0, 0
+	}
+	return r.owner.eventCounts(r.objectKey)
+}
+
+func (r *secretEventHandlerRegistration) LastSyncResourceVersion() string {
+	if r.owner == nil {
+		return ""
+	}
+	return r.owner.lastSyncResourceVersion(r.objectKey)
 }
 
 type monitoredItem struct {
 	itemMonitor *singleItemMonitor
 	numHandlers int
+
+	// idleGeneration is bumped every time the item becomes idle or is reused,
+	// so that a stale idle timer doesn't stop a reused informer.
+	idleGeneration int
+
+	// external is true if the informer was provided by the caller, in which case it is never recreated.
+	external bool
+}
+
+// pendingItem is the informer of an item being started by addItemEventHandler, outside the lock of the monitor.
+// done is closed once the informer has synced or failed to, and the monitor of the item is published, if any.
+type pendingItem struct {
+	done chan struct{}
 }
 
+// FieldSelectorBuilder builds the field selector used to list/watch the secret with the given name.
+type FieldSelectorBuilder func(secretName string) fields.Selector
+
+// Option configures optional behaviour of the SecretMonitor created by NewSecretMonitor.
+type Option func(*secretMonitor)
+
+// WithFieldSelectorBuilder overrides how the field selector of a secret informer is built.
+// The returned selector must still require an exact match on metadata.name, since
+// each informer is expected to hold a single secret.
+func WithFieldSelectorBuilder(builder FieldSelectorBuilder) Option {
+	return func(s *secretMonitor) {
+		s.fieldSelectorBuilder = builder
+	}
+}
+
+// WithIdleTimeout keeps the informer of a secret running for the given grace period
+// after its last handler is removed. A handler added within the period reuses the
+// informer, otherwise the informer is stopped once the period expires.
+// By default the informer is stopped as soon as the last handler is removed.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(s *secretMonitor) {
+		s.idleTimeout = timeout
+	}
+}
+
+// WithStartupJitter delays the start of every secret informer by a random duration up to max,
+// spreading the initial LIST requests when many secrets are monitored at once.
+// Stopping an informer is never delayed. By default informers are started immediately.
+func WithStartupJitter(max time.Duration) Option {
+	return func(s *secretMonitor) {
+		s.startupJitter = max
+	}
+}
+
+// WithOnSynced registers a callback invoked with the key of a secret once its informer has synced.
+// The callback is invoked from a separate goroutine, exactly once per informer: a recreated informer
+// (see ReregisterAll) invokes it again. The callback must not block.
+func WithOnSynced(onSynced func(key ObjectKey)) Option {
+	return func(s *secretMonitor) {
+		s.onSynced = onSynced
+	}
+}
+
+// WithOnInformerExited registers a callback invoked with the key of a secret when its informer exits
+// without being stopped, which isn't expected to happen. The monitor of the secret is then stopped: getting
+// the secret fails, and adding a handler replaces the monitor, so the callback can re-add the handlers of the
+// secret. It is invoked from a separate goroutine and must not block.
+func WithOnInformerExited(onInformerExited func(key ObjectKey)) Option {
+	return func(s *secretMonitor) {
+		s.onInformerExited = onInformerExited
+	}
+}
+
+// WithSyncPollInterval sets how often HasSynced is polled while waiting for an informer to sync,
+// e.g. when adding a handler or getting a secret. Defaults to 50ms.
+func WithSyncPollInterval(interval time.Duration) Option {
+	return func(s *secretMonitor) {
+		s.syncPollInterval = interval
+	}
+}
+
+// WithNamespaceInformer watches the namespaces with the given informer, which the caller must start, and removes
+// the monitors of the namespaces which are terminating or deleted, see WithOnNamespaceTerminating. Without it, the
+// monitors of a terminating namespace are kept until their handlers are removed.
+func WithNamespaceInformer(namespaceInformer cache.SharedIndexInformer) Option {
+	return func(s *secretMonitor) {
+		s.namespaceInformer = namespaceInformer
+	}
+}
+
+// WithOnNamespaceTerminating registers a callback invoked when the monitors of a namespace are removed
+// because the namespace is terminating, see WithNamespaceInformer, so that the caller can clean up its
+// registrations. Monitors of a terminating namespace are removed even without a callback.
+func WithOnNamespaceTerminating(onNamespaceTerminating func(namespace string)) Option {
+	return func(s *secretMonitor) {
+		s.onNamespaceTerminating = onNamespaceTerminating
+	}
+}
+
+// WithMaxHandlersPerSecret limits the number of handlers of a single secret to max, adding more handlers
+// fails with ErrTooManyHandlers. It helps surfacing handlers which are never removed.
+// By default the number of handlers is unlimited.
+func WithMaxHandlersPerSecret(max int) Option {
+	return func(s *secretMonitor) {
+		s.maxHandlersPerSecret = max
+	}
+}
+
+// WithClock sets the clock used for the idle timeout and the startup jitter, which defaults to the real clock.
+// It is mostly useful in tests, with a fake clock.
+func WithClock(clock clock.WithDelayedExecution) Option {
+	return func(s *secretMonitor) {
+		s.clock = clock
+	}
+}
+
+// WithResyncPeriod makes the secret informers redeliver the cached secret to the handlers every period,
+// as an update whose old and new secrets are the same, so that handlers can re-check secrets even absent
+// changes, e.g. the expiry of their certificate. By default the informers never resync.
+func WithResyncPeriod(period time.Duration) Option {
+	return func(s *secretMonitor) {
+		s.resyncPeriod = period
+	}
+}
+
+// WithListWatchTimeout bounds the list calls of the secret informers by timeout, and asks the apiserver
+// to close the watches after timeout, so that an informer stuck on a slow apiserver fails and is retried
+// instead of hanging. The errors are reported to the WithOnListWatchError callback.
+// By default list and watch calls are not bounded.
+func WithListWatchTimeout(timeout time.Duration) Option {
+	return func(s *secretMonitor) {
+		s.listWatchTimeout = timeout
+	}
+}
+
+// WithOnListWatchError registers a callback invoked with the key of a secret whenever a list or watch
+// call of its informer fails, e.g. to mark the secret as degraded. The informer keeps retrying.
+// The callback is invoked from the informer goroutine and must not block.
+func WithOnListWatchError(onListWatchError func(key ObjectKey, err error)) Option {
+	return func(s *secretMonitor) {
+		s.onListWatchError = onListWatchError
+	}
+}
+
+// WithIndexers adds the indexers to every secret informer created by the monitor, so that the
+// cached secrets can be looked up with ByIndex, e.g. by the route owning them.
+func WithIndexers(indexers cache.Indexers) Option {
+	return func(s *secretMonitor) {
+		s.indexers = indexers
+	}
+}
+
+// WithMinimalSecretCache drops every data key but tls.crt, tls.key and ca.crt from the secrets
+// before they enter the informer caches, reducing the memory used by secrets holding extra data.
+// Secrets returned by GetSecret and delivered to the handlers are trimmed as well.
+func WithMinimalSecretCache() Option {
+	return func(s *secretMonitor) {
+		s.transform = minimalSecretTransform
+	}
+}
+
+// WithAddRetry makes adding a handler which requires a new informer retry the informer start with the given
+// backoff, so that a transient failure, e.g. a client which is not ready yet, doesn't propagate to the caller.
+// An attempt fails as soon as a list or watch call of the informer fails before it has synced, in which case the
+// informer is stopped and a new one, built with the current client, is started after the backoff delay.
+// At most backoff.Steps attempts are made, and retries stop once the context is done.
+// Informers provided with AddSecretEventHandlerWithInformer are not retried.
+// By default a single informer is started, whose list and watch calls are retried until the context is done.
+func WithAddRetry(backoff wait.Backoff) Option {
+	return func(s *secretMonitor) {
+		s.addRetry = &backoff
+	}
+}
+
+// WithDiscoveryCheck makes the monitor check with discovery that the v1 secrets resource is served
+// before starting a new informer, failing with ErrSecretsAPIUnavailable otherwise instead of starting
+// an informer which can't sync, e.g. while a cluster bootstraps. By default there is no check.
+func WithDiscoveryCheck() Option {
+	return func(s *secretMonitor) {
+		s.discoveryCheck = true
+	}
+}
+
+// WithLogger sets the logger of the monitor, which defaults to the klog logger. The logs of a secret,
+// e.g. of the events delivered to its handlers or of its failed list and watch calls, have the
+// namespace and the name of the secret as "namespace" and "secret" values.
+func WithLogger(logger klog.Logger) Option {
+	return func(s *secretMonitor) {
+		s.logger = logger
+	}
+}
+
+// WithSkipInitialEvents makes the handlers ignore the add events of the secrets listed before their
+// registration has synced, e.g. on informer start, so that only the changes made afterwards are delivered.
+// It helps controllers which load the initial state elsewhere to avoid redundant reconciles.
+// Handlers moved to a recreated informer, see ReregisterAll, skip its initial events as well.
+func WithSkipInitialEvents() Option {
+	return func(s *secretMonitor) {
+		s.skipInitialEvents = true
+	}
+}
+
+// WithRESTClientForNamespace makes the secret informers of a namespace list and watch the secrets with the
+// REST client returned by restClientFor, e.g. one impersonating a namespace specific user or going through a proxy.
+// restClientFor is invoked with the namespace of the secret every time a secret informer is created.
+// By default the client of the monitor is used, see SetKubeClient.
+func WithRESTClientForNamespace(restClientFor func(namespace string) rest.Interface) Option {
+	return func(s *secretMonitor) {
+		s.restClientFor = restClientFor
+	}
+}
+
+// WithCircuitBreaker makes the monitor stop creating new informers once failureThreshold informers
+// failed to start within window, e.g. because the apiserver is overloaded: adding a handler which
+// requires a new informer fails fast with ErrCircuitOpen for cooldown. Once the cooldown expires,
+// a single informer start is attempted, closing the breaker on success and opening it again on failure.
+// Handlers of already monitored secrets are always added. The state is exposed by the
+// secret_monitor_circuit_breaker_state metric with the given name as monitor label, which must be
+// unique among the monitors of the process, see RegisterMetrics.
+// By default informers are always created.
+func WithCircuitBreaker(name string, failureThreshold int, window, cooldown time.Duration) Option {
+	return func(s *secretMonitor) {
+		s.breaker = newCircuitBreaker(name, failureThreshold, window, cooldown)
+	}
+}
+
+// WithNamespaceAllowList restricts the monitored secrets to the given namespaces: adding a handler for a
+// secret of another namespace fails with ErrNamespaceNotAllowed. An empty list allows every namespace,
+// which is the default.
+func WithNamespaceAllowList(namespaces []string) Option {
+	return func(s *secretMonitor) {
+		if len(namespaces) == 0 {
+			s.allowedNamespaces = nil
+			return
+		}
+		s.allowedNamespaces = sets.New(namespaces...)
+	}
+}
+
+// minimalSecretKeys are the data keys kept by minimalSecretTransform.
+var minimalSecretKeys = []string{TLSCertKey, TLSPrivateKeyKey, TLSCABundleKey}
+
+// minimalSecretTransform is a cache.TransformFunc keeping only the certificate related data keys of a secret.
+func minimalSecretTransform(obj interface{}) (interface{}, error) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		// e.g. tombstones, which hold an already transformed secret
+		return obj, nil
+	}
+
+	trimmed := *secret
+	trimmed.Data = make(map[string][]byte, len(minimalSecretKeys))
+	for _, key := range minimalSecretKeys {
+		if value, exists := secret.Data[key]; exists {
+			trimmed.Data[key] = value
+		}
+	}
+	trimmed.StringData = nil
+	return &trimmed, nil
+}
+
+var _ SecretReader = &secretMonitor{}
+
 // secretMonitor is an implementation of the SecretMonitor
 type secretMonitor struct {
 	kubeClient kubernetes.Interface
 	lock       sync.RWMutex
 	monitors   map[ObjectKey]*monitoredItem
+
+	// pending are the items whose informer is being started, see startPendingItem. Protected by lock.
+	pending map[ObjectKey]*pendingItem
+
+	// fieldSelectorBuilder builds the field selector of the secret informers.
+	fieldSelectorBuilder FieldSelectorBuilder
+
+	// idleTimeout is the grace period before stopping an informer without handlers.
+	idleTimeout time.Duration
+
+	// startupJitter is the maximum random delay before running a new informer.
+	startupJitter time.Duration
+
+	// onSynced is invoked once the informer of a secret has synced.
+	onSynced func(key ObjectKey)
+
+	// onInformerExited is invoked when the informer of a secret exits without being stopped.
+	onInformerExited func(key ObjectKey)
+
+	// transform is applied to the secrets before they enter the informer caches, nil means no transform.
+	transform cache.TransformFunc
+
+	// syncPollInterval is how often informers are polled while waiting for them to sync.
+	syncPollInterval time.Duration
+
+	// syncWaiter waits for the informers to sync, nil means waitForSync. It is only set by tests.
+	syncWaiter syncWaiter
+
+	// clock is used for the idle timeout and the startup delay of the informers.
+	clock clock.WithDelayedExecution
+
+	// namespaceInformer notifies the terminating namespaces, nil means they are not watched.
+	namespaceInformer cache.SharedIndexInformer
+
+	// onNamespaceTerminating is invoked once the monitors of a terminating namespace have been removed.
+	onNamespaceTerminating func(namespace string)
+
+	// maxHandlersPerSecret is the maximum number of handlers of a secret, zero means unlimited.
+	maxHandlersPerSecret int
+
+	// indexers are added to the secret informers, nil means no indexers.
+	indexers cache.Indexers
+
+	// listWatchTimeout bounds the list and watch calls of the informers, zero means unbounded.
+	listWatchTimeout time.Duration
+
+	// resyncPeriod is the resync period of the informers, zero means no resync.
+	resyncPeriod time.Duration
+
+	// onListWatchError is invoked when a list or watch call of an informer fails.
+	onListWatchError func(key ObjectKey, err error)
+
+	// onPermissionDenied is invoked when a list or watch call of an informer is forbidden or unauthorized.
+	onPermissionDenied func(key ObjectKey, err error)
+
+	// restClientFor returns the REST client of the informers of a namespace, nil means the kubeClient is used.
+	restClientFor func(namespace string) rest.Interface
+
+	// logger is the base logger of the secrets, see loggerForKey.
+	logger klog.Logger
+
+	// skipInitialEvents drops the add events delivered to the handlers before they have synced.
+	skipInitialEvents bool
+
+	// addRetry is the backoff of the informer start attempts, nil means a single attempt.
+	addRetry *wait.Backoff
+
+	// discoveryCheck verifies that the secrets resource is served before starting an informer.
+	discoveryCheck bool
+
+	// breaker stops the creation of informers after repeated start failures, nil means no breaker.
+	breaker *circuitBreaker
+
+	// allowedNamespaces are the namespaces whose secrets can be monitored, nil means every namespace.
+	allowedNamespaces sets.Set[string]
+
+	// onSecretAccessed is invoked on every successful GetSecret, nil means no callback.
+	onSecretAccessed func(key ObjectKey, reader string)
+
+	// maxSecretBytes is the maximum data size of the cached secrets, zero means unlimited.
+	maxSecretBytes int
+
+	// onSecretTooLarge is invoked when the data of a secret exceeding maxSecretBytes is dropped.
+	onSecretTooLarge func(key ObjectKey, size int)
+}
+
+func NewSecretMonitor(kubeClient kubernetes.Interface, opts ...Option) SecretMonitor {
+	return newSecretMonitor(kubeClient, opts...)
 }
 
-func NewSecretMonitor(kubeClient kubernetes.Interface) SecretMonitor {
-	return &secretMonitor{
-		kubeClient: kubeClient,
-		monitors:   map[ObjectKey]*monitoredItem{},
+// newSecretMonitor creates a secretMonitor and applies the options.
+func newSecretMonitor(kubeClient kubernetes.Interface, opts ...Option) *secretMonitor {
+	s := &secretMonitor{
+		kubeClient:           kubeClient,
+		monitors:             map[ObjectKey]*monitoredItem{},
+		fieldSelectorBuilder: nameFieldSelector,
+		clock:                clock.RealClock{},
+		logger:               klog.Background(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	if s.namespaceInformer != nil {
+		if _, err := s.namespaceInformer.AddEventHandler(s.namespaceHandler()); err != nil {
+			klog.Errorf("failed to watch terminating namespaces: %v", err)
+		}
+	}
+	return s
+}
+
+// waitForSync waits for hasSynced to return true with the syncWaiter of the monitor, see waitForSync.
+func (s *secretMonitor) waitForSync(ctx context.Context, hasSynced cache.InformerSynced) bool {
+	return s.syncWaiter.wait(ctx, s.syncPollInterval, hasSynced)
+}
+
+// jitteredDelay returns a random duration in [0, startupJitter).
+func (s *secretMonitor) jitteredDelay() time.Duration {
+	return time.Duration(rand.Int63n(int64(s.startupJitter)))
+}
+
+// nameFieldSelector is the default FieldSelectorBuilder, selecting the secret by metadata.name.
+func nameFieldSelector(secretName string) fields.Selector {
+	return fields.OneTermEqualSelector("metadata.name", secretName)
 }
 
 // AddSecretEventHandler adds a secret event handler to the monitor.
+// Returns ErrEmptyNamespace or ErrEmptySecretName if the namespace or the secret name is empty.
 func (s *secretMonitor) AddSecretEventHandler(ctx context.Context, namespace, secretName string, handler cache.ResourceEventHandler) (SecretEventHandlerRegistration, error) {
-	return s.addSecretEventHandler(ctx, namespace, secretName, handler, s.createSecretInformer(namespace, secretName))
+	key := NewObjectKey(namespace, secretName)
+	if err := s.validateKey(key); err != nil {
+		return nil, err
+	}
+	namespace, secretName = key.Namespace, key.Name
+	selector, err := s.fieldSelector(secretName)
+	if err != nil {
+		return nil, err
+	}
+	return s.addSecretEventHandler(ctx, namespace, secretName, handler, s.createSecretInformer(ctx, namespace, selector))
+}
+
+// AddSecretEventHandlerWithInformer adds a secret event handler to the monitor, monitoring the secret with the
+// provided informer if the secret isn't monitored yet. Informers provided this way are not recreated by ReregisterAll.
+func (s *secretMonitor) AddSecretEventHandlerWithInformer(ctx context.Context, key ObjectKey, informer cache.SharedInformer, handler cache.ResourceEventHandler) (SecretEventHandlerRegistration, error) {
+	if err := s.validateKey(key); err != nil {
+		return nil, err
+	}
+	if informer == nil {
+		return nil, fmt.Errorf("nil informer is provided for item key %v", key)
+	}
+
+	return s.addItemEventHandler(ctx, key, handler, informer, true)
+}
+
+// validateKey validates the key of a secret a handler is added for, and returns ErrNamespaceNotAllowed
+// if its namespace is not allowed, see WithNamespaceAllowList.
+func (s *secretMonitor) validateKey(key ObjectKey) error {
+	if err := key.Validate(); err != nil {
+		return err
+	}
+	if s.allowedNamespaces != nil && !s.allowedNamespaces.Has(key.Namespace) {
+		return fmt.Errorf("%w: not monitoring item key %v", ErrNamespaceNotAllowed, key)
+	}
+	return nil
+}
+
+// fieldSelector builds the field selector for the secret and makes sure it
+// still selects a single secret by name, as GetItem() relies on it.
+func (s *secretMonitor) fieldSelector(secretName string) (fields.Selector, error) {
+	selector := s.fieldSelectorBuilder(secretName)
+	if name, found := selector.RequiresExactMatch("metadata.name"); !found || name != secretName {
+		return nil, fmt.Errorf("field selector %q must require metadata.name=%s", selector, secretName)
+	}
+	return selector, nil
 }
 
 // createSecretInformer creates a SharedInformer for monitoring a specific secret.
-func (s *secretMonitor) createSecretInformer(namespace, name string) cache.SharedInformer {
-	return cache.NewSharedInformer(
-		cache.NewListWatchFromClient(
-			s.kubeClient.CoreV1().RESTClient(),
-			"secrets",
-			namespace,
-			fields.OneTermEqualSelector("metadata.name", name),
-		),
+// The context is passed to the List and Watch calls, so cancelling it aborts in-flight requests.
+func (s *secretMonitor) createSecretInformer(ctx context.Context, namespace string, selector fields.Selector) cache.SharedInformer {
+	return s.createSecretInformerWithClient(ctx, namespace, selector, s.currentKubeClient())
+}
+
+// createSecretInformerWithClient is like createSecretInformer, with the given client instead of the current one.
+// It doesn't acquire the lock.
+func (s *secretMonitor) createSecretInformerWithClient(ctx context.Context, namespace string, selector fields.Selector, kubeClient kubernetes.Interface) cache.SharedInformer {
+	// the informer keeps the client it was created with
+	secretsClient := s.secretsClient(namespace, kubeClient)
+	fieldSelector := selector.String()
+	// the selector requires an exact match on the name, see fieldSelector
+	secretName, _ := selector.RequiresExactMatch("metadata.name")
+	informer := cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				options.FieldSelector = fieldSelector
+				listCtx := ctx
+				if s.listWatchTimeout > 0 {
+					var cancel context.CancelFunc
+					listCtx, cancel = context.WithTimeout(ctx, s.listWatchTimeout)
+					defer cancel()
+				}
+				list, err := secretsClient.List(listCtx, options)
+				if err != nil {
+					return nil, err
+				}
+				return filterSecretList(list, secretName), nil
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				options.FieldSelector = fieldSelector
+				if s.listWatchTimeout > 0 {
+					options.TimeoutSeconds = watchTimeoutSeconds(s.listWatchTimeout)
+				}
+				w, err := secretsClient.Watch(ctx, options)
+				if err != nil {
+					return nil, err
+				}
+				return filterSecretWatch(w, secretName), nil
+			},
+		},
 		&corev1.Secret{},
-		0,
+		s.resyncPeriod,
+		s.indexers,
 	)
+	// neither can fail, the informer is not running yet
+	if err := informer.SetWatchErrorHandler(s.watchErrorHandler(NewObjectKey(namespace, secretName))); err != nil {
+		klog.Errorf("failed to set watch error handler on secret informer: %v", err)
+	}
+	if transform := s.secretTransform(); transform != nil {
+		if err := informer.SetTransform(transform); err != nil {
+			klog.Errorf("failed to set transform on secret informer: %v", err)
+		}
+	}
+	return informer
+}
+
+// filterSecretList drops the secrets of the list not named secretName, in case the API server ignores the
+// metadata.name field selector, so that the store of the informer only holds the intended secret.
+// The list is kept as is if secretName is empty.
+func filterSecretList(list *corev1.SecretList, secretName string) *corev1.SecretList {
+	if secretName == "" {
+		return list
+	}
+	items := make([]corev1.Secret, 0, 1)
+	for _, item := range list.Items {
+		if item.Name == secretName {
+			items = append(items, item)
+		}
+	}
+	if dropped := len(list.Items) - len(items); dropped > 0 {
+		klog.V(4).Infof("secret monitor dropped %d secrets not matching the field selector of secret %s/%s", dropped, list.Items[0].Namespace, secretName)
+	}
+	list.Items = items
+	return list
+}
+
+// filterSecretWatch drops the events of the secrets not named secretName, see filterSecretList.
+// Events of other objects, e.g. errors and bookmarks, are kept.
+func filterSecretWatch(w watch.Interface, secretName string) watch.Interface {
+	if secretName == "" {
+		return w
+	}
+	return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+		if s, ok := event.Object.(*corev1.Secret); ok && s.Name != secretName {
+			return event, false
+		}
+		return event, true
+	})
+}
+
+// secretsClient returns the client listing and watching the secrets of the namespace,
+// built with kubeClient unless a REST client is selected for the namespace.
+func (s *secretMonitor) secretsClient(namespace string, kubeClient kubernetes.Interface) corev1client.SecretInterface {
+	if s.restClientFor != nil {
+		return corev1client.New(s.restClientFor(namespace)).Secrets(namespace)
+	}
+	return kubeClient.CoreV1().Secrets(namespace)
+}
+
+// checkSecretsAPI returns ErrSecretsAPIUnavailable if discovery doesn't report the v1 secrets resource.
+// The caller must hold the lock.
+func (s *secretMonitor) checkSecretsAPI() error {
+	resources, err := s.kubeClient.Discovery().ServerResourcesForGroupVersion(corev1.SchemeGroupVersion.String())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSecretsAPIUnavailable, err)
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == "secrets" {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: resource secrets not found in %s", ErrSecretsAPIUnavailable, corev1.SchemeGroupVersion)
+}
+
+// SetKubeClient replaces the client used to create new secret informers.
+func (s *secretMonitor) SetKubeClient(kubeClient kubernetes.Interface) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.kubeClient = kubeClient
+	klog.Info("secret monitor client replaced")
+}
+
+// currentKubeClient returns the client used to create new secret informers.
+func (s *secretMonitor) currentKubeClient() kubernetes.Interface {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.kubeClient
 }
 
 // addSecretEventHandler adds a secret event handler and starts the informer if not already running.
 func (s *secretMonitor) addSecretEventHandler(ctx context.Context, namespace, secretName string, handler cache.ResourceEventHandler, secretInformer cache.SharedInformer) (SecretEventHandlerRegistration, error) {
+	// secret identifier (namespace/secret)
+	key := NewObjectKey(namespace, secretName)
+
+	return s.addItemEventHandler(ctx, key, handler, secretInformer, false)
+}
+
+// addItemEventHandler implements addSecretEventHandler. If the item isn't monitored yet, it is monitored
+// with secretInformer, which is marked as external if provided by the caller.
+func (s *secretMonitor) addItemEventHandler(ctx context.Context, key ObjectKey, handler cache.ResourceEventHandler, secretInformer cache.SharedInformer, external bool) (SecretEventHandlerRegistration, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
@@ -101,28 +785,56 @@ func (s *secretMonitor) addSecretEventHandler(ctx context.Context, namespace, se
 		return nil, fmt.Errorf("nil handler is provided")
 	}
 
-	// secret identifier (namespace/secret)
-	key := NewObjectKey(namespace, secretName)
+	m, exists, err := s.awaitItem(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists && s.maxHandlersPerSecret > 0 && m.numHandlers >= s.maxHandlersPerSecret {
+		return nil, fmt.Errorf("%w: item key %v already has %d handlers", ErrTooManyHandlers, key, m.numHandlers)
+	}
 
 	// Start secret informer if monitor does not exist.
-	m, exists := s.monitors[key]
 	if !exists {
-		m = &monitoredItem{}
-		m.itemMonitor = newSingleItemMonitor(key, secretInformer)
-		m.itemMonitor.StartInformer(ctx)
+		if s.breaker != nil {
+			if err := s.breaker.allow(s.clock.Now()); err != nil {
+				return nil, fmt.Errorf("not starting secret informer for item key %v: %w", key, err)
+			}
+		}
 
-		// wait for first sync
-		if !cache.WaitForCacheSync(ctx.Done(), m.itemMonitor.HasSynced) {
-			return nil, fmt.Errorf("failed waiting for cache sync")
+		if s.discoveryCheck {
+			if err := s.checkSecretsAPI(); err != nil {
+				return nil, fmt.Errorf("not starting secret informer for item key %v: %w", key, err)
+			}
 		}
 
+		itemMonitor, err := s.startPendingItem(ctx, key, secretInformer, external)
+		if s.breaker != nil {
+			if err != nil {
+				s.breaker.recordFailure(s.clock.Now())
+			} else {
+				s.breaker.recordSuccess()
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		m = &monitoredItem{itemMonitor: itemMonitor, external: external}
+
 		// add item key to monitors map
 		s.monitors[key] = m
 
 		klog.Info("secret informer started", " item key ", key)
+	} else if m.numHandlers <= 0 {
+		// reuse the idle informer and cancel its pending stop
+		m.idleGeneration += 1
+		klog.V(5).Info("reusing idle secret informer", " item key ", key)
 	}
 
 	// add the event handler
+	if s.skipInitialEvents {
+		handler = &initialListFilteringHandler{handler: handler}
+	}
 	registration, err := m.itemMonitor.AddEventHandler(handler)
 	if err != nil {
 		return nil, err
@@ -137,6 +849,136 @@ func (s *secretMonitor) addSecretEventHandler(ctx context.Context, namespace, se
 	return registration, nil
 }
 
+// awaitItem returns the monitor of the item, after waiting for its informer to start if it is pending.
+// A stopped monitor is removed and reported as not existing, so that it is replaced.
+// The caller must hold the write lock, which is released while waiting.
+func (s *secretMonitor) awaitItem(ctx context.Context, key ObjectKey) (*monitoredItem, bool, error) {
+	for {
+		if p, pending := s.pending[key]; pending {
+			s.lock.Unlock()
+			select {
+			case <-p.done:
+			case <-ctx.Done():
+			}
+			s.lock.Lock()
+			if ctx.Err() != nil {
+				return nil, false, fmt.Errorf("%w for item key %v: %w", ErrCacheNotSynced, key, ctx.Err())
+			}
+			continue
+		}
+
+		m, exists := s.monitors[key]
+		if exists && m.itemMonitor.IsStopped() {
+			// The informer was stopped without removing the monitor, e.g. on context cancellation.
+			// Replace the monitor instead of failing to add the handler to a stopped informer.
+			delete(s.monitors, key)
+			exists = false
+			klog.V(5).Info("replacing stopped secret informer", " item key ", key)
+		}
+		return m, exists, nil
+	}
+}
+
+// startPendingItem starts the monitor of the item with startItemMonitor without holding the lock, so that
+// the startup delay, see WithStartupJitter, the first sync of the informer and the backoff between its start
+// attempts, see WithAddRetry, don't block the other items. The item is pending meanwhile, see awaitItem.
+// The caller must hold the write lock, which is released while the informer starts, and must publish the
+// returned monitor.
+func (s *secretMonitor) startPendingItem(ctx context.Context, key ObjectKey, informer cache.SharedInformer, external bool) (*singleItemMonitor, error) {
+	p := &pendingItem{done: make(chan struct{})}
+	if s.pending == nil {
+		s.pending = make(map[ObjectKey]*pendingItem)
+	}
+	s.pending[key] = p
+	s.lock.Unlock()
+	itemMonitor, err := s.startItemMonitor(ctx, key, informer, external)
+	s.lock.Lock()
+	delete(s.pending, key)
+	close(p.done)
+	return itemMonitor, err
+}
+
+// newItemMonitor creates the monitor of the item with the given informer, configured by the options.
+func (s *secretMonitor) newItemMonitor(key ObjectKey, informer cache.SharedInformer) *singleItemMonitor {
+	itemMonitor := newSingleItemMonitor(key, informer)
+	if s.startupJitter > 0 {
+		itemMonitor.startupDelay = s.jitteredDelay
+	}
+	itemMonitor.onSynced = s.onSynced
+	itemMonitor.onExited = s.onInformerExited
+	itemMonitor.syncPollInterval = s.syncPollInterval
+	itemMonitor.syncWaiter = s.syncWaiter
+	itemMonitor.logger = loggerForKey(s.logger, key)
+	if s.clock != nil {
+		itemMonitor.clock = s.clock
+	}
+	return itemMonitor
+}
+
+// startItemMonitor starts a monitor of the item with the given informer and waits for its first sync.
+// The caller must not hold the lock, see startPendingItem.
+// With WithAddRetry, an informer whose list or watch fails before syncing is stopped and replaced by a new one,
+// after a backoff, until one syncs, the attempts are exhausted or ctx is done. Informers provided by the caller
+// are never replaced.
+func (s *secretMonitor) startItemMonitor(ctx context.Context, key ObjectKey, informer cache.SharedInformer, external bool) (*singleItemMonitor, error) {
+	if s.addRetry == nil || external {
+		itemMonitor := s.newItemMonitor(key, informer)
+		itemMonitor.StartInformer(ctx)
+
+		// wait for first sync
+		if !s.waitForSync(ctx, itemMonitor.HasSynced) {
+			return nil, fmt.Errorf("%w for item key %v", ErrCacheNotSynced, key)
+		}
+		return itemMonitor, nil
+	}
+
+	selector, err := s.fieldSelector(key.Name)
+	if err != nil {
+		return nil, err
+	}
+	backoff := *s.addRetry
+	for {
+		itemMonitor, err := s.tryStartItemMonitor(ctx, key, informer)
+		if err == nil || backoff.Steps <= 1 || ctx.Err() != nil {
+			return itemMonitor, err
+		}
+
+		delay := backoff.Step()
+		klog.V(2).Info("retrying secret informer start", " item key ", key, " delay ", delay, " error ", err)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-s.clock.After(delay):
+		}
+		// the new informer is built with the current client
+		informer = s.createSecretInformerWithClient(ctx, key.Namespace, selector, s.currentKubeClient())
+	}
+}
+
+// tryStartItemMonitor is like startItemMonitor without retry, but fails as soon as a list or watch call
+// of the informer fails before it has synced. The informer is stopped on failure.
+func (s *secretMonitor) tryStartItemMonitor(ctx context.Context, key ObjectKey, informer cache.SharedInformer) (*singleItemMonitor, error) {
+	attemptCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	handleWatchError := s.watchErrorHandler(key)
+	if err := informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+		handleWatchError(r, err)
+		cancel(err)
+	}); err != nil {
+		return nil, err
+	}
+
+	itemMonitor := s.newItemMonitor(key, informer)
+	itemMonitor.StartInformer(ctx)
+
+	if !s.waitForSync(attemptCtx, itemMonitor.HasSynced) {
+		itemMonitor.StopInformer()
+		return nil, fmt.Errorf("%w for item key %v: %w", ErrCacheNotSynced, key, context.Cause(attemptCtx))
+	}
+	return itemMonitor, nil
+}
+
 // RemoveSecretEventHandler removes a secret event handler and stops the informer if no handlers are left.
 // If the handler is not found or if there is an issue removing it, an error is returned.
 func (s *secretMonitor) RemoveSecretEventHandler(handlerRegistration SecretEventHandlerRegistration) error {
@@ -166,6 +1008,10 @@ func (s *secretMonitor) RemoveSecretEventHandler(handlerRegistration SecretEvent
 
 	// stop informer if there is no handler
 	if m.numHandlers <= 0 {
+		if s.idleTimeout > 0 {
+			s.stopAfterIdleTimeout(key, m)
+			return nil
+		}
 		if !m.itemMonitor.StopInformer() {
 			return fmt.Errorf("secret informer already stopped for item key %v", key)
 		}
@@ -177,7 +1023,257 @@ func (s *secretMonitor) RemoveSecretEventHandler(handlerRegistration SecretEvent
 	return nil
 }
 
-// GetSecret retrieves the secret object from the informer's cache. Error if the secret is not found in the cache.
+// stopAfterIdleTimeout stops the informer of the item once the idle timeout expires,
+// unless a handler was added in the meantime. The caller must hold the write lock.
+func (s *secretMonitor) stopAfterIdleTimeout(key ObjectKey, m *monitoredItem) {
+	m.idleGeneration += 1
+	generation := m.idleGeneration
+	klog.V(5).Info("secret informer is idle", " item key ", key, " timeout ", s.idleTimeout)
+
+	s.clock.AfterFunc(s.idleTimeout, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		// the item was removed, reused, or became idle again meanwhile
+		if current, exists := s.monitors[key]; !exists || current != m || m.idleGeneration != generation {
+			return
+		}
+		m.itemMonitor.StopInformer()
+		delete(s.monitors, key)
+		klog.Info("idle secret informer stopped", " item key ", key)
+	})
+}
+
+// ShutdownNamespace stops the informers of every monitored secret in the namespace and removes
+// their monitors. Errors from monitors which could not be stopped are aggregated.
+func (s *secretMonitor) ShutdownNamespace(namespace string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, err := s.shutdownNamespace(namespace)
+	return err
+}
+
+// shutdownNamespace implements ShutdownNamespace, and returns the number of removed monitors.
+// The caller must hold the write lock.
+func (s *secretMonitor) shutdownNamespace(namespace string) (int, error) {
+	removed := 0
+	var errs []error
+	for key, m := range s.monitors {
+		if key.Namespace != namespace {
+			continue
+		}
+		if !m.itemMonitor.StopInformer() {
+			errs = append(errs, fmt.Errorf("secret informer already stopped for item key %v", key))
+		}
+		// remove the key from map even if it was already stopped
+		delete(s.monitors, key)
+		removed += 1
+		klog.Info("secret informer stopped", " item key ", key)
+	}
+
+	return removed, utilerrors.NewAggregate(errs)
+}
+
+// handleNamespaceTerminating removes the monitors of a terminating namespace, whose secrets are about to be
+// deleted, and notifies the onNamespaceTerminating callback if any monitor was removed.
+func (s *secretMonitor) handleNamespaceTerminating(namespace string) {
+	s.lock.Lock()
+	removed, err := s.shutdownNamespace(namespace)
+	s.lock.Unlock()
+
+	if removed > 0 {
+		klog.Info("stopped secret informers of terminating namespace", " namespace ", namespace)
+	}
+	if err != nil {
+		klog.Errorf("failed to stop secret informers of terminating namespace %s: %v", namespace, err)
+	}
+	if removed > 0 && s.onNamespaceTerminating != nil {
+		s.onNamespaceTerminating(namespace)
+	}
+}
+
+// namespaceHandler removes the monitors of the namespaces notified by the namespace informer once they are
+// terminating, i.e. in phase Terminating or marked for deletion, or deleted.
+func (s *secretMonitor) namespaceHandler() cache.ResourceEventHandler {
+	onNamespace := func(obj interface{}) {
+		namespace, ok := obj.(*corev1.Namespace)
+		if !ok || !isNamespaceTerminating(namespace) {
+			return
+		}
+		s.handleNamespaceTerminating(namespace.Name)
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: onNamespace,
+		UpdateFunc: func(_, newObj interface{}) {
+			onNamespace(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			namespace, ok := obj.(*corev1.Namespace)
+			if !ok {
+				return
+			}
+			s.handleNamespaceTerminating(namespace.Name)
+		},
+	}
+}
+
+// isNamespaceTerminating returns true if the namespace is in phase Terminating or marked for deletion.
+func isNamespaceTerminating(namespace *corev1.Namespace) bool {
+	return namespace.Status.Phase == corev1.NamespaceTerminating || namespace.DeletionTimestamp != nil
+}
+
+// watchErrorHandler handles the list and watch errors of the informer of the secret with the given key.
+func (s *secretMonitor) watchErrorHandler(key ObjectKey) cache.WatchErrorHandler {
+	logger := loggerForKey(s.logger, key)
+	return func(r *cache.Reflector, err error) {
+		logger.V(4).Info("secret list or watch failed", "err", err)
+		if s.onListWatchError != nil {
+			s.onListWatchError(key, err)
+		}
+		if s.onPermissionDenied != nil && (apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err)) {
+			s.onPermissionDenied(key, err)
+		}
+		cache.DefaultWatchErrorHandler(r, err)
+	}
+}
+
+// watchTimeoutSeconds converts the timeout to the TimeoutSeconds of a watch, in whole seconds and at least one.
+func watchTimeoutSeconds(timeout time.Duration) *int64 {
+	seconds := int64(timeout / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return &seconds
+}
+
+// ReregisterAll recreates the informer of every monitored secret, preserving its handlers.
+// Informers provided with AddSecretEventHandlerWithInformer are left untouched.
+// Informers are replaced one at a time, and the previous cache is served until the new one has synced.
+func (s *secretMonitor) ReregisterAll() error {
+	s.lock.RLock()
+	items := make(map[ObjectKey]*singleItemMonitor, len(s.monitors))
+	for key, m := range s.monitors {
+		if m.external {
+			klog.V(5).Info("skipping informer provided by the caller", " item key ", key)
+			continue
+		}
+		items[key] = m.itemMonitor
+	}
+	s.lock.RUnlock()
+
+	var errs []error
+	for key, itemMonitor := range items {
+		if err := s.replaceInformer(key, itemMonitor); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Reregister recreates the informer of the secret. Informers provided by the caller can't be recreated.
+func (s *secretMonitor) Reregister(key ObjectKey) error {
+	s.lock.RLock()
+	m, exists := s.monitors[key]
+	s.lock.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("secret monitor doesn't exist for key %v", key)
+	}
+	if m.external {
+		return fmt.Errorf("cannot recreate informer provided by the caller for item key %v", key)
+	}
+	return s.replaceInformer(key, m.itemMonitor)
+}
+
+// replaceInformer replaces the informer of the item monitor by a new secret informer.
+func (s *secretMonitor) replaceInformer(key ObjectKey, itemMonitor *singleItemMonitor) error {
+	selector, err := s.fieldSelector(key.Name)
+	if err != nil {
+		return err
+	}
+	return itemMonitor.ReplaceInformer(func(ctx context.Context) cache.SharedInformer {
+		return s.createSecretInformer(ctx, key.Namespace, selector)
+	})
+}
+
+// ListCachedSecrets returns a summary of every monitored secret sorted by namespace and name.
+// Type and ResourceVersion are empty for secrets which are not present in the cache.
+func (s *secretMonitor) ListCachedSecrets() []SecretSummary {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	summaries := make([]SecretSummary, 0, len(s.monitors))
+	for key, m := range s.monitors {
+		summary := SecretSummary{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Synced:    m.itemMonitor.HasSynced(),
+		}
+		if secret, err := getSecretFromMonitor(m.itemMonitor); err == nil {
+			summary.Type = string(secret.Type)
+			summary.ResourceVersion = secret.ResourceVersion
+		}
+		summaries = append(summaries, summary)
+	}
+	sortSecretSummaries(summaries)
+
+	return summaries
+}
+
+// GetSecretByKey retrieves the secret from the cache of its informer.
+func (s *secretMonitor) GetSecretByKey(key ObjectKey) (*corev1.Secret, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	m, exists := s.monitors[key]
+	if !exists {
+		return nil, fmt.Errorf("secret monitor doesn't exist for key %v", key)
+	}
+	return getSecretFromMonitor(m.itemMonitor)
+}
+
+// ListMonitoredKeys returns the keys of the secrets with an informer, including idle ones, see WithIdleTimeout.
+func (s *secretMonitor) ListMonitoredKeys() []ObjectKey {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	keys := make([]ObjectKey, 0, len(s.monitors))
+	for key := range s.monitors {
+		keys = append(keys, key)
+	}
+	sortObjectKeys(keys)
+
+	return keys
+}
+
+// sortObjectKeys sorts the keys by namespace and name.
+func sortObjectKeys(keys []ObjectKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Namespace != keys[j].Namespace {
+			return keys[i].Namespace < keys[j].Namespace
+		}
+		return keys[i].Name < keys[j].Name
+	})
+}
+
+// sortSecretSummaries sorts the summaries by namespace and name.
+func sortSecretSummaries(summaries []SecretSummary) {
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Namespace != summaries[j].Namespace {
+			return summaries[i].Namespace < summaries[j].Namespace
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+}
+
+// GetSecret retrieves the secret object from the informer's cache.
+// Returns ErrCacheNotSynced if the cache could not be synced, ErrSecretDeleted if the secret was
+// removed from the cache after its deletion was observed, and ErrSecretNotFound if it is not in the cache otherwise.
 func (s *secretMonitor) GetSecret(ctx context.Context, handlerRegistration SecretEventHandlerRegistration) (*corev1.Secret, error) {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
@@ -186,7 +1282,6 @@ func (s *secretMonitor) GetSecret(ctx context.Context, handlerRegistration Secre
 		return nil, fmt.Errorf("nil secret handler registration is provided")
 	}
 	key := handlerRegistration.GetKey()
-	secretName := key.Name
 
 	// check if secret informer exists
 	m, exists := s.monitors[key]
@@ -195,23 +1290,115 @@ func (s *secretMonitor) GetSecret(ctx context.Context, handlerRegistration Secre
 	}
 
 	// wait for informer store sync, to load secrets
-	if !cache.WaitForCacheSync(ctx.Done(), handlerRegistration.HasSynced) {
-		return nil, fmt.Errorf("failed waiting for cache sync")
+	if !s.waitForSync(ctx, handlerRegistration.HasSynced) {
+		return nil, fmt.Errorf("%w for item key %v", ErrCacheNotSynced, key)
 	}
 
-	uncast, exists, err := m.itemMonitor.GetItem()
+	secret, err := getSecretFromMonitor(m.itemMonitor)
+	if err == nil && s.onSecretAccessed != nil {
+		s.onSecretAccessed(key, ReaderFromContext(ctx))
+	}
+	return secret, err
+}
+
+// ListHandlers returns the handler registrations of the monitor of the secret, nil if the secret is not monitored.
+func (s *secretMonitor) ListHandlers(key ObjectKey) []SecretEventHandlerRegistration {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	m, exists := s.monitors[key]
+	if !exists {
+		return nil
+	}
+	return m.itemMonitor.ListHandlers()
+}
+
+// ByIndex returns the secrets matching the index from the caches of every monitored secret.
+// Informers provided with AddSecretEventHandlerWithInformer which lack the index are skipped.
+func (s *secretMonitor) ByIndex(indexName, indexedValue string) ([]*corev1.Secret, error) {
+	if _, exists := s.indexers[indexName]; !exists {
+		return nil, fmt.Errorf("index with name %s does not exist", indexName)
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	secrets := []*corev1.Secret{}
+	for key, m := range s.monitors {
+		informer, ok := m.itemMonitor.currentInformer().(cache.SharedIndexInformer)
+		if !ok {
+			continue
+		}
+		indexer := informer.GetIndexer()
+		if _, exists := indexer.GetIndexers()[indexName]; !exists {
+			klog.V(5).Info("skipping informer without index ", indexName, " item key ", key)
+			continue
+		}
+		objs, err := indexer.ByIndex(indexName, indexedValue)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			// only the monitored secret, as another one could have been let in by the field selector
+			if secret, ok := obj.(*corev1.Secret); ok && checkSecretKey(secret, key) == nil {
+				secrets = append(secrets, secret)
+			}
+		}
+	}
+	sortSecrets(secrets)
+
+	return secrets, nil
+}
+
+// sortSecrets sorts the secrets by namespace and name.
+func sortSecrets(secrets []*corev1.Secret) {
+	sort.Slice(secrets, func(i, j int) bool {
+		if secrets[i].Namespace != secrets[j].Namespace {
+			return secrets[i].Namespace < secrets[j].Namespace
+		}
+		return secrets[i].Name < secrets[j].Name
+	})
+}
+
+// getSecretFromMonitor reads the secret monitored by the given singleItemMonitor from its cache.
+// It never returns a nil secret without error, see checkSecretKey.
+func getSecretFromMonitor(m *singleItemMonitor) (*corev1.Secret, error) {
+	uncast, exists, err := m.GetItem()
 
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, apierrors.NewNotFound(corev1.Resource("secrets"), secretName)
+		if m.lastObservedDeleted.Load() {
+			return nil, fmt.Errorf("%w: %w", ErrSecretDeleted, apierrors.NewNotFound(corev1.Resource("secrets"), m.key.Name))
+		}
+		return nil, fmt.Errorf("%w: %w", ErrSecretNotFound, apierrors.NewNotFound(corev1.Resource("secrets"), m.key.Name))
 	}
 
+	// the store holds a tombstone if the deletion of the secret was missed, e.g. during a relist
+	if _, deleted := uncast.(cache.DeletedFinalStateUnknown); deleted {
+		return nil, fmt.Errorf("%w: final state unknown: %w", ErrSecretDeleted, apierrors.NewNotFound(corev1.Resource("secrets"), m.key.Name))
+	}
 	secret, ok := uncast.(*corev1.Secret)
 	if !ok {
 		return nil, fmt.Errorf("unexpected type: %T", uncast)
 	}
+	if err := checkSecretKey(secret, m.key); err != nil {
+		return nil, err
+	}
 
 	return secret, nil
 }
+
+// checkSecretKey returns ErrSecretKeyMismatch if the secret is not the one identified by key,
+// guarding against a field selector letting another secret into the cache.
+// It returns an error for a nil secret too, so that callers never return a nil secret without error.
+func checkSecretKey(secret *corev1.Secret, key ObjectKey) error {
+	if secret == nil {
+		return fmt.Errorf("unexpected nil secret for item key %v", key)
+	}
+	if secret.Namespace != key.Namespace || secret.Name != key.Name {
+		return fmt.Errorf("%w: got secret %s/%s for item key %v", ErrSecretKeyMismatch, secret.Namespace, secret.Name, key)
+	}
+	return nil
+}
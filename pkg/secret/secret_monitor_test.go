@@ -1,13 +1,38 @@
 package secret
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/go-logr/logr/funcr"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	restfake "k8s.io/client-go/rest/fake"
+	clienttesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
 )
 
 func TestAddSecretEventHandler(t *testing.T) {
@@ -278,3 +303,1815 @@ func TestGetSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistrationGetSecret(t *testing.T) {
+	var (
+		namespace  = "testNamespace"
+		secretName = "testSecretName"
+	)
+
+	secret := fakeSecret(namespace, secretName)
+	kubeClient := fake.NewSimpleClientset(secret)
+	fakeInformer := fakeSecretInformer(context.TODO(), kubeClient, namespace, secretName)
+	sm := secretMonitor{
+		kubeClient: kubeClient,
+		monitors:   map[ObjectKey]*monitoredItem{},
+	}
+	h, err := sm.addSecretEventHandler(context.TODO(), namespace, secretName, cache.ResourceEventHandlerFuncs{}, fakeInformer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// read through the registration while the monitor is running
+	gotSec, err := h.GetSecret()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(secret, gotSec) {
+		t.Errorf("expected %v got %v", secret, gotSec)
+	}
+
+	// removing the only handler stops the monitor
+	if err := sm.RemoveSecretEventHandler(h); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := h.GetSecret(); err == nil {
+		t.Error("expected error after monitor is stopped, got nil")
+	}
+}
+
+func TestShutdownNamespace(t *testing.T) {
+	keys := []ObjectKey{
+		{Namespace: "ns1", Name: "secret1"},
+		{Namespace: "ns1", Name: "secret2"},
+		{Namespace: "ns2", Name: "secret1"},
+		{Namespace: "ns2", Name: "secret3"},
+	}
+
+	fakeKubeClient := fake.NewSimpleClientset()
+	sm := secretMonitor{
+		kubeClient: fakeKubeClient,
+		monitors:   map[ObjectKey]*monitoredItem{},
+	}
+	for _, k := range keys {
+		fakeInformer := fakeSecretInformer(context.TODO(), fakeKubeClient, k.Namespace, k.Name)
+		if _, err := sm.addSecretEventHandler(context.TODO(), k.Namespace, k.Name, cache.ResourceEventHandlerFuncs{}, fakeInformer); err != nil {
+			t.Fatal(err)
+		}
+	}
+	itemMonitors := map[ObjectKey]*singleItemMonitor{}
+	for k, m := range sm.monitors {
+		itemMonitors[k] = m.itemMonitor
+	}
+
+	if err := sm.ShutdownNamespace("ns1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, k := range keys {
+		_, exists := sm.monitors[k]
+		if k.Namespace == "ns1" {
+			if exists {
+				t.Errorf("expected key %v to be removed", k)
+			}
+			if !itemMonitors[k].IsStopped() {
+				t.Errorf("expected informer for key %v to be stopped", k)
+			}
+			continue
+		}
+		if !exists {
+			t.Errorf("expected key %v to exist", k)
+		}
+		if itemMonitors[k].IsStopped() {
+			t.Errorf("expected informer for key %v to be running", k)
+		}
+	}
+
+	// nothing left to shutdown
+	if err := sm.ShutdownNamespace("ns1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFieldSelector(t *testing.T) {
+	secretName := "secret"
+
+	scenarios := []struct {
+		name           string
+		opts           []Option
+		expectSelector string
+		expectErr      bool
+	}{
+		{
+			name:           "default selector by name",
+			expectSelector: "metadata.name=secret",
+		},
+		{
+			name: "builder combining name and type",
+			opts: []Option{
+				WithFieldSelectorBuilder(func(secretName string) fields.Selector {
+					return fields.AndSelectors(
+						fields.OneTermEqualSelector("metadata.name", secretName),
+						fields.OneTermEqualSelector("type", string(corev1.SecretTypeTLS)),
+					)
+				}),
+			},
+			expectSelector: "metadata.name=secret,type=kubernetes.io/tls",
+		},
+		{
+			name: "builder without name",
+			opts: []Option{
+				WithFieldSelectorBuilder(func(_ string) fields.Selector {
+					return fields.OneTermEqualSelector("type", string(corev1.SecretTypeTLS))
+				}),
+			},
+			expectErr: true,
+		},
+		{
+			name: "builder with a different name",
+			opts: []Option{
+				WithFieldSelectorBuilder(func(_ string) fields.Selector {
+					return fields.OneTermEqualSelector("metadata.name", "other")
+				}),
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			sm := newSecretMonitor(fake.NewSimpleClientset(), s.opts...)
+
+			selector, err := sm.fieldSelector(secretName)
+			if (err != nil) != s.expectErr {
+				t.Fatalf("expected errors to be %t, but got %v", s.expectErr, err)
+			}
+			if s.expectErr {
+				// the selector is validated before any informer is created
+				if _, err := sm.AddSecretEventHandler(context.TODO(), "ns", secretName, cache.ResourceEventHandlerFuncs{}); err == nil {
+					t.Error("expected AddSecretEventHandler to fail, got nil")
+				}
+				return
+			}
+			if selector.String() != s.expectSelector {
+				t.Errorf("expected selector %q, got %q", s.expectSelector, selector.String())
+			}
+		})
+	}
+}
+
+func TestListCachedSecrets(t *testing.T) {
+	tlsSecret := fakeSecret("ns2", "secret2")
+	tlsSecret.Type = corev1.SecretTypeTLS
+	tlsSecret.ResourceVersion = "2"
+	opaqueSecret := fakeSecret("ns1", "secret1")
+	opaqueSecret.ResourceVersion = "1"
+
+	fakeKubeClient := fake.NewSimpleClientset(tlsSecret, opaqueSecret)
+	sm := secretMonitor{
+		kubeClient: fakeKubeClient,
+		monitors:   map[ObjectKey]*monitoredItem{},
+	}
+	for _, k := range []ObjectKey{
+		{Namespace: "ns2", Name: "secret2"},
+		{Namespace: "ns1", Name: "secret1"},
+		{Namespace: "ns1", Name: "missing"},
+	} {
+		fakeInformer := fakeSecretInformer(context.TODO(), fakeKubeClient, k.Namespace, k.Name)
+		if _, err := sm.addSecretEventHandler(context.TODO(), k.Namespace, k.Name, cache.ResourceEventHandlerFuncs{}, fakeInformer); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []SecretSummary{
+		{Namespace: "ns1", Name: "missing", Synced: true},
+		{Namespace: "ns1", Name: "secret1", Type: string(corev1.SecretTypeOpaque), ResourceVersion: "1", Synced: true},
+		{Namespace: "ns2", Name: "secret2", Type: string(corev1.SecretTypeTLS), ResourceVersion: "2", Synced: true},
+	}
+	if got := sm.ListCachedSecrets(); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+
+	scenarios := []struct {
+		name         string
+		idleTimeout  time.Duration
+		step         time.Duration
+		readd        bool
+		expectExists bool
+	}{
+		{
+			name:         "informer stopped immediately without idle timeout",
+			expectExists: false,
+		},
+		{
+			name:         "informer kept within the idle timeout",
+			idleTimeout:  time.Hour,
+			step:         59 * time.Minute,
+			expectExists: true,
+		},
+		{
+			name:         "informer reused within the idle timeout",
+			idleTimeout:  time.Minute,
+			readd:        true,
+			step:         2 * time.Minute,
+			expectExists: true,
+		},
+		{
+			name:         "informer stopped after the idle timeout",
+			idleTimeout:  time.Minute,
+			step:         2 * time.Minute,
+			expectExists: false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			fakeKubeClient := fake.NewSimpleClientset()
+			fakeClock := clocktesting.NewFakeClock(time.Now())
+			sm := newSecretMonitor(fakeKubeClient, WithIdleTimeout(s.idleTimeout), WithClock(fakeClock))
+
+			fakeInformer := fakeSecretInformer(context.TODO(), fakeKubeClient, key.Namespace, key.Name)
+			h, err := sm.addSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}, fakeInformer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			itemMonitor := sm.monitors[key].itemMonitor
+
+			if err := sm.RemoveSecretEventHandler(h); err != nil {
+				t.Fatal(err)
+			}
+			if s.readd {
+				unusedInformer := fakeSecretInformer(context.TODO(), fakeKubeClient, key.Namespace, key.Name)
+				if _, err := sm.addSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}, unusedInformer); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// expired idle timers run synchronously within Step
+			fakeClock.Step(s.step)
+
+			sm.lock.RLock()
+			defer sm.lock.RUnlock()
+			m, exists := sm.monitors[key]
+			if exists != s.expectExists {
+				t.Fatalf("expected monitor to exist %t, got %t", s.expectExists, exists)
+			}
+			if itemMonitor.IsStopped() == s.expectExists {
+				t.Errorf("expected informer stopped to be %t", !s.expectExists)
+			}
+			if exists && m.itemMonitor != itemMonitor {
+				t.Error("expected the idle informer to be reused")
+			}
+		})
+	}
+}
+
+func TestStartupJitter(t *testing.T) {
+	maxJitter := 50 * time.Millisecond
+	sm := newSecretMonitor(fake.NewSimpleClientset(), WithStartupJitter(maxJitter))
+
+	for i := 0; i < 100; i++ {
+		if delay := sm.jitteredDelay(); delay < 0 || delay >= maxJitter {
+			t.Fatalf("expected delay within [0, %v), got %v", maxJitter, delay)
+		}
+	}
+
+	key := NewObjectKey("ns", "secret")
+	fakeInformer := fakeSecretInformer(context.TODO(), sm.kubeClient.(*fake.Clientset), key.Namespace, key.Name)
+	if _, err := sm.addSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}, fakeInformer); err != nil {
+		t.Fatal(err)
+	}
+	if sm.monitors[key].itemMonitor.startupDelay == nil {
+		t.Error("expected startup delay to be configured on the informer")
+	}
+}
+
+// afterCountingClock is a fake clock counting the calls to After, e.g. the startup delays of the informers.
+type afterCountingClock struct {
+	*clocktesting.FakeClock
+	afters atomic.Int32
+}
+
+func (c *afterCountingClock) After(d time.Duration) <-chan time.Time {
+	c.afters.Add(1)
+	return c.FakeClock.After(d)
+}
+
+func TestStartupJitterConcurrentRegistrations(t *testing.T) {
+	keys := []ObjectKey{NewObjectKey("ns", "secret1"), NewObjectKey("ns", "secret2")}
+	kubeClient := fake.NewSimpleClientset(fakeSecret(keys[0].Namespace, keys[0].Name), fakeSecret(keys[1].Namespace, keys[1].Name))
+	fakeClock := &afterCountingClock{FakeClock: clocktesting.NewFakeClock(time.Now())}
+	sm := newSecretMonitor(kubeClient, WithStartupJitter(time.Second), WithClock(fakeClock))
+
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		wg.Add(1)
+		go func(key ObjectKey) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			if _, err := sm.AddSecretEventHandler(ctx, key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}); err != nil {
+				t.Errorf("unexpected error for item key %v: %v", key, err)
+			}
+		}(key)
+	}
+
+	// both informers wait for their startup delay at the same time, which never happens if the
+	// informers are started one after the other
+	if err := wait.PollUntilContextTimeout(context.TODO(), time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return fakeClock.afters.Load() == int32(len(keys)), nil
+	}); err != nil {
+		t.Errorf("expected the startup delays of the informers to overlap, got %d delays", fakeClock.afters.Load())
+	}
+	// the lock of the monitor is not held while the items are pending
+	sm.lock.RLock()
+	pending := len(sm.pending)
+	sm.lock.RUnlock()
+	if pending != len(keys) {
+		t.Errorf("expected %d pending items, got %d", len(keys), pending)
+	}
+	fakeClock.Step(time.Second)
+	wg.Wait()
+
+	if got := sm.ListMonitoredKeys(); !reflect.DeepEqual(got, keys) {
+		t.Errorf("expected monitored keys %v, got %v", keys, got)
+	}
+}
+
+func TestConcurrentRegistrationsOfPendingItem(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	sm := newSecretMonitor(fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name)), WithStartupJitter(50*time.Millisecond))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the handlers share the informer started by the first registration
+	sm.lock.RLock()
+	defer sm.lock.RUnlock()
+	if m := sm.monitors[key]; m == nil || m.numHandlers != 5 {
+		t.Errorf("expected a single monitor with 5 handlers, got %+v", m)
+	}
+	if len(sm.pending) != 0 {
+		t.Errorf("expected no pending item, got %v", sm.pending)
+	}
+}
+
+func TestGetSecretDeleted(t *testing.T) {
+	var (
+		namespace  = "testNamespace"
+		secretName = "testSecretName"
+	)
+
+	kubeClient := fake.NewSimpleClientset(fakeSecret(namespace, secretName))
+	sm := secretMonitor{
+		kubeClient: kubeClient,
+		monitors:   map[ObjectKey]*monitoredItem{},
+	}
+
+	deleted := make(chan struct{})
+	fakeInformer := fakeSecretInformer(context.TODO(), kubeClient, namespace, secretName)
+	h, err := sm.addSecretEventHandler(context.TODO(), namespace, secretName, cache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(_ interface{}) { close(deleted) },
+	}, fakeInformer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sm.GetSecret(context.TODO(), h); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := kubeClient.CoreV1().Secrets(namespace).Delete(context.TODO(), secretName, metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-deleted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for delete event")
+	}
+
+	_, err = sm.GetSecret(context.TODO(), h)
+	if !errors.Is(err, ErrSecretDeleted) {
+		t.Fatalf("expected %v, got %v", ErrSecretDeleted, err)
+	}
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected a NotFound error, got %v", err)
+	}
+
+	// a secret which never existed is reported as not found
+	fakeInformer = fakeSecretInformer(context.TODO(), kubeClient, namespace, "missing")
+	h, err = sm.addSecretEventHandler(context.TODO(), namespace, "missing", cache.ResourceEventHandlerFuncs{}, fakeInformer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = sm.GetSecret(context.TODO(), h)
+	if !errors.Is(err, ErrSecretNotFound) || errors.Is(err, ErrSecretDeleted) {
+		t.Fatalf("expected %v, got %v", ErrSecretNotFound, err)
+	}
+}
+
+type contextKey string
+
+// recordingKubeClient records the context and options of the secret List calls.
+type recordingKubeClient struct {
+	kubernetes.Interface
+	lock  sync.Mutex
+	lists []recordedList
+}
+
+type recordedList struct {
+	ctx     context.Context
+	options metav1.ListOptions
+}
+
+func (c *recordingKubeClient) CoreV1() corev1client.CoreV1Interface {
+	return &recordingCoreV1{CoreV1Interface: c.Interface.CoreV1(), client: c}
+}
+
+func (c *recordingKubeClient) recordedLists() []recordedList {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]recordedList{}, c.lists...)
+}
+
+type recordingCoreV1 struct {
+	corev1client.CoreV1Interface
+	client *recordingKubeClient
+}
+
+func (c *recordingCoreV1) Secrets(namespace string) corev1client.SecretInterface {
+	return &recordingSecrets{SecretInterface: c.CoreV1Interface.Secrets(namespace), client: c.client}
+}
+
+type recordingSecrets struct {
+	corev1client.SecretInterface
+	client *recordingKubeClient
+}
+
+func (s *recordingSecrets) List(ctx context.Context, opts metav1.ListOptions) (*corev1.SecretList, error) {
+	s.client.lock.Lock()
+	s.client.lists = append(s.client.lists, recordedList{ctx: ctx, options: opts})
+	s.client.lock.Unlock()
+	return s.SecretInterface.List(ctx, opts)
+}
+
+func TestCreateSecretInformerContext(t *testing.T) {
+	var (
+		namespace  = "ns"
+		secretName = "secret"
+		ctxKey     = contextKey("test")
+	)
+
+	kubeClient := &recordingKubeClient{Interface: fake.NewSimpleClientset(fakeSecret(namespace, secretName))}
+	sm := newSecretMonitor(kubeClient)
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.TODO(), ctxKey, "value"))
+	h, err := sm.AddSecretEventHandler(ctx, namespace, secretName, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.GetSecret(ctx, h); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lists := kubeClient.recordedLists()
+	if len(lists) == 0 {
+		t.Fatal("expected secrets to be listed")
+	}
+	for _, l := range lists {
+		if l.ctx.Value(ctxKey) != "value" {
+			t.Error("expected the monitor context to be used for the list call")
+		}
+		if l.options.FieldSelector != "metadata.name="+secretName {
+			t.Errorf("expected field selector metadata.name=%s, got %q", secretName, l.options.FieldSelector)
+		}
+	}
+
+	// cancelling the monitor context is seen by the list call context
+	cancel()
+	if lists[0].ctx.Err() == nil {
+		t.Error("expected list call context to be cancelled")
+	}
+}
+
+func TestReregisterAll(t *testing.T) {
+	var (
+		namespace  = "ns"
+		secretName = "secret"
+		key        = NewObjectKey(namespace, secretName)
+	)
+
+	kubeClient := fake.NewSimpleClientset(fakeSecret(namespace, secretName))
+	sm := newSecretMonitor(kubeClient)
+	h, err := sm.AddSecretEventHandler(context.TODO(), namespace, secretName, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousInformer := sm.monitors[key].itemMonitor.currentInformer()
+	previousHandle := h.GetHandler()
+
+	// change the secret out of band, the re-list must observe it
+	updated := fakeSecret(namespace, secretName)
+	updated.Data = map[string][]byte{"test": {5, 6, 7, 8}}
+	if err := kubeClient.Tracker().Update(corev1.SchemeGroupVersion.WithResource("secrets"), updated, namespace); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sm.ReregisterAll(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sm.monitors[key].itemMonitor.currentInformer() == previousInformer {
+		t.Fatal("expected the informer to be recreated")
+	}
+	if h.GetHandler() == previousHandle {
+		t.Error("expected the registration to be moved to the new informer")
+	}
+
+	// the registration held by the caller is still valid
+	gotSec, err := sm.GetSecret(context.TODO(), h)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(updated.Data, gotSec.Data) {
+		t.Errorf("expected data %v, got %v", updated.Data, gotSec.Data)
+	}
+	if gotSec, err = h.GetSecret(); err != nil || !reflect.DeepEqual(updated.Data, gotSec.Data) {
+		t.Errorf("expected data %v through the registration, got %v, err %v", updated.Data, gotSec, err)
+	}
+
+	if err := sm.RemoveSecretEventHandler(h); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, exists := sm.monitors[key]; exists {
+		t.Error("expected monitor to be removed")
+	}
+}
+
+func TestAddSecretEventHandlerValidation(t *testing.T) {
+	scenarios := []struct {
+		name       string
+		namespace  string
+		secretName string
+		expectErr  error
+	}{
+		{
+			name:       "empty secret name",
+			namespace:  "ns",
+			secretName: "",
+			expectErr:  ErrEmptySecretName,
+		},
+		{
+			name:       "empty namespace",
+			namespace:  "",
+			secretName: "secret",
+			expectErr:  ErrEmptyNamespace,
+		},
+		{
+			name:       "valid namespace and secret name",
+			namespace:  "ns",
+			secretName: "secret",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			sm := newSecretMonitor(fake.NewSimpleClientset())
+			_, err := sm.AddSecretEventHandler(context.TODO(), s.namespace, s.secretName, cache.ResourceEventHandlerFuncs{})
+			if !errors.Is(err, s.expectErr) {
+				t.Fatalf("expected %v, got %v", s.expectErr, err)
+			}
+			if s.expectErr != nil && len(sm.monitors) != 0 {
+				t.Errorf("expected no monitor to be created, got %d", len(sm.monitors))
+			}
+		})
+	}
+}
+
+func TestOnSynced(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	synced := make(chan ObjectKey, 10)
+	sm := newSecretMonitor(fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name)), WithOnSynced(func(k ObjectKey) {
+		synced <- k
+	}))
+
+	// adding more handlers to the same informer must not invoke the callback again
+	for i := 0; i < 3; i++ {
+		if _, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case k := <-synced:
+		if k != key {
+			t.Errorf("expected callback for %v, got %v", key, k)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the synced callback")
+	}
+	select {
+	case k := <-synced:
+		t.Errorf("expected exactly one callback, got another one for %v", k)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSetKubeClient(t *testing.T) {
+	namespace := "ns"
+	oldKubeClient := fake.NewSimpleClientset(fakeSecret(namespace, "old"))
+	newKubeClient := fake.NewSimpleClientset(fakeSecret(namespace, "new"))
+	sm := newSecretMonitor(oldKubeClient)
+
+	oldRegistration, err := sm.AddSecretEventHandler(context.TODO(), namespace, "old", cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sm.SetKubeClient(newKubeClient)
+
+	newRegistration, err := sm.AddSecretEventHandler(context.TODO(), namespace, "new", cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.GetSecret(context.TODO(), newRegistration); err != nil {
+		t.Errorf("expected the new monitor to list from the new client, got %v", err)
+	}
+	// the existing monitor keeps the previous client
+	if _, err := sm.GetSecret(context.TODO(), oldRegistration); err != nil {
+		t.Errorf("expected the existing monitor to keep its secret, got %v", err)
+	}
+
+	for _, action := range oldKubeClient.Actions() {
+		if action.GetVerb() == "list" && action.(clienttesting.ListAction).GetListRestrictions().Fields.String() == "metadata.name=new" {
+			t.Errorf("expected the new monitor not to use the previous client")
+		}
+	}
+}
+
+func TestAddSecretEventHandlerWithInformer(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	kubeClient := fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name))
+	// the monitor's own client has no secret, so reads must come from the provided informer
+	sm := newSecretMonitor(fake.NewSimpleClientset())
+
+	informer := fakeSecretInformer(context.TODO(), kubeClient, key.Namespace, key.Name)
+	registration, err := sm.AddSecretEventHandlerWithInformer(context.TODO(), key, informer, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registration.GetKey() != key {
+		t.Errorf("expected key %v, got %v", key, registration.GetKey())
+	}
+	if got := sm.monitors[key].itemMonitor.currentInformer(); got != informer {
+		t.Error("expected the provided informer to be used")
+	}
+	if _, err := sm.GetSecret(context.TODO(), registration); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a second informer for the same key is ignored
+	other := fakeSecretInformer(context.TODO(), kubeClient, key.Namespace, key.Name)
+	if _, err := sm.AddSecretEventHandlerWithInformer(context.TODO(), key, other, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	if sm.monitors[key].numHandlers != 2 {
+		t.Errorf("expected 2 handlers, got %d", sm.monitors[key].numHandlers)
+	}
+
+	// provided informers are not recreated
+	if err := sm.ReregisterAll(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := sm.monitors[key].itemMonitor.currentInformer(); got != informer {
+		t.Error("expected the provided informer to be kept by ReregisterAll")
+	}
+
+	if _, err := sm.AddSecretEventHandlerWithInformer(context.TODO(), NewObjectKey("ns", "nil"), nil, cache.ResourceEventHandlerFuncs{}); err == nil {
+		t.Error("expected an error for a nil informer")
+	}
+}
+
+func TestAddSecretEventHandlerReplacesStoppedMonitor(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	sm := newSecretMonitor(fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name)))
+
+	// cancelling the context stops the informer, but keeps the monitor
+	ctx, cancel := context.WithCancel(context.Background())
+	stale, err := sm.AddSecretEventHandler(ctx, key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stopped := sm.monitors[key].itemMonitor
+	cancel()
+	if err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(_ context.Context) (bool, error) {
+		return stopped.IsStopped(), nil
+	}); err != nil {
+		t.Fatal("timed out waiting for the informer to stop")
+	}
+
+	registration, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatalf("expected the stopped monitor to be replaced, got %v", err)
+	}
+	if sm.monitors[key].itemMonitor == stopped {
+		t.Error("expected a new monitor")
+	}
+	if _, err := sm.GetSecret(context.TODO(), registration); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// the stale registration doesn't affect the new monitor
+	if err := sm.RemoveSecretEventHandler(stale); err == nil {
+		t.Error("expected an error removing a registration of the stopped monitor")
+	}
+	if sm.monitors[key].numHandlers != 1 {
+		t.Errorf("expected 1 handler, got %d", sm.monitors[key].numHandlers)
+	}
+}
+
+func TestConcurrentAddRemoveSecretEventHandler(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	sm := newSecretMonitor(fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name)))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				registration, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{})
+				if err != nil {
+					errs <- err
+					return
+				}
+				if err := sm.RemoveSecretEventHandler(registration); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(sm.monitors) != 0 {
+		t.Errorf("expected no monitor left, got %d", len(sm.monitors))
+	}
+}
+
+func TestMinimalSecretCache(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	secret := fakeSecret(key.Namespace, key.Name)
+	secret.Data = map[string][]byte{
+		corev1.TLSCertKey:       []byte("cert"),
+		corev1.TLSPrivateKeyKey: []byte("key"),
+		"ca.crt":                []byte("ca"),
+		"bundle.pem":            []byte("extra"),
+		"other":                 []byte("extra"),
+	}
+	sm := newSecretMonitor(fake.NewSimpleClientset(secret), WithMinimalSecretCache())
+
+	registration, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedData := map[string][]byte{
+		corev1.TLSCertKey:       []byte("cert"),
+		corev1.TLSPrivateKeyKey: []byte("key"),
+		"ca.crt":                []byte("ca"),
+	}
+
+	gotSecret, err := sm.GetSecret(context.TODO(), registration)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(gotSecret.Data, expectedData) {
+		t.Errorf("expected cached data %v, got %v", expectedData, gotSecret.Data)
+	}
+	item, _, err := sm.monitors[key].itemMonitor.GetItem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(item.(*corev1.Secret).Data, expectedData) {
+		t.Errorf("expected stored data %v, got %v", expectedData, item.(*corev1.Secret).Data)
+	}
+}
+
+func TestSkipInitialEvents(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	kubeClient := fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name))
+	sm := newSecretMonitor(kubeClient, WithSkipInitialEvents())
+
+	added := make(chan struct{}, 2)
+	updated := make(chan struct{}, 2)
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(_ interface{}) {
+			added <- struct{}{}
+		},
+		UpdateFunc: func(_, _ interface{}) {
+			updated <- struct{}{}
+		},
+	}
+	// the second handler is added to an already running informer
+	for i := 0; i < 2; i++ {
+		registration, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, handler)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cache.WaitForCacheSync(context.TODO().Done(), registration.HasSynced) {
+			t.Fatal("timed out waiting for the handler to sync")
+		}
+	}
+
+	updatedSecret := fakeSecret(key.Namespace, key.Name)
+	updatedSecret.Labels = map[string]string{"updated": "true"}
+	if _, err := kubeClient.CoreV1().Secrets(key.Namespace).Update(context.TODO(), updatedSecret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-updated:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for update event")
+		}
+	}
+	select {
+	case <-added:
+		t.Error("expected the initial add event to be skipped")
+	default:
+	}
+}
+
+func TestLoggerWithKeyValues(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	var (
+		lock sync.Mutex
+		logs []string
+	)
+	logger := funcr.New(func(prefix, args string) {
+		lock.Lock()
+		defer lock.Unlock()
+		logs = append(logs, args)
+	}, funcr.Options{Verbosity: 5})
+	sm := newSecretMonitor(fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name)), WithLogger(logger))
+
+	added := make(chan struct{}, 1)
+	registration, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(_ interface{}) {
+			added <- struct{}{}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sm.RemoveSecretEventHandler(registration)
+	select {
+	case <-added:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for add event")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	for _, log := range logs {
+		if strings.Contains(log, `"msg"="delivering secret event"`) && strings.Contains(log, `"event"="add"`) {
+			if !strings.Contains(log, `"namespace"="ns"`) || !strings.Contains(log, `"secret"="secret"`) {
+				t.Errorf("expected the key of the secret in the log, got %s", log)
+			}
+			return
+		}
+	}
+	t.Errorf("expected a log of the delivered add event, got %v", logs)
+}
+
+// fixedKeyInformer is a SharedInformer whose store indexes every object under the same key.
+type fixedKeyInformer struct {
+	cache.SharedInformer
+	store cache.Store
+}
+
+func (i *fixedKeyInformer) GetStore() cache.Store {
+	return i.store
+}
+
+func TestGetSecretKeyMismatch(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	store := cache.NewStore(func(_ interface{}) (string, error) {
+		return key.Namespace + "/" + key.Name, nil
+	})
+	if err := store.Add(fakeSecret(key.Namespace, "other")); err != nil {
+		t.Fatal(err)
+	}
+	informer := &fixedKeyInformer{
+		SharedInformer: fakeSecretInformer(context.TODO(), fake.NewSimpleClientset(), key.Namespace, key.Name),
+		store:          store,
+	}
+
+	_, err := getSecretFromMonitor(newSingleItemMonitor(key, informer))
+	if !errors.Is(err, ErrSecretKeyMismatch) {
+		t.Fatalf("expected %v, got %v", ErrSecretKeyMismatch, err)
+	}
+}
+
+func TestSecretInformerFiltersOtherSecrets(t *testing.T) {
+	// the fake client ignores field selectors, like API servers not supporting them
+	fakeKubeClient := fake.NewSimpleClientset(fakeSecret("ns", "secret"), fakeSecret("ns", "other"))
+	sm := newSecretMonitor(fakeKubeClient)
+
+	added := make(chan string, 3)
+	_, err := sm.AddSecretEventHandler(context.TODO(), "ns", "secret", cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			added <- obj.(*corev1.Secret).Name
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fakeKubeClient.CoreV1().Secrets("ns").Create(context.TODO(), fakeSecret("ns", "created"), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case name := <-added:
+		if name != "secret" {
+			t.Errorf("expected an event for secret, got %s", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for add event")
+	}
+	select {
+	case name := <-added:
+		t.Errorf("unexpected event for %s", name)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	sm.lock.RLock()
+	keys := sm.monitors[NewObjectKey("ns", "secret")].itemMonitor.informer.GetStore().ListKeys()
+	sm.lock.RUnlock()
+	if !reflect.DeepEqual(keys, []string{"ns/secret"}) {
+		t.Errorf("expected only ns/secret to be cached, got %v", keys)
+	}
+}
+
+func TestGetSecretTombstone(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	store := cache.NewStore(func(_ interface{}) (string, error) {
+		return key.Namespace + "/" + key.Name, nil
+	})
+	if err := store.Add(cache.DeletedFinalStateUnknown{Key: key.Namespace + "/" + key.Name, Obj: fakeSecret(key.Namespace, key.Name)}); err != nil {
+		t.Fatal(err)
+	}
+	informer := &fixedKeyInformer{
+		SharedInformer: fakeSecretInformer(context.TODO(), fake.NewSimpleClientset(), key.Namespace, key.Name),
+		store:          store,
+	}
+
+	gotSecret, err := getSecretFromMonitor(newSingleItemMonitor(key, informer))
+	if !errors.Is(err, ErrSecretDeleted) || !apierrors.IsNotFound(err) {
+		t.Fatalf("expected %v satisfying apierrors.IsNotFound, got %v", ErrSecretDeleted, err)
+	}
+	if gotSecret != nil {
+		t.Errorf("expected no secret, got %v", gotSecret)
+	}
+}
+
+func TestGetSecretNeverReturnsNilWithoutError(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+
+	scenarios := []struct {
+		name    string
+		item    interface{}
+		deleted bool
+	}{
+		{
+			name: "secret",
+			item: fakeSecret(key.Namespace, key.Name),
+		},
+		{
+			name: "missing secret",
+		},
+		{
+			name:    "deleted secret",
+			deleted: true,
+		},
+		{
+			name: "wrong type",
+			item: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}},
+		},
+		{
+			name: "nil secret",
+			item: (*corev1.Secret)(nil),
+		},
+		{
+			name: "other secret",
+			item: fakeSecret(key.Namespace, "other"),
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			store := cache.NewStore(func(_ interface{}) (string, error) {
+				return key.Namespace + "/" + key.Name, nil
+			})
+			if s.item != nil {
+				if err := store.Add(s.item); err != nil {
+					t.Fatal(err)
+				}
+			}
+			informer := &fixedKeyInformer{
+				SharedInformer: fakeSecretInformer(context.TODO(), fake.NewSimpleClientset(), key.Namespace, key.Name),
+				store:          store,
+			}
+			m := newSingleItemMonitor(key, informer)
+			m.lastObservedDeleted.Store(s.deleted)
+
+			gotSecret, err := getSecretFromMonitor(m)
+			if (gotSecret == nil) == (err == nil) {
+				t.Errorf("expected either a secret or an error, got %v, %v", gotSecret, err)
+			}
+		})
+	}
+}
+
+func TestNamespaceTerminating(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	otherKey := NewObjectKey("other", "secret")
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: key.Namespace}},
+		fakeSecret(key.Namespace, key.Name),
+		fakeSecret(otherKey.Namespace, otherKey.Name),
+	)
+	namespaceInformer := informers.NewSharedInformerFactory(kubeClient, 0).Core().V1().Namespaces().Informer()
+	terminating := make(chan string, 1)
+	sm := newSecretMonitor(kubeClient, WithNamespaceInformer(namespaceInformer), WithOnNamespaceTerminating(func(namespace string) {
+		terminating <- namespace
+	}))
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go namespaceInformer.Run(ctx.Done())
+
+	for _, k := range []ObjectKey{key, otherKey} {
+		if _, err := sm.AddSecretEventHandler(context.TODO(), k.Namespace, k.Name, cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !cache.WaitForCacheSync(ctx.Done(), namespaceInformer.HasSynced) {
+		t.Fatal("failed to sync the namespace informer")
+	}
+	// an active namespace is not terminating
+	select {
+	case namespace := <-terminating:
+		t.Fatalf("unexpected termination of namespace %s", namespace)
+	default:
+	}
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Namespace},
+		Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+	}
+	if _, err := kubeClient.CoreV1().Namespaces().UpdateStatus(context.TODO(), namespace, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case namespace := <-terminating:
+		if namespace != key.Namespace {
+			t.Errorf("expected namespace %s, got %s", key.Namespace, namespace)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the namespace terminating callback")
+	}
+
+	sm.lock.RLock()
+	defer sm.lock.RUnlock()
+	if _, exists := sm.monitors[key]; exists {
+		t.Error("expected the monitor of the terminating namespace to be removed")
+	}
+	if _, exists := sm.monitors[otherKey]; !exists {
+		t.Error("expected the monitor of the other namespace to be kept")
+	}
+}
+
+func TestMaxHandlersPerSecret(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	sm := newSecretMonitor(fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name)), WithMaxHandlersPerSecret(2))
+
+	var registrations []SecretEventHandlerRegistration
+	for i := 0; i < 2; i++ {
+		registration, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{})
+		if err != nil {
+			t.Fatalf("unexpected error adding handler %d: %v", i, err)
+		}
+		registrations = append(registrations, registration)
+	}
+
+	if _, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}); !errors.Is(err, ErrTooManyHandlers) {
+		t.Fatalf("expected %v, got %v", ErrTooManyHandlers, err)
+	}
+	if sm.monitors[key].numHandlers != 2 {
+		t.Errorf("expected 2 handlers, got %d", sm.monitors[key].numHandlers)
+	}
+
+	// the cap is per secret
+	if _, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, "other", cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Errorf("unexpected error adding handler of another secret: %v", err)
+	}
+
+	// removing a handler makes room for another one
+	if err := sm.RemoveSecretEventHandler(registrations[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Errorf("unexpected error after removing a handler: %v", err)
+	}
+}
+
+// ownerRouteIndex indexes the secrets by the value of their route label.
+func ownerRouteIndex(obj interface{}) ([]string, error) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return nil, nil
+	}
+	if route, exists := secret.Labels["route"]; exists {
+		return []string{secret.Namespace + "/" + route}, nil
+	}
+	return nil, nil
+}
+
+func TestByIndex(t *testing.T) {
+	owned := fakeSecret("ns", "owned")
+	owned.Labels = map[string]string{"route": "route1"}
+	other := fakeSecret("ns", "other")
+	other.Labels = map[string]string{"route": "route2"}
+	sm := newSecretMonitor(fake.NewSimpleClientset(owned, other, fakeSecret("ns", "unowned")),
+		WithIndexers(cache.Indexers{"ownerRoute": ownerRouteIndex}))
+
+	for _, name := range []string{"owned", "other", "unowned"} {
+		if _, err := sm.AddSecretEventHandler(context.TODO(), "ns", name, cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	secrets, err := sm.ByIndex("ownerRoute", "ns/route1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(secrets) != 1 || secrets[0].Name != "owned" {
+		t.Errorf("expected secret owned, got %v", secrets)
+	}
+
+	// the index survives informer recreation
+	if err := sm.ReregisterAll(); err != nil {
+		t.Fatal(err)
+	}
+	secrets, err = sm.ByIndex("ownerRoute", "ns/route2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(secrets) != 1 || secrets[0].Name != "other" {
+		t.Errorf("expected secret other, got %v", secrets)
+	}
+
+	if _, err := sm.ByIndex("unknown", "ns/route1"); err == nil {
+		t.Error("expected error for unknown index")
+	}
+}
+
+// blockingListKubeClient blocks the secret List calls until their context is done.
+type blockingListKubeClient struct {
+	kubernetes.Interface
+}
+
+func (c *blockingListKubeClient) CoreV1() corev1client.CoreV1Interface {
+	return &blockingListCoreV1{CoreV1Interface: c.Interface.CoreV1()}
+}
+
+type blockingListCoreV1 struct {
+	corev1client.CoreV1Interface
+}
+
+func (c *blockingListCoreV1) Secrets(namespace string) corev1client.SecretInterface {
+	return &blockingListSecrets{SecretInterface: c.CoreV1Interface.Secrets(namespace)}
+}
+
+type blockingListSecrets struct {
+	corev1client.SecretInterface
+}
+
+func (s *blockingListSecrets) List(ctx context.Context, _ metav1.ListOptions) (*corev1.SecretList, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestListWatchTimeout(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	kubeClient := &blockingListKubeClient{Interface: fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name))}
+	listErrors := make(chan error, 10)
+	sm := newSecretMonitor(kubeClient,
+		WithListWatchTimeout(100*time.Millisecond),
+		WithOnListWatchError(func(errKey ObjectKey, err error) {
+			if errKey != key {
+				t.Errorf("expected error for item key %v, got %v", key, errKey)
+			}
+			select {
+			case listErrors <- err:
+			default:
+			}
+		}),
+	)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+	if _, err := sm.AddSecretEventHandler(ctx, key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}); !errors.Is(err, ErrCacheNotSynced) {
+		t.Fatalf("expected %v, got %v", ErrCacheNotSynced, err)
+	}
+
+	select {
+	case err := <-listErrors:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the list error callback")
+	}
+}
+
+func TestWatchTimeoutSeconds(t *testing.T) {
+	for timeout, expected := range map[time.Duration]int64{
+		100 * time.Millisecond: 1,
+		time.Second:            1,
+		90 * time.Second:       90,
+	} {
+		if got := *watchTimeoutSeconds(timeout); got != expected {
+			t.Errorf("expected %d seconds for %v, got %d", expected, timeout, got)
+		}
+	}
+}
+
+func TestHandlersReceiveEventsAfterInformerRecreation(t *testing.T) {
+	namespace, secretName := "ns", "secret"
+	kubeClient := fake.NewSimpleClientset(fakeSecret(namespace, secretName))
+	sm := newSecretMonitor(kubeClient)
+
+	updates := make(chan string, 10)
+	for _, handlerName := range []string{"first", "second"} {
+		handlerName := handlerName
+		if _, err := sm.AddSecretEventHandler(context.TODO(), namespace, secretName, cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(_, newObj interface{}) {
+				if string(newObj.(*corev1.Secret).Data["test"]) == "updated" {
+					updates <- handlerName
+				}
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// recreate the informer twice, the handlers must be carried over every time
+	for i := 0; i < 2; i++ {
+		if err := sm.ReregisterAll(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	updated := fakeSecret(namespace, secretName)
+	updated.Data = map[string][]byte{"test": []byte("updated")}
+	if _, err := kubeClient.CoreV1().Secrets(namespace).Update(context.TODO(), updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	received := map[string]int{}
+	timeout := time.After(5 * time.Second)
+	for len(received) < 2 {
+		select {
+		case handlerName := <-updates:
+			received[handlerName] += 1
+		case <-timeout:
+			t.Fatalf("timed out waiting for the update events, got %v", received)
+		}
+	}
+	// the stopped informers don't deliver the event again
+	select {
+	case handlerName := <-updates:
+		t.Errorf("unexpected duplicate update event for handler %s", handlerName)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRESTClientForNamespace(t *testing.T) {
+	var (
+		lock       sync.Mutex
+		namespaces []string
+		paths      []string
+	)
+	restClientFor := func(namespace string) rest.Interface {
+		lock.Lock()
+		defer lock.Unlock()
+		namespaces = append(namespaces, namespace)
+
+		return &restfake.RESTClient{
+			NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+			GroupVersion:         corev1.SchemeGroupVersion,
+			VersionedAPIPath:     "/api/v1",
+			Client: restfake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				lock.Lock()
+				paths = append(paths, req.URL.Path)
+				lock.Unlock()
+
+				header := http.Header{"Content-Type": []string{"application/json"}}
+				if req.URL.Query().Get("watch") == "true" {
+					// an empty watch, closed right away
+					return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(&bytes.Buffer{})}, nil
+				}
+				list := &corev1.SecretList{
+					TypeMeta: metav1.TypeMeta{Kind: "SecretList", APIVersion: "v1"},
+					Items:    []corev1.Secret{*fakeSecret(namespace, "secret")},
+				}
+				body, err := json.Marshal(list)
+				if err != nil {
+					return nil, err
+				}
+				return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(bytes.NewReader(body))}, nil
+			}),
+		}
+	}
+	// the client of the monitor doesn't hold the secret
+	sm := newSecretMonitor(fake.NewSimpleClientset(), WithRESTClientForNamespace(restClientFor))
+
+	h, err := sm.AddSecretEventHandler(context.TODO(), "ns1", "secret", cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gotSec, err := sm.GetSecret(context.TODO(), h)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotSec.Namespace != "ns1" || gotSec.Name != "secret" {
+		t.Errorf("expected secret ns1/secret, got %s/%s", gotSec.Namespace, gotSec.Name)
+	}
+	if err := sm.RemoveSecretEventHandler(h); err != nil {
+		t.Fatal(err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if !reflect.DeepEqual(namespaces, []string{"ns1"}) {
+		t.Errorf("expected the REST client to be selected for ns1, got %v", namespaces)
+	}
+	if len(paths) == 0 || paths[0] != "/api/v1/namespaces/ns1/secrets" {
+		t.Errorf("expected the secrets of ns1 to be listed with the selected client, got requests %v", paths)
+	}
+}
+
+func TestDiscoveryCheck(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	scenarios := []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		expectErr error
+	}{
+		{
+			name: "secrets served",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "configmaps"}, {Name: "secrets"}}},
+			},
+		},
+		{
+			name: "secrets missing",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "configmaps"}}},
+			},
+			expectErr: ErrSecretsAPIUnavailable,
+		},
+		{
+			name:      "group version missing",
+			expectErr: ErrSecretsAPIUnavailable,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name))
+			kubeClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = s.resources
+			sm := newSecretMonitor(kubeClient, WithDiscoveryCheck())
+
+			_, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{})
+			if !errors.Is(err, s.expectErr) {
+				t.Fatalf("expected %v, got %v", s.expectErr, err)
+			}
+			if _, exists := sm.monitors[key]; exists != (s.expectErr == nil) {
+				t.Errorf("expected the secret to be monitored to be %t", s.expectErr == nil)
+			}
+		})
+	}
+}
+
+func TestListHandlers(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	kubeClient := fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name))
+	sm := newSecretMonitor(kubeClient)
+
+	if handlers := sm.ListHandlers(key); len(handlers) != 0 {
+		t.Errorf("expected no handlers of an unmonitored secret, got %v", handlers)
+	}
+
+	updated := make([]chan struct{}, 3)
+	registrations := make([]SecretEventHandlerRegistration, 3)
+	for i := range registrations {
+		events := make(chan struct{}, 1)
+		updated[i] = events
+		registration, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(_, _ interface{}) {
+				events <- struct{}{}
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		registrations[i] = registration
+	}
+	if handlers := sm.ListHandlers(key); len(handlers) != 3 {
+		t.Fatalf("expected 3 handlers, got %d", len(handlers))
+	}
+
+	// remove the second handler through the listed registration
+	for _, h := range sm.ListHandlers(key) {
+		if h == registrations[1] {
+			if err := sm.RemoveSecretEventHandler(h); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	}
+	handlers := sm.ListHandlers(key)
+	if len(handlers) != 2 || sm.monitors[key].numHandlers != 2 {
+		t.Fatalf("expected 2 handlers, got %d listed and %d counted", len(handlers), sm.monitors[key].numHandlers)
+	}
+	for _, h := range handlers {
+		if h == registrations[1] {
+			t.Error("expected the removed handler not to be listed")
+		}
+	}
+
+	updatedSecret := fakeSecret(key.Namespace, key.Name)
+	updatedSecret.Labels = map[string]string{"updated": "true"}
+	if _, err := kubeClient.CoreV1().Secrets(key.Namespace).Update(context.TODO(), updatedSecret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{0, 2} {
+		select {
+		case <-updated[i]:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for update event of handler %d", i)
+		}
+	}
+	select {
+	case <-updated[1]:
+		t.Error("unexpected update event of the removed handler")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestAddRetry(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	scenarios := []struct {
+		name         string
+		listFailures int
+		expectErr    bool
+		expectLists  int
+	}{
+		{
+			name:         "first attempt fails",
+			listFailures: 1,
+			expectLists:  2,
+		},
+		{
+			name:         "every attempt fails",
+			listFailures: 10,
+			expectErr:    true,
+			expectLists:  3,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name))
+			var (
+				lock      sync.Mutex
+				lists     int
+				listError = errors.New("transient error")
+			)
+			kubeClient.PrependReactor("list", "secrets", func(_ clienttesting.Action) (bool, runtime.Object, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				lists += 1
+				if lists <= s.listFailures {
+					return true, nil, listError
+				}
+				return false, nil, nil
+			})
+			sm := newSecretMonitor(kubeClient, WithAddRetry(wait.Backoff{Duration: 10 * time.Millisecond, Factor: 1, Steps: 3}))
+
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+			_, err := sm.AddSecretEventHandler(ctx, key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{})
+			if s.expectErr {
+				if !errors.Is(err, ErrCacheNotSynced) || !errors.Is(err, listError) {
+					t.Fatalf("expected %v caused by %v, got %v", ErrCacheNotSynced, listError, err)
+				}
+				if _, exists := sm.monitors[key]; exists {
+					t.Error("expected the secret not to be monitored")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			if lists != s.expectLists {
+				t.Errorf("expected %d lists, got %d", s.expectLists, lists)
+			}
+		})
+	}
+}
+
+// secretNames is a component which only reads secrets.
+type secretNames struct {
+	reader SecretReader
+}
+
+// names returns the namespace/name of every monitored secret which exists.
+func TestAddRetryDoesNotBlockOtherItems(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	failingKey := NewObjectKey("ns", "failing")
+	kubeClient := fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name), fakeSecret(failingKey.Namespace, failingKey.Name))
+	kubeClient.PrependReactor("list", "secrets", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.(clienttesting.ListAction).GetListRestrictions().Fields.String() == "metadata.name="+failingKey.Name {
+			return true, nil, errors.New("transient error")
+		}
+		return false, nil, nil
+	})
+	// the backoff only elapses when the fake clock is stepped
+	fakeClock := &afterCountingClock{FakeClock: clocktesting.NewFakeClock(time.Now())}
+	sm := newSecretMonitor(kubeClient, WithClock(fakeClock), WithAddRetry(wait.Backoff{Duration: time.Hour, Factor: 1, Steps: 3}))
+
+	registration, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failingCtx, cancelFailing := context.WithCancel(context.TODO())
+	failed := make(chan error)
+	go func() {
+		_, err := sm.AddSecretEventHandler(failingCtx, failingKey.Namespace, failingKey.Name, cache.ResourceEventHandlerFuncs{})
+		failed <- err
+	}()
+	if err := wait.PollUntilContextTimeout(context.TODO(), time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return fakeClock.afters.Load() > 0, nil
+	}); err != nil {
+		t.Fatalf("expected the failing informer to wait for its retry: %v", err)
+	}
+
+	// the other items are served while the retry is pending
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+	if _, err := sm.GetSecret(ctx, registration); err != nil {
+		t.Errorf("expected GetSecret not to block on the pending retry, got %v", err)
+	}
+	if _, err := sm.AddSecretEventHandler(ctx, key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Errorf("expected AddSecretEventHandler not to block on the pending retry, got %v", err)
+	}
+	if err := sm.RemoveSecretEventHandler(registration); err != nil {
+		t.Errorf("expected RemoveSecretEventHandler not to block on the pending retry, got %v", err)
+	}
+
+	cancelFailing()
+	select {
+	case err := <-failed:
+		if !errors.Is(err, ErrCacheNotSynced) {
+			t.Errorf("expected %v, got %v", ErrCacheNotSynced, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the retry to stop with its context")
+	}
+	if keys := sm.ListMonitoredKeys(); !reflect.DeepEqual(keys, []ObjectKey{key}) {
+		t.Errorf("expected monitored keys %v, got %v", []ObjectKey{key}, keys)
+	}
+}
+
+func (c *secretNames) names() []string {
+	names := []string{}
+	for _, key := range c.reader.ListMonitoredKeys() {
+		secret, err := c.reader.GetSecretByKey(key)
+		if err != nil {
+			continue
+		}
+		names = append(names, secret.Namespace+"/"+secret.Name)
+	}
+	return names
+}
+
+func TestSecretReader(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		fakeSecret("ns2", "secret"),
+		fakeSecret("ns1", "secret2"),
+		fakeSecret("ns1", "secret1"),
+	)
+	sm := newSecretMonitor(kubeClient)
+	c := &secretNames{reader: sm}
+
+	if names := c.names(); len(names) != 0 {
+		t.Errorf("expected no secrets, got %v", names)
+	}
+	for _, key := range []ObjectKey{NewObjectKey("ns2", "secret"), NewObjectKey("ns1", "secret2"), NewObjectKey("ns1", "secret1"), NewObjectKey("ns1", "missing")} {
+		if _, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{"ns1/secret1", "ns1/secret2", "ns2/secret"}
+	if names := c.names(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+	if _, err := sm.GetSecretByKey(NewObjectKey("ns", "unmonitored")); err == nil {
+		t.Error("expected error for an unmonitored secret")
+	}
+}
+
+func TestNamespaceAllowList(t *testing.T) {
+	scenarios := []struct {
+		name       string
+		allowList  []string
+		namespace  string
+		expectsErr bool
+	}{
+		{
+			name:      "empty allow list allows every namespace",
+			namespace: "ns1",
+		},
+		{
+			name:      "allowed namespace",
+			allowList: []string{"ns1", "ns2"},
+			namespace: "ns2",
+		},
+		{
+			name:       "disallowed namespace",
+			allowList:  []string{"ns1", "ns2"},
+			namespace:  "ns3",
+			expectsErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset(fakeSecret(s.namespace, "secret"))
+			sm := newSecretMonitor(kubeClient, WithNamespaceAllowList(s.allowList))
+
+			_, err := sm.AddSecretEventHandler(context.TODO(), s.namespace, "secret", cache.ResourceEventHandlerFuncs{})
+			if s.expectsErr {
+				if !errors.Is(err, ErrNamespaceNotAllowed) {
+					t.Fatalf("expected %v, got %v", ErrNamespaceNotAllowed, err)
+				}
+				if len(sm.monitors) != 0 {
+					t.Error("expected no secret to be monitored")
+				}
+				if actions := kubeClient.Actions(); len(actions) != 0 {
+					t.Errorf("expected no API calls, got %v", actions)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestAddSecretEventHandlerNormalizesKey(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	sm := newSecretMonitor(fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name)))
+
+	for _, secretName := range []string{"secret", "secret ", "\tsecret"} {
+		registration, err := sm.AddSecretEventHandler(context.TODO(), " ns", secretName, cache.ResourceEventHandlerFuncs{})
+		if err != nil {
+			t.Fatalf("unexpected error for secret name %q: %v", secretName, err)
+		}
+		if registration.GetKey() != key {
+			t.Errorf("expected key %v for secret name %q, got %v", key, secretName, registration.GetKey())
+		}
+	}
+	if len(sm.monitors) != 1 || sm.monitors[key].numHandlers != 3 {
+		t.Errorf("expected a single informer with 3 handlers, got monitors %v", sm.monitors)
+	}
+
+	if _, err := sm.AddSecretEventHandler(context.TODO(), "ns", "Invalid_Secret", cache.ResourceEventHandlerFuncs{}); !errors.Is(err, ErrInvalidSecretName) {
+		t.Errorf("expected %v, got %v", ErrInvalidSecretName, err)
+	}
+}
+
+func TestReregister(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	sm := newSecretMonitor(fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name)))
+
+	if err := sm.Reregister(key); err == nil {
+		t.Error("expected error for an unmonitored secret")
+	}
+	registration, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	informer := sm.monitors[key].itemMonitor.currentInformer()
+	if err := sm.Reregister(key); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sm.monitors[key].itemMonitor.currentInformer() == informer {
+		t.Error("expected the informer to be recreated")
+	}
+	if _, err := registration.GetSecret(); err != nil {
+		t.Errorf("expected the registration to remain valid, got %v", err)
+	}
+}
+
+func TestRemoveSecretEventHandlerAlreadyRemoved(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	fakeKubeClient := fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name))
+	newInformer := func() cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(
+			&cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					return fakeKubeClient.CoreV1().Secrets(key.Namespace).List(context.TODO(), options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					return fakeKubeClient.CoreV1().Secrets(key.Namespace).Watch(context.TODO(), options)
+				},
+			},
+			&corev1.Secret{},
+			0,
+			cache.Indexers{},
+		)
+	}
+
+	t.Run("handler removed from the informer", func(t *testing.T) {
+		sm := newSecretMonitor(fake.NewSimpleClientset())
+		informer := newInformer()
+		registration, err := sm.AddSecretEventHandlerWithInformer(context.TODO(), key, informer, cache.ResourceEventHandlerFuncs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		itemMonitor := sm.monitors[key].itemMonitor
+
+		// the informer doesn't fail removing handlers it doesn't know
+		if err := informer.RemoveEventHandler(registration.GetHandler()); err != nil {
+			t.Fatal(err)
+		}
+		if err := sm.RemoveSecretEventHandler(registration); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, exists := sm.monitors[key]; exists {
+			t.Error("expected the monitor to be removed")
+		}
+		if !itemMonitor.IsStopped() {
+			t.Error("expected the informer to be stopped")
+		}
+	})
+
+	t.Run("registration removed twice", func(t *testing.T) {
+		sm := newSecretMonitor(fake.NewSimpleClientset())
+		informer := newInformer()
+		registration, err := sm.AddSecretEventHandlerWithInformer(context.TODO(), key, informer, cache.ResourceEventHandlerFuncs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sm.AddSecretEventHandlerWithInformer(context.TODO(), key, informer, cache.ResourceEventHandlerFuncs{}); err != nil {
+			t.Fatal(err)
+		}
+		itemMonitor := sm.monitors[key].itemMonitor
+		defer itemMonitor.StopInformer()
+
+		if err := sm.RemoveSecretEventHandler(registration); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := sm.RemoveSecretEventHandler(registration); err == nil {
+			t.Error("expected error removing a registration no longer tracked")
+		}
+		if sm.monitors[key].numHandlers != 1 || itemMonitor.IsStopped() {
+			t.Error("expected the remaining handler to stay counted and the informer to keep running")
+		}
+	})
+}
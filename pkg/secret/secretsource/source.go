@@ -0,0 +1,114 @@
+// Package secretsource adapts a secret.SecretMonitor into a controller-runtime source.Source.
+// It is the only package of library-go depending on sigs.k8s.io/controller-runtime.
+package secretsource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// secretSource delivers the events of its secret to the event handler of a controller.
+type secretSource struct {
+	monitor secret.SecretMonitor
+	key     secret.ObjectKey
+}
+
+var _ source.Source = &secretSource{}
+
+// NewSource returns a source.Source watching the secret identified by key with the monitor, and delivering
+// the add, update and delete events of the secret to the event handler of the controller, which maps them to
+// reconcile requests, e.g. handler.EnqueueRequestForObject enqueues a request named after the secret.
+// Reconcilers of routes map such a request to the routes referencing the secret.
+func NewSource(monitor secret.SecretMonitor, key secret.ObjectKey) source.Source {
+	return &secretSource{
+		monitor: monitor,
+		key:     key,
+	}
+}
+
+// Start registers the handler of the source with the monitor, and removes it once ctx is done. Events are
+// delivered to eventHandler only if all the predicates accept them.
+// Only the lifetime of the handler is bound to ctx: the informer of the secret is stopped by the
+// monitor when its last handler is removed. Returns an error if the handler can't be registered.
+func (s *secretSource) Start(ctx context.Context, eventHandler handler.EventHandler, queue workqueue.RateLimitingInterface, predicates ...predicate.Predicate) error {
+	if eventHandler == nil {
+		return fmt.Errorf("nil event handler is provided for item key %v", s.key)
+	}
+	if queue == nil {
+		return fmt.Errorf("nil queue is provided for item key %v", s.key)
+	}
+
+	// The informer must outlive ctx, which would otherwise stop it before the handler is removed.
+	registration, err := s.monitor.AddSecretEventHandler(context.WithoutCancel(ctx), s.key.Namespace, s.key.Name, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			secret, ok := obj.(*corev1.Secret)
+			if !ok {
+				return
+			}
+			evt := event.CreateEvent{Object: secret}
+			for _, p := range predicates {
+				if !p.Create(evt) {
+					return
+				}
+			}
+			eventHandler.Create(ctx, evt, queue)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldSecret, oldOK := oldObj.(*corev1.Secret)
+			newSecret, newOK := newObj.(*corev1.Secret)
+			if !oldOK || !newOK {
+				return
+			}
+			evt := event.UpdateEvent{ObjectOld: oldSecret, ObjectNew: newSecret}
+			for _, p := range predicates {
+				if !p.Update(evt) {
+					return
+				}
+			}
+			eventHandler.Update(ctx, evt, queue)
+		},
+		DeleteFunc: func(obj interface{}) {
+			evt := event.DeleteEvent{}
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				evt.DeleteStateUnknown = true
+				obj = tombstone.Obj
+			}
+			secret, ok := obj.(*corev1.Secret)
+			if !ok {
+				return
+			}
+			evt.Object = secret
+			for _, p := range predicates {
+				if !p.Delete(evt) {
+					return
+				}
+			}
+			eventHandler.Delete(ctx, evt, queue)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := s.monitor.RemoveSecretEventHandler(registration); err != nil {
+			klog.Errorf("failed to remove secret source handler for item key %v: %v", s.key, err)
+		}
+	}()
+	return nil
+}
+
+func (s *secretSource) String() string {
+	return fmt.Sprintf("secret source %s/%s", s.key.Namespace, s.key.Name)
+}
@@ -0,0 +1,126 @@
+package secretsource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestSource(t *testing.T) {
+	key := secret.NewObjectKey("ns", "secret")
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "secret"}}
+	kubeClient := fake.NewSimpleClientset()
+	monitor := secret.NewSecretMonitor(kubeClient)
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := NewSource(monitor, key).Start(ctx, &handler.EnqueueRequestForObject{}, queue); err != nil {
+		t.Fatal(err)
+	}
+
+	secrets := kubeClient.CoreV1().Secrets(key.Namespace)
+	obj := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	if _, err := secrets.Create(context.TODO(), obj, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expectRequest(t, queue, request)
+
+	obj.Labels = map[string]string{"updated": "true"}
+	if _, err := secrets.Update(context.TODO(), obj, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expectRequest(t, queue, request)
+
+	if err := secrets.Delete(context.TODO(), key.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expectRequest(t, queue, request)
+
+	// the handler is removed once the context is done
+	cancel()
+	if err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(_ context.Context) (bool, error) {
+		return len(monitor.ListCachedSecrets()) == 0, nil
+	}); err != nil {
+		t.Error("expected the secret informer to be stopped")
+	}
+}
+
+func TestSourcePredicates(t *testing.T) {
+	key := secret.NewObjectKey("ns", "secret")
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "secret"}}
+	kubeClient := fake.NewSimpleClientset()
+	monitor := secret.NewSecretMonitor(kubeClient)
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// only the updates of the labels are delivered
+	skipCreate := predicate.Funcs{CreateFunc: func(event.CreateEvent) bool { return false }}
+	if err := NewSource(monitor, key).Start(ctx, &handler.EnqueueRequestForObject{}, queue, skipCreate, predicate.LabelChangedPredicate{}); err != nil {
+		t.Fatal(err)
+	}
+
+	secrets := kubeClient.CoreV1().Secrets(key.Namespace)
+	obj := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	if _, err := secrets.Create(context.TODO(), obj, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	obj.Data = map[string][]byte{"key": []byte("value")}
+	if _, err := secrets.Update(context.TODO(), obj, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	obj.Labels = map[string]string{"updated": "true"}
+	if _, err := secrets.Update(context.TODO(), obj, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expectRequest(t, queue, request)
+	if queue.Len() != 0 {
+		t.Errorf("expected the events rejected by the predicate not to be enqueued, got %d requests", queue.Len())
+	}
+}
+
+func TestSourceNilArguments(t *testing.T) {
+	monitor := secret.NewSecretMonitor(fake.NewSimpleClientset())
+	src := NewSource(monitor, secret.NewObjectKey("ns", "secret"))
+	if err := src.Start(context.TODO(), nil, workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())); err == nil {
+		t.Error("expected an error for a nil event handler")
+	}
+	if err := src.Start(context.TODO(), &handler.EnqueueRequestForObject{}, nil); err == nil {
+		t.Error("expected an error for a nil queue")
+	}
+}
+
+// expectRequest waits for the next item of the queue and checks it is the expected reconcile request.
+func expectRequest(t *testing.T, queue workqueue.RateLimitingInterface, request reconcile.Request) {
+	t.Helper()
+
+	items := make(chan interface{})
+	go func() {
+		item, _ := queue.Get()
+		items <- item
+	}()
+	select {
+	case item := <-items:
+		queue.Done(item)
+		if item != request {
+			t.Errorf("expected request %v, got %v", request, item)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a request")
+	}
+}
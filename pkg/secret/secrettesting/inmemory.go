@@ -0,0 +1,324 @@
+package secrettesting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// InMemorySecretMonitor is a secret.SecretMonitor backed by a map instead of informers, for unit tests of
+// controllers which don't need a Kubernetes client. Secrets are driven by Set and Delete, which deliver the
+// events to the registered handlers synchronously.
+type InMemorySecretMonitor struct {
+	lock          sync.Mutex
+	secrets       map[secret.ObjectKey]*corev1.Secret
+	registrations map[*inMemoryRegistration]struct{}
+	counts        map[secret.ObjectKey]*eventCounts
+}
+
+var _ secret.SecretMonitor = &InMemorySecretMonitor{}
+
+// eventCounts counts the events delivered for a secret.
+type eventCounts struct {
+	adds, updates, deletes int64
+}
+
+// NewInMemorySecretMonitor returns an empty InMemorySecretMonitor.
+func NewInMemorySecretMonitor() *InMemorySecretMonitor {
+	return &InMemorySecretMonitor{
+		secrets:       map[secret.ObjectKey]*corev1.Secret{},
+		registrations: map[*inMemoryRegistration]struct{}{},
+		counts:        map[secret.ObjectKey]*eventCounts{},
+	}
+}
+
+// Set stores a copy of the secret under the given namespace and name, and delivers an Add event to the handlers
+// of the secret if it didn't exist, an Update event otherwise. The handlers are invoked before Set returns.
+func (m *InMemorySecretMonitor) Set(namespace, name string, obj *corev1.Secret) {
+	key := secret.NewObjectKey(namespace, name)
+	stored := obj.DeepCopy()
+	stored.Namespace = namespace
+	stored.Name = name
+
+	m.lock.Lock()
+	old, exists := m.secrets[key]
+	m.secrets[key] = stored
+	handlers := m.handlersLocked(key)
+	counts := m.countsLocked(key)
+	if exists {
+		counts.updates += 1
+	} else {
+		counts.adds += 1
+	}
+	m.lock.Unlock()
+
+	for _, handler := range handlers {
+		if exists {
+			handler.OnUpdate(old, stored)
+		} else {
+			handler.OnAdd(stored, false)
+		}
+	}
+}
+
+// Delete removes the secret and delivers a Delete event to its handlers, before returning.
+// Deleting a secret which doesn't exist does nothing.
+func (m *InMemorySecretMonitor) Delete(namespace, name string) {
+	key := secret.NewObjectKey(namespace, name)
+
+	m.lock.Lock()
+	old, exists := m.secrets[key]
+	if !exists {
+		m.lock.Unlock()
+		return
+	}
+	delete(m.secrets, key)
+	handlers := m.handlersLocked(key)
+	m.countsLocked(key).deletes += 1
+	m.lock.Unlock()
+
+	for _, handler := range handlers {
+		handler.OnDelete(old)
+	}
+}
+
+// AddSecretEventHandler registers the handler for the secret. If the secret exists, an Add event
+// is delivered to the handler before returning, as an informer would do for its initial list.
+func (m *InMemorySecretMonitor) AddSecretEventHandler(_ context.Context, namespace, secretName string, handler cache.ResourceEventHandler) (secret.SecretEventHandlerRegistration, error) {
+	key := secret.NewObjectKey(namespace, secretName)
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("nil handler is provided")
+	}
+
+	registration := &inMemoryRegistration{key: key, handler: handler, monitor: m}
+	m.lock.Lock()
+	m.registrations[registration] = struct{}{}
+	obj, exists := m.secrets[key]
+	m.lock.Unlock()
+
+	if exists {
+		handler.OnAdd(obj, true)
+	}
+	return registration, nil
+}
+
+// AddSecretEventHandlerWithInformer ignores the informer and registers the handler like AddSecretEventHandler.
+func (m *InMemorySecretMonitor) AddSecretEventHandlerWithInformer(ctx context.Context, key secret.ObjectKey, _ cache.SharedInformer, handler cache.ResourceEventHandler) (secret.SecretEventHandlerRegistration, error) {
+	return m.AddSecretEventHandler(ctx, key.Namespace, key.Name, handler)
+}
+
+// RemoveSecretEventHandler unregisters the handler. Returns an error if it is not registered.
+func (m *InMemorySecretMonitor) RemoveSecretEventHandler(handlerRegistration secret.SecretEventHandlerRegistration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	registration, ok := handlerRegistration.(*inMemoryRegistration)
+	if !ok {
+		return fmt.Errorf("unexpected registration type %T", handlerRegistration)
+	}
+	if _, exists := m.registrations[registration]; !exists {
+		return fmt.Errorf("secret handler not registered for item key %v", registration.key)
+	}
+	delete(m.registrations, registration)
+	return nil
+}
+
+// GetSecret returns the secret of the registration, or an error wrapping secret.ErrSecretNotFound if it doesn't exist.
+func (m *InMemorySecretMonitor) GetSecret(_ context.Context, handlerRegistration secret.SecretEventHandlerRegistration) (*corev1.Secret, error) {
+	if handlerRegistration == nil {
+		return nil, fmt.Errorf("nil secret handler registration is provided")
+	}
+	return m.get(handlerRegistration.GetKey())
+}
+
+// GetSecretByKey returns the secret, or an error if no handler of the secret is registered.
+func (m *InMemorySecretMonitor) GetSecretByKey(key secret.ObjectKey) (*corev1.Secret, error) {
+	if len(m.ListHandlers(key)) == 0 {
+		return nil, fmt.Errorf("secret monitor doesn't exist for key %v", key)
+	}
+	return m.get(key)
+}
+
+// ListMonitoredKeys returns the keys of the secrets with a registered handler, sorted by namespace and name.
+func (m *InMemorySecretMonitor) ListMonitoredKeys() []secret.ObjectKey {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	monitored := map[secret.ObjectKey]struct{}{}
+	keys := []secret.ObjectKey{}
+	for registration := range m.registrations {
+		if _, exists := monitored[registration.key]; exists {
+			continue
+		}
+		monitored[registration.key] = struct{}{}
+		keys = append(keys, registration.key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Namespace != keys[j].Namespace {
+			return keys[i].Namespace < keys[j].Namespace
+		}
+		return keys[i].Name < keys[j].Name
+	})
+	return keys
+}
+
+// ShutdownNamespace unregisters every handler of the secrets of the namespace. The secrets are kept.
+func (m *InMemorySecretMonitor) ShutdownNamespace(namespace string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for registration := range m.registrations {
+		if registration.key.Namespace == namespace {
+			delete(m.registrations, registration)
+		}
+	}
+	return nil
+}
+
+// ListCachedSecrets returns a summary of every monitored secret, which is every secret with a registered handler,
+// sorted by namespace and name.
+func (m *InMemorySecretMonitor) ListCachedSecrets() []secret.SecretSummary {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	monitored := map[secret.ObjectKey]struct{}{}
+	summaries := []secret.SecretSummary{}
+	for registration := range m.registrations {
+		if _, exists := monitored[registration.key]; exists {
+			continue
+		}
+		monitored[registration.key] = struct{}{}
+		summary := secret.SecretSummary{Namespace: registration.key.Namespace, Name: registration.key.Name, Synced: true}
+		if obj, exists := m.secrets[registration.key]; exists {
+			summary.Type = string(obj.Type)
+			summary.ResourceVersion = obj.ResourceVersion
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Namespace != summaries[j].Namespace {
+			return summaries[i].Namespace < summaries[j].Namespace
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
+
+// ReregisterAll does nothing, there is no informer to recreate.
+func (m *InMemorySecretMonitor) ReregisterAll() error {
+	return nil
+}
+
+// Reregister does nothing, there is no informer to recreate. Returns an error if no handler of the secret is registered.
+func (m *InMemorySecretMonitor) Reregister(key secret.ObjectKey) error {
+	if len(m.ListHandlers(key)) == 0 {
+		return fmt.Errorf("secret monitor doesn't exist for key %v", key)
+	}
+	return nil
+}
+
+// SetKubeClient does nothing, there is no client.
+func (m *InMemorySecretMonitor) SetKubeClient(_ kubernetes.Interface) {
+}
+
+// ByIndex is not supported, the secrets are not indexed.
+func (m *InMemorySecretMonitor) ByIndex(indexName, _ string) ([]*corev1.Secret, error) {
+	return nil, fmt.Errorf("index with name %s does not exist", indexName)
+}
+
+// ListHandlers returns the registrations of the handlers of the secret, in no particular order.
+func (m *InMemorySecretMonitor) ListHandlers(key secret.ObjectKey) []secret.SecretEventHandlerRegistration {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var registrations []secret.SecretEventHandlerRegistration
+	for registration := range m.registrations {
+		if registration.key == key {
+			registrations = append(registrations, registration)
+		}
+	}
+	return registrations
+}
+
+// get returns the secret with the given key.
+func (m *InMemorySecretMonitor) get(key secret.ObjectKey) (*corev1.Secret, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	obj, exists := m.secrets[key]
+	if !exists {
+		return nil, fmt.Errorf("%w: %w", secret.ErrSecretNotFound, apierrors.NewNotFound(corev1.Resource("secrets"), key.Name))
+	}
+	return obj, nil
+}
+
+// handlersLocked returns the handlers of the secret. The caller must hold the lock.
+func (m *InMemorySecretMonitor) handlersLocked(key secret.ObjectKey) []cache.ResourceEventHandler {
+	var handlers []cache.ResourceEventHandler
+	for registration := range m.registrations {
+		if registration.key == key {
+			handlers = append(handlers, registration.handler)
+		}
+	}
+	return handlers
+}
+
+// countsLocked returns the event counts of the secret. The caller must hold the lock.
+func (m *InMemorySecretMonitor) countsLocked(key secret.ObjectKey) *eventCounts {
+	counts, exists := m.counts[key]
+	if !exists {
+		counts = &eventCounts{}
+		m.counts[key] = counts
+	}
+	return counts
+}
+
+// inMemoryRegistration is the secret.SecretEventHandlerRegistration of the InMemorySecretMonitor.
+type inMemoryRegistration struct {
+	key     secret.ObjectKey
+	handler cache.ResourceEventHandler
+	monitor *InMemorySecretMonitor
+}
+
+func (r *inMemoryRegistration) HasSynced() bool {
+	return true
+}
+
+func (r *inMemoryRegistration) GetKey() secret.ObjectKey {
+	return r.key
+}
+
+func (r *inMemoryRegistration) GetHandler() cache.ResourceEventHandlerRegistration {
+	return r
+}
+
+func (r *inMemoryRegistration) GetSecret() (*corev1.Secret, error) {
+	return r.monitor.get(r.key)
+}
+
+func (r *inMemoryRegistration) EventCounts() (adds, updates, deletes int64) {
+	r.monitor.lock.Lock()
+	defer r.monitor.lock.Unlock()
+
+	counts := r.monitor.countsLocked(r.key)
+	return counts.adds, counts.updates, counts.deletes
+}
+
+// LastSyncResourceVersion returns the resource version of the stored secret, empty if it doesn't exist.
+func (r *inMemoryRegistration) LastSyncResourceVersion() string {
+	obj, err := r.monitor.get(r.key)
+	if err != nil {
+		return ""
+	}
+	return obj.ResourceVersion
+}
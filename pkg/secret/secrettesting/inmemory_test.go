@@ -0,0 +1,77 @@
+package secrettesting
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestInMemorySecretMonitor(t *testing.T) {
+	namespace, name := "ns", "secret"
+	var events []string
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			events = append(events, "add "+obj.(*corev1.Secret).ResourceVersion)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			events = append(events, "update "+oldObj.(*corev1.Secret).ResourceVersion+" to "+newObj.(*corev1.Secret).ResourceVersion)
+		},
+		DeleteFunc: func(obj interface{}) {
+			events = append(events, "delete "+obj.(*corev1.Secret).ResourceVersion)
+		},
+	}
+	version := func(resourceVersion string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{ResourceVersion: resourceVersion}}
+	}
+
+	sm := NewInMemorySecretMonitor()
+	sm.Set(namespace, name, version("1"))
+	registration, err := sm.AddSecretEventHandler(context.TODO(), namespace, name, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// events of other secrets are not delivered
+	sm.Set(namespace, "other", version("1"))
+
+	sm.Set(namespace, name, version("2"))
+	gotSecret, err := sm.GetSecret(context.TODO(), registration)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotSecret.Namespace != namespace || gotSecret.Name != name || gotSecret.ResourceVersion != "2" {
+		t.Errorf("expected secret %s/%s at version 2, got %s/%s at version %s", namespace, name, gotSecret.Namespace, gotSecret.Name, gotSecret.ResourceVersion)
+	}
+
+	sm.Delete(namespace, name)
+	if _, err := registration.GetSecret(); !errors.Is(err, secret.ErrSecretNotFound) {
+		t.Errorf("expected %v, got %v", secret.ErrSecretNotFound, err)
+	}
+	// deleting a missing secret does nothing
+	sm.Delete(namespace, name)
+
+	expected := []string{"add 1", "update 1 to 2", "delete 2"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %v, got %v", expected, events)
+	}
+	if adds, updates, deletes := registration.EventCounts(); adds != 1 || updates != 1 || deletes != 1 {
+		t.Errorf("expected 1 add, update and delete, got %d, %d, %d", adds, updates, deletes)
+	}
+
+	// no event is delivered once the handler is removed
+	if err := sm.RemoveSecretEventHandler(registration); err != nil {
+		t.Fatal(err)
+	}
+	sm.Set(namespace, name, version("3"))
+	if len(events) != len(expected) {
+		t.Errorf("expected no event after removing the handler, got %v", events[len(expected):])
+	}
+	if err := sm.RemoveSecretEventHandler(registration); err == nil {
+		t.Error("expected an error removing the handler twice")
+	}
+}
@@ -0,0 +1,62 @@
+package secrettesting
+
+import (
+	"context"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// pollInterval is the interval at which the helpers check for their condition.
+const pollInterval = 10 * time.Millisecond
+
+// TestingT is the subset of testing.TB used by the helpers.
+type TestingT interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+	Cleanup(func())
+}
+
+// SyncMonitor waits until the informer of the handler registration returned by the AddSecretEventHandler
+// method of the monitor has synced, failing the test if it doesn't sync within the timeout. The handler is
+// removed from the monitor when the test finishes, which stops the informer unless other handlers remain.
+func SyncMonitor(t TestingT, monitor secret.SecretMonitor, registration secret.SecretEventHandlerRegistration, timeout time.Duration) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		if err := monitor.RemoveSecretEventHandler(registration); err != nil {
+			klog.Errorf("failed to remove handler of secret %v: %v", registration.GetKey(), err)
+		}
+	})
+
+	err := wait.PollUntilContextTimeout(context.Background(), pollInterval, timeout, true, func(_ context.Context) (bool, error) {
+		return registration.HasSynced(), nil
+	})
+	if err != nil {
+		t.Fatalf("informer of secret %v not synced within %v: %v", registration.GetKey(), timeout, err)
+	}
+}
+
+// AwaitSecret polls get until it returns a secret, failing the test if no secret
+// is returned within the timeout. Errors returned by get are retried.
+// For example, the GetSecret method of a SecretEventHandlerRegistration can be used as get.
+func AwaitSecret(t TestingT, get func() (*corev1.Secret, error), timeout time.Duration) *corev1.Secret {
+	t.Helper()
+
+	var (
+		obj     *corev1.Secret
+		lastErr error
+	)
+	err := wait.PollUntilContextTimeout(context.Background(), pollInterval, timeout, true, func(_ context.Context) (bool, error) {
+		obj, lastErr = get()
+		return lastErr == nil && obj != nil, nil
+	})
+	if err != nil {
+		t.Fatalf("secret not cached within %v: %v (last error: %v)", timeout, err, lastErr)
+		return nil
+	}
+	return obj
+}
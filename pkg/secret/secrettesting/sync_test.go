@@ -0,0 +1,157 @@
+package secrettesting
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/secret"
+	"github.com/openshift/library-go/pkg/secret/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+// recordingT records fatal failures instead of failing the test.
+type recordingT struct {
+	*testing.T
+	failed bool
+}
+
+func (r *recordingT) Fatalf(format string, args ...interface{}) {
+	r.failed = true
+	r.Logf(format, args...)
+}
+
+// syncingRegistration syncs syncAfter polls after being added.
+type syncingRegistration struct {
+	fake.SecretEventHandlerRegistration
+	polls     atomic.Int32
+	syncAfter int32
+}
+
+func (r *syncingRegistration) HasSynced() bool {
+	return r.polls.Add(1) > r.syncAfter
+}
+
+// removingMonitor records the removed handlers.
+type removingMonitor struct {
+	fake.SecretMonitor
+	removed []secret.SecretEventHandlerRegistration
+}
+
+func (m *removingMonitor) RemoveSecretEventHandler(registration secret.SecretEventHandlerRegistration) error {
+	m.removed = append(m.removed, registration)
+	return nil
+}
+
+func TestSyncMonitor(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		syncAfter    int32
+		expectFailed bool
+	}{
+		{
+			name:      "monitor synced immediately",
+			syncAfter: 0,
+		},
+		{
+			name:      "monitor synced after a few polls",
+			syncAfter: 3,
+		},
+		{
+			name:         "monitor never synced",
+			syncAfter:    1 << 30,
+			expectFailed: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			monitor := &removingMonitor{}
+			registration := &syncingRegistration{syncAfter: s.syncAfter}
+			rt := &recordingT{T: t}
+
+			t.Run("sync", func(t *testing.T) {
+				rt.T = t
+				SyncMonitor(rt, monitor, registration, 200*time.Millisecond)
+			})
+
+			if rt.failed != s.expectFailed {
+				t.Errorf("expected failed to be %t, got %t", s.expectFailed, rt.failed)
+			}
+			if len(monitor.removed) != 1 || monitor.removed[0] != registration {
+				t.Errorf("expected the handler to be removed on cleanup, got %v", monitor.removed)
+			}
+		})
+	}
+}
+
+func TestSyncMonitorInformer(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret"}})
+	monitor := secret.NewSecretMonitor(kubeClient)
+
+	t.Run("sync", func(t *testing.T) {
+		registration, err := monitor.AddSecretEventHandler(context.Background(), "ns", "secret", cache.ResourceEventHandlerFuncs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		SyncMonitor(t, monitor, registration, 5*time.Second)
+		if _, err := registration.GetSecret(); err != nil {
+			t.Errorf("expected the secret to be cached once synced, got %v", err)
+		}
+	})
+
+	if keys := monitor.ListMonitoredKeys(); len(keys) != 0 {
+		t.Errorf("expected the informer to be stopped on cleanup, got %v", keys)
+	}
+}
+
+func TestAwaitSecret(t *testing.T) {
+	obj := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret"}}
+
+	scenarios := []struct {
+		name         string
+		availableAt  int32
+		expectFailed bool
+	}{
+		{
+			name:        "secret cached immediately",
+			availableAt: 1,
+		},
+		{
+			name:        "secret cached after a few polls",
+			availableAt: 4,
+		},
+		{
+			name:         "secret never cached",
+			availableAt:  1 << 30,
+			expectFailed: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var calls atomic.Int32
+			get := func() (*corev1.Secret, error) {
+				if calls.Add(1) < s.availableAt {
+					return nil, fmt.Errorf("not cached yet")
+				}
+				return obj, nil
+			}
+			rt := &recordingT{T: t}
+
+			got := AwaitSecret(rt, get, 200*time.Millisecond)
+
+			if rt.failed != s.expectFailed {
+				t.Fatalf("expected failed to be %t, got %t", s.expectFailed, rt.failed)
+			}
+			if !s.expectFailed && got != obj {
+				t.Errorf("expected %v, got %v", obj, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package secrettesting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultTLSSecretLifetime is the lifetime of the certificates generated by NewTLSSecret.
+const defaultTLSSecretLifetime = 24 * time.Hour
+
+// expiredTLSSecretLifetime is the lifetime of the certificates generated by NewTLSSecret which are already expired.
+const expiredTLSSecretLifetime = time.Hour
+
+// TLSSecretOption configures the secret generated by NewTLSSecret.
+type TLSSecretOption func(*tlsSecretConfig)
+
+type tlsSecretConfig struct {
+	// expiresIn is the offset of the expiry of the certificate from now.
+	expiresIn time.Duration
+	// mismatchedKey stores the private key of another certificate.
+	mismatchedKey bool
+}
+
+// WithExpiresIn makes the certificate expire at the given offset from now, which defaults to 24 hours.
+// A negative offset generates a certificate which is already expired.
+func WithExpiresIn(offset time.Duration) TLSSecretOption {
+	return func(c *tlsSecretConfig) {
+		c.expiresIn = offset
+	}
+}
+
+// WithMismatchedKey stores a private key which doesn't match the certificate, so that the key pair is invalid.
+func WithMismatchedKey() TLSSecretOption {
+	return func(c *tlsSecretConfig) {
+		c.mismatchedKey = true
+	}
+}
+
+// NewTLSSecret returns a kubernetes.io/tls secret holding a self-signed certificate and its private key,
+// PEM encoded in the tls.crt and tls.key data keys. By default the key pair is valid and the certificate
+// expires in 24 hours, see the options to generate invalid secrets.
+func NewTLSSecret(namespace, name string, opts ...TLSSecretOption) (*corev1.Secret, error) {
+	config := tlsSecretConfig{expiresIn: defaultTLSSecretLifetime}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	// the certificate is valid from now, or for an hour before its expiry if it is already expired
+	now := time.Now()
+	notBefore, lifetime := now, config.expiresIn
+	if config.expiresIn <= 0 {
+		notBefore, lifetime = now.Add(config.expiresIn-expiredTLSSecretLifetime), expiredTLSSecretLifetime
+	}
+	commonName := fmt.Sprintf("%s.%s", name, namespace)
+	certConfig, err := crypto.UnsafeMakeSelfSignedCAConfigForDurationAtTime(commonName, func() time.Time { return notBefore }, lifetime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate certificate: %w", err)
+	}
+	certPEM, keyPEM, err := certConfig.GetPEMBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode certificate: %w", err)
+	}
+
+	if config.mismatchedKey {
+		otherConfig, err := crypto.MakeSelfSignedCAConfigForDuration(commonName, lifetime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate mismatched key: %w", err)
+		}
+		if _, keyPEM, err = otherConfig.GetPEMBytes(); err != nil {
+			return nil, fmt.Errorf("failed to encode mismatched key: %w", err)
+		}
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			secret.TLSCertKey:       certPEM,
+			secret.TLSPrivateKeyKey: keyPEM,
+		},
+	}, nil
+}
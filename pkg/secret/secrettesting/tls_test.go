@@ -0,0 +1,79 @@
+package secrettesting
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewTLSSecret(t *testing.T) {
+	scenarios := []struct {
+		name              string
+		opts              []TLSSecretOption
+		expectValidPair   bool
+		expectExpired     bool
+		expectedExpiresIn time.Duration
+	}{
+		{
+			name:              "valid",
+			expectValidPair:   true,
+			expectedExpiresIn: 24 * time.Hour,
+		},
+		{
+			name:              "expires soon",
+			opts:              []TLSSecretOption{WithExpiresIn(time.Minute)},
+			expectValidPair:   true,
+			expectedExpiresIn: time.Minute,
+		},
+		{
+			name:              "expired",
+			opts:              []TLSSecretOption{WithExpiresIn(-time.Hour)},
+			expectValidPair:   true,
+			expectExpired:     true,
+			expectedExpiresIn: -time.Hour,
+		},
+		{
+			name:              "mismatched key",
+			opts:              []TLSSecretOption{WithMismatchedKey()},
+			expectedExpiresIn: 24 * time.Hour,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			obj, err := NewTLSSecret("ns", "secret", s.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if obj.Namespace != "ns" || obj.Name != "secret" || obj.Type != corev1.SecretTypeTLS {
+				t.Errorf("expected kubernetes.io/tls secret ns/secret, got %s/%s of type %s", obj.Namespace, obj.Name, obj.Type)
+			}
+
+			keyPair, err := tls.X509KeyPair(obj.Data[corev1.TLSCertKey], obj.Data[corev1.TLSPrivateKeyKey])
+			if s.expectValidPair != (err == nil) {
+				t.Fatalf("expected valid key pair %v, got error %v", s.expectValidPair, err)
+			}
+			if err != nil {
+				return
+			}
+
+			leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+			if err != nil {
+				t.Fatalf("failed to parse certificate: %v", err)
+			}
+			now := time.Now()
+			if expired := now.After(leaf.NotAfter); expired != s.expectExpired {
+				t.Errorf("expected expired %v, got certificate expiring at %v", s.expectExpired, leaf.NotAfter)
+			}
+			if expiresIn := leaf.NotAfter.Sub(now); expiresIn > s.expectedExpiresIn || expiresIn < s.expectedExpiresIn-time.Minute {
+				t.Errorf("expected certificate expiring in %v, got %v", s.expectedExpiresIn, expiresIn)
+			}
+			if leaf.NotBefore.After(now) {
+				t.Errorf("expected certificate valid from before now, got %v", leaf.NotBefore)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package secret
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// WithMaxSecretBytes drops the data of the secrets whose data exceeds maxBytes, counting the keys and the
+// values of data and stringData, before they enter the informer caches. The metadata of such secrets is
+// still cached, so GetSecret returns them without data. Oversized secrets are reported to the
+// WithOnSecretTooLarge callback. The limit applies after WithMinimalSecretCache, if set.
+// By default the size of the secrets is not limited.
+func WithMaxSecretBytes(maxBytes int) Option {
+	return func(s *secretMonitor) {
+		s.maxSecretBytes = maxBytes
+	}
+}
+
+// WithOnSecretTooLarge registers a callback invoked with the key and the data size of a secret whose data
+// is dropped because it exceeds WithMaxSecretBytes. It is invoked by the informer whenever an oversized
+// version of the secret is cached, and must not block.
+func WithOnSecretTooLarge(onSecretTooLarge func(key ObjectKey, size int)) Option {
+	return func(s *secretMonitor) {
+		s.onSecretTooLarge = onSecretTooLarge
+	}
+}
+
+// secretDataSize returns the size of the keys and the values of the data and stringData of the secret.
+func secretDataSize(secret *corev1.Secret) int {
+	size := 0
+	for key, value := range secret.Data {
+		size += len(key) + len(value)
+	}
+	for key, value := range secret.StringData {
+		size += len(key) + len(value)
+	}
+	return size
+}
+
+// secretTransform returns the transform of the secret informers, nil if the secrets are cached as is.
+func (s *secretMonitor) secretTransform() cache.TransformFunc {
+	if s.maxSecretBytes <= 0 {
+		return s.transform
+	}
+	return func(obj interface{}) (interface{}, error) {
+		if s.transform != nil {
+			var err error
+			if obj, err = s.transform(obj); err != nil {
+				return nil, err
+			}
+		}
+		return s.limitSecretSize(obj), nil
+	}
+}
+
+// limitSecretSize drops the data of the secret if it exceeds maxSecretBytes.
+func (s *secretMonitor) limitSecretSize(obj interface{}) interface{} {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		// e.g. tombstones, which hold an already transformed secret
+		return obj
+	}
+	size := secretDataSize(secret)
+	if size <= s.maxSecretBytes {
+		return obj
+	}
+
+	key := NewObjectKey(secret.Namespace, secret.Name)
+	klog.Warning("dropping data of oversized secret", " item key ", key, " size ", size, " max ", s.maxSecretBytes)
+	if s.onSecretTooLarge != nil {
+		s.onSecretTooLarge(key, size)
+	}
+	trimmed := *secret
+	trimmed.Data = nil
+	trimmed.StringData = nil
+	return &trimmed
+}
@@ -0,0 +1,69 @@
+package secret
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestMaxSecretBytes(t *testing.T) {
+	small := fakeSecret("ns", "small")
+	small.Data = map[string][]byte{corev1.TLSCertKey: []byte("cert")}
+	large := fakeSecret("ns", "large")
+	large.Labels = map[string]string{"app": "router"}
+	large.Data = map[string][]byte{
+		corev1.TLSCertKey: make([]byte, 64),
+		"extra":           make([]byte, 1024),
+	}
+
+	var (
+		lock       sync.Mutex
+		tooLarge   = map[ObjectKey]int{}
+		kubeClient = fake.NewSimpleClientset(small, large)
+	)
+	sm := newSecretMonitor(kubeClient, WithMaxSecretBytes(512), WithOnSecretTooLarge(func(key ObjectKey, size int) {
+		lock.Lock()
+		defer lock.Unlock()
+		tooLarge[key] = size
+	}))
+
+	smallRegistration, err := sm.AddSecretEventHandler(context.TODO(), small.Namespace, small.Name, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	largeRegistration, err := sm.AddSecretEventHandler(context.TODO(), large.Namespace, large.Name, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotSecret, err := sm.GetSecret(context.TODO(), smallRegistration)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(gotSecret.Data, small.Data) {
+		t.Errorf("expected the data of the small secret to be cached, got %v", gotSecret.Data)
+	}
+
+	gotSecret, err = sm.GetSecret(context.TODO(), largeRegistration)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotSecret.Data != nil {
+		t.Errorf("expected the data of the large secret to be dropped, got %d keys", len(gotSecret.Data))
+	}
+	if !reflect.DeepEqual(gotSecret.Labels, large.Labels) {
+		t.Errorf("expected the metadata of the large secret to be cached, got labels %v", gotSecret.Labels)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	expected := map[ObjectKey]int{NewObjectKey(large.Namespace, large.Name): len(corev1.TLSCertKey) + 64 + len("extra") + 1024}
+	if !reflect.DeepEqual(tooLarge, expected) {
+		t.Errorf("expected oversized secrets %v, got %v", expected, tooLarge)
+	}
+}
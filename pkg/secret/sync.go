@@ -0,0 +1,53 @@
+package secret
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+)
+
+// defaultSyncPollInterval is how often HasSynced is polled while waiting for an informer to sync.
+const defaultSyncPollInterval = 50 * time.Millisecond
+
+// syncWaiter waits until hasSynced returns true, polling it every interval, or until ctx is done, in which
+// case it returns false. It is implemented by waitForSync, and lets tests cover the paths of informers which
+// never sync without waiting for a timeout. The monitors pass their syncWaiter to the item monitors and the
+// namespace informers they create, so that every wait of a monitor goes through it.
+type syncWaiter func(ctx context.Context, interval time.Duration, hasSynced cache.InformerSynced) bool
+
+// wait waits for hasSynced with the syncWaiter, or with waitForSync if the syncWaiter is nil.
+func (w syncWaiter) wait(ctx context.Context, interval time.Duration, hasSynced cache.InformerSynced) bool {
+	if w != nil {
+		return w(ctx, interval, hasSynced)
+	}
+	return waitForSync(ctx, interval, hasSynced)
+}
+
+// syncingMonitor is implemented by the monitors of the package, so that the helpers taking a SecretMonitor,
+// like AddSecretEventHandlerWithReplay, wait for the informers with the syncWaiter of the monitor.
+type syncingMonitor interface {
+	waitForSync(ctx context.Context, hasSynced cache.InformerSynced) bool
+}
+
+// waitForMonitorSync waits for hasSynced with the syncWaiter of the monitor if it is a syncingMonitor,
+// and with waitForSync otherwise.
+func waitForMonitorSync(ctx context.Context, monitor SecretMonitor, hasSynced cache.InformerSynced) bool {
+	if m, ok := monitor.(syncingMonitor); ok {
+		return m.waitForSync(ctx, hasSynced)
+	}
+	return waitForSync(ctx, defaultSyncPollInterval, hasSynced)
+}
+
+// waitForSync polls hasSynced every interval until it returns true, or ctx is done.
+// A non-positive interval means defaultSyncPollInterval. Returns false if ctx is done before hasSynced returns true.
+func waitForSync(ctx context.Context, interval time.Duration, hasSynced cache.InformerSynced) bool {
+	if interval <= 0 {
+		interval = defaultSyncPollInterval
+	}
+	err := wait.PollUntilContextCancel(ctx, interval, true, func(_ context.Context) (bool, error) {
+		return hasSynced(), nil
+	})
+	return err == nil
+}
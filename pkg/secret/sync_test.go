@@ -0,0 +1,129 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestWaitForSyncCadence(t *testing.T) {
+	interval := 50 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 10*interval)
+	defer cancel()
+
+	var polls atomic.Int64
+	if waitForSync(ctx, interval, func() bool {
+		polls.Add(1)
+		return false
+	}) {
+		t.Fatal("expected the wait to time out")
+	}
+
+	// ~11 polls are expected over 10 intervals, a busy loop would poll orders of magnitude more
+	if got := polls.Load(); got < 5 || got > 15 {
+		t.Errorf("expected the condition to be polled about every %v, got %d polls in %v", interval, got, 10*interval)
+	}
+}
+
+func TestWaitForSyncImmediate(t *testing.T) {
+	// an already synced informer doesn't wait for the interval
+	start := time.Now()
+	if !waitForSync(context.TODO(), time.Hour, func() bool { return true }) {
+		t.Fatal("expected the wait to succeed")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected an immediate return, took %v", elapsed)
+	}
+}
+
+func TestWithSyncPollInterval(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	sm := newSecretMonitor(nil, WithSyncPollInterval(10*time.Millisecond))
+	if sm.syncPollInterval != 10*time.Millisecond {
+		t.Fatalf("expected poll interval to be set, got %v", sm.syncPollInterval)
+	}
+	// the single item monitors get the interval as well
+	informer := fakeSecretInformer(context.TODO(), fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name)), key.Namespace, key.Name)
+	if _, err := sm.addSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}, informer); err != nil {
+		t.Fatal(err)
+	}
+	if got := sm.monitors[key].itemMonitor.syncPollInterval; got != 10*time.Millisecond {
+		t.Errorf("expected item monitor poll interval to be set, got %v", got)
+	}
+}
+
+// neverSynced is a syncWaiter of informers which never sync, returning as if ctx was done.
+func neverSynced(_ context.Context, _ time.Duration, _ cache.InformerSynced) bool {
+	return false
+}
+
+func TestGetSecretNeverSynced(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	sm := newSecretMonitor(fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name)))
+	registration, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no deadline is needed, the stub returns immediately
+	sm.syncWaiter = neverSynced
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	start := time.Now()
+	if _, err := sm.GetSecret(ctx, registration); !errors.Is(err, ErrCacheNotSynced) {
+		t.Errorf("expected %v, got %v", ErrCacheNotSynced, err)
+	}
+	// the informer of the new secret is stopped with ctx
+	if _, err := sm.AddSecretEventHandler(ctx, key.Namespace, "other", cache.ResourceEventHandlerFuncs{}); !errors.Is(err, ErrCacheNotSynced) {
+		t.Errorf("expected %v adding a handler of a new secret, got %v", ErrCacheNotSynced, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the timeout paths not to wait, took %v", elapsed)
+	}
+	if _, exists := sm.monitors[NewObjectKey(key.Namespace, "other")]; exists {
+		t.Error("expected the secret which never synced not to be monitored")
+	}
+}
+
+func TestSyncWaiterCoversMonitors(t *testing.T) {
+	key := NewObjectKey("ns", "secret")
+	kubeClient := fake.NewSimpleClientset(fakeSecret(key.Namespace, key.Name))
+
+	// informers sync until the waiter is switched to never syncing
+	var neverSync atomic.Bool
+	waiter := func(ctx context.Context, interval time.Duration, hasSynced cache.InformerSynced) bool {
+		if neverSync.Load() {
+			return false
+		}
+		return waitForSync(ctx, interval, hasSynced)
+	}
+
+	sm := newSecretMonitor(kubeClient)
+	sm.syncWaiter = waiter
+	if _, err := sm.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}); err != nil {
+		t.Fatal(err)
+	}
+	neverSync.Store(true)
+
+	// no deadline is needed, the waiter returns immediately
+	if _, err := AddSecretEventHandlerWithReplay(context.TODO(), sm, key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}); !errors.Is(err, ErrCacheNotSynced) {
+		t.Errorf("expected %v replaying the secret, got %v", ErrCacheNotSynced, err)
+	}
+	if err := sm.Reregister(key); !errors.Is(err, ErrCacheNotSynced) {
+		t.Errorf("expected %v replacing the informer of the item monitor, got %v", ErrCacheNotSynced, err)
+	}
+
+	namespaced := NewNamespacedSecretMonitor(func(namespace string) informers.SharedInformerFactory {
+		return informers.NewSharedInformerFactoryWithOptions(kubeClient, 0, informers.WithNamespace(namespace))
+	}).(*namespacedSecretMonitor)
+	namespaced.syncWaiter = waiter
+	if _, err := namespaced.AddSecretEventHandler(context.TODO(), key.Namespace, key.Name, cache.ResourceEventHandlerFuncs{}); !errors.Is(err, ErrCacheNotSynced) {
+		t.Errorf("expected %v starting the namespace informer, got %v", ErrCacheNotSynced, err)
+	}
+}